@@ -0,0 +1,212 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Authorization configures the Authorization Services of a client: its resource server settings,
+// resources, scopes, policies and permissions. Everything is referenced by name.
+type Authorization struct {
+	// PolicyEnforcementMode of the resource server
+	// +kubebuilder:validation:Enum=ENFORCING;PERMISSIVE;DISABLED
+	// +optional
+	PolicyEnforcementMode string `json:"policyEnforcementMode,omitempty"`
+	// DecisionStrategy combining the permissions evaluated for a resource
+	// +kubebuilder:validation:Enum=UNANIMOUS;AFFIRMATIVE;CONSENSUS
+	// +optional
+	DecisionStrategy string `json:"decisionStrategy,omitempty"`
+	// AllowRemoteResourceManagement lets the resource server manage its resources through the protection API
+	// +optional
+	AllowRemoteResourceManagement *bool `json:"allowRemoteResourceManagement,omitempty"`
+	// Scopes of the resource server
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Scopes []AuthorizationScope `json:"scopes,omitempty"`
+	// Resources of the resource server
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Resources []AuthorizationResource `json:"resources,omitempty"`
+	// Policies of the resource server. Aggregate policies may reference policies declared before them.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Policies []AuthorizationPolicy `json:"policies,omitempty"`
+	// Permissions of the resource server
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Permissions []AuthorizationPermission `json:"permissions,omitempty"`
+	// PrunePolicy selects whether scopes, resources, policies and permissions not declared are left alone
+	// ("Retain", default) or deleted ("Prune"), including those Keycloak creates with the resource server.
+	// Only the kinds of objects declaring at least one entry are pruned.
+	// +kubebuilder:validation:Enum=Retain;Prune
+	// +optional
+	PrunePolicy PrunePolicy `json:"prunePolicy,omitempty"`
+}
+
+// AuthorizationScope declares a scope of a resource server.
+type AuthorizationScope struct {
+	// Name of the scope
+	Name string `json:"name"`
+	// DisplayName of the scope
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// IconURI of the scope
+	// +optional
+	IconURI string `json:"iconUri,omitempty"`
+}
+
+// AuthorizationResource declares a resource of a resource server.
+type AuthorizationResource struct {
+	// Name of the resource
+	Name string `json:"name"`
+	// DisplayName of the resource
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// Type of the resource, e.g. "urn:my-app:resources:document"
+	// +optional
+	Type string `json:"type,omitempty"`
+	// URIs of the resource
+	// +optional
+	URIs []string `json:"uris,omitempty"`
+	// Scopes of the resource, by name
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+	// OwnerManagedAccess lets the owner of the resource manage access to it
+	// +optional
+	OwnerManagedAccess *bool `json:"ownerManagedAccess,omitempty"`
+	// Attributes of the resource
+	// +optional
+	Attributes map[string][]string `json:"attributes,omitempty"`
+}
+
+// AuthorizationPolicy declares a policy of a resource server. The fields used depend on its type.
+type AuthorizationPolicy struct {
+	// Name of the policy
+	Name string `json:"name"`
+	// Description of the policy
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Type of the policy
+	// +kubebuilder:validation:Enum=role;group;client;user;time;aggregate
+	Type string `json:"type"`
+	// Logic of the policy, "NEGATIVE" denies access when the conditions are met
+	// +kubebuilder:validation:Enum=POSITIVE;NEGATIVE
+	// +optional
+	Logic string `json:"logic,omitempty"`
+	// DecisionStrategy combining the policies of an aggregate policy
+	// +kubebuilder:validation:Enum=UNANIMOUS;AFFIRMATIVE;CONSENSUS
+	// +optional
+	DecisionStrategy string `json:"decisionStrategy,omitempty"`
+	// Roles of a role policy
+	// +optional
+	Roles []PolicyRole `json:"roles,omitempty"`
+	// Groups of a group policy
+	// +optional
+	Groups []PolicyGroup `json:"groups,omitempty"`
+	// GroupsClaim of a group policy, the token claim holding the groups of the user
+	// +optional
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+	// Clients of a client policy, by clientId
+	// +optional
+	Clients []string `json:"clients,omitempty"`
+	// Users of a user policy, by username
+	// +optional
+	Users []string `json:"users,omitempty"`
+	// Time conditions of a time policy
+	// +optional
+	Time *TimeCondition `json:"time,omitempty"`
+	// Policies of an aggregate policy, by name
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+}
+
+// PolicyRole references a role of a role policy.
+type PolicyRole struct {
+	// Role name, a realm role or "<clientId>/<role>" for a client role
+	Role string `json:"role"`
+	// Required makes the role mandatory for the policy to grant access
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// PolicyGroup references a group of a group policy.
+type PolicyGroup struct {
+	// Path of the group, e.g. "/engineering/backend"
+	Path string `json:"path"`
+	// ExtendChildren also grants access to the members of the subgroups
+	// +optional
+	ExtendChildren bool `json:"extendChildren,omitempty"`
+}
+
+// TimeCondition restricts a time policy to a period. Dates use the "yyyy-MM-dd HH:mm:ss" format,
+// and the other fields are numbers, each *End field closing the range opened by its counterpart.
+type TimeCondition struct {
+	// +optional
+	NotBefore string `json:"notBefore,omitempty"`
+	// +optional
+	NotOnOrAfter string `json:"notOnOrAfter,omitempty"`
+	// +optional
+	DayMonth string `json:"dayMonth,omitempty"`
+	// +optional
+	DayMonthEnd string `json:"dayMonthEnd,omitempty"`
+	// +optional
+	Month string `json:"month,omitempty"`
+	// +optional
+	MonthEnd string `json:"monthEnd,omitempty"`
+	// +optional
+	Year string `json:"year,omitempty"`
+	// +optional
+	YearEnd string `json:"yearEnd,omitempty"`
+	// +optional
+	Hour string `json:"hour,omitempty"`
+	// +optional
+	HourEnd string `json:"hourEnd,omitempty"`
+	// +optional
+	Minute string `json:"minute,omitempty"`
+	// +optional
+	MinuteEnd string `json:"minuteEnd,omitempty"`
+}
+
+// AuthorizationPermission declares a permission of a resource server, granting access to resources or
+// scopes when its policies allow it.
+type AuthorizationPermission struct {
+	// Name of the permission
+	Name string `json:"name"`
+	// Description of the permission
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Type of the permission
+	// +kubebuilder:validation:Enum=resource;scope
+	Type string `json:"type"`
+	// DecisionStrategy combining the policies of the permission
+	// +kubebuilder:validation:Enum=UNANIMOUS;AFFIRMATIVE;CONSENSUS
+	// +optional
+	DecisionStrategy string `json:"decisionStrategy,omitempty"`
+	// Resources the permission applies to, by name
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+	// ResourceType a resource permission applies to instead of resources
+	// +optional
+	ResourceType string `json:"resourceType,omitempty"`
+	// Scopes a scope permission applies to, by name
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+	// Policies deciding the permission, by name
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+}
@@ -33,59 +33,311 @@ type ClientSecretReference struct {
 	// Key in the secret for the client secret (default: "clientSecret")
 	// +optional
 	ClientSecretKey string `json:"clientSecretKey,omitempty"`
+	// Key in the secret for the previous client secret, kept during the grace period of a secret rotation
+	// (default: "previousClientSecret")
+	// +optional
+	PreviousClientSecretKey string `json:"previousClientSecretKey,omitempty"`
+}
+
+// ConnectionReference references the Keycloak connection used to manage a resource.
+type ConnectionReference struct {
+	// Kind of the referenced connection (default: "KeycloakConnection")
+	// +kubebuilder:validation:Enum=KeycloakConnection;ClusterKeycloakConnection
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Name of the referenced connection. A KeycloakConnection must live in the same namespace.
+	Name string `json:"name"`
+}
+
+// DriftPolicy selects how the operator handles changes made directly in Keycloak.
+type DriftPolicy string
+
+const (
+	// DriftPolicyCorrect overwrites changes made in Keycloak with the desired state.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+	// DriftPolicyReport only reports changes made in Keycloak in the Drifted condition.
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
+// SyncPolicy controls how the operator keeps Keycloak in sync with a resource.
+type SyncPolicy struct {
+	// ResyncInterval is how often the Keycloak state is compared with the desired state, e.g. "10m".
+	// The operator-wide --resync-interval applies when omitted, "0s" disables periodic resync.
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+	// DriftPolicy selects what happens when the Keycloak state drifted from the desired state:
+	// "Correct" (default) overwrites the changes, "Report" only reports them.
+	// +kubebuilder:validation:Enum=Correct;Report
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// DeletionPolicy selects what happens in Keycloak when a resource is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the Keycloak object with the resource.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain leaves the Keycloak object in place when the resource is deleted.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// DeletionPolicyAnnotation overrides spec.deletionPolicy. It can be set right before deleting a resource.
+const DeletionPolicyAnnotation = "keycloak.pewty.fr/deletion-policy"
+
+// SecretRotation configures the rotation of the secret of a confidential client.
+type SecretRotation struct {
+	// Interval between two rotations of the secret, e.g. "720h". When omitted, the secret is only
+	// rotated on demand through the keycloak.pewty.fr/rotate-secret annotation.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// GracePeriod during which Keycloak still accepts the previous secret after a rotation, e.g. "1h".
+	// The previous secret stops working right away when omitted.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// SecretTemplate shapes the Secret the operator writes the client credentials to.
+// Values are Go templates rendered with .ClientID, .ClientSecret, .URL (Keycloak base URL), .Realm and
+// the OpenID Connect endpoints of the realm: .Issuer, .WellKnownURL, .AuthorizationEndpoint, .TokenEndpoint,
+// .IntrospectionEndpoint, .UserinfoEndpoint, .EndSessionEndpoint and .JWKSURI.
+// The functions upper, lower, quote and b64enc are available.
+type SecretTemplate struct {
+	// Data adds keys to the secret, next to the client ID and secret keys.
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+	// Labels added to the secret
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the secret
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ExportKind is the kind of object installation documents are exported to.
+type ExportKind string
+
+const (
+	// ExportKindConfigMap exports installation documents to a ConfigMap.
+	ExportKindConfigMap ExportKind = "ConfigMap"
+	// ExportKindSecret exports installation documents to a Secret.
+	ExportKindSecret ExportKind = "Secret"
+)
+
+// ClientExports writes documents generated by Keycloak installation providers to a ConfigMap or Secret.
+type ClientExports struct {
+	// Kind of the object the documents are written to (default: "Secret"). Documents such as keycloak.json
+	// embed the secret of confidential clients, so a ConfigMap is only fit for public clients.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +optional
+	Kind ExportKind `json:"kind,omitempty"`
+	// Name of the ConfigMap or Secret. It is created in the namespace of the Client and owned by it.
+	Name string `json:"name"`
+	// Providers lists the installation providers whose documents are exported
+	// +kubebuilder:validation:MinItems=1
+	Providers []InstallationProvider `json:"providers"`
+}
+
+// InstallationProvider selects a Keycloak installation provider and the key its document is written to.
+type InstallationProvider struct {
+	// ID of the installation provider, e.g. "keycloak-oidc-keycloak-json", "saml-idp-descriptor",
+	// "saml-sp-descriptor" or "mod-auth-mellon"
+	ID string `json:"id"`
+	// Key the document is written to (default: the provider ID)
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// RoleMappingPolicy selects how declared role mappings are reconciled.
+type RoleMappingPolicy string
+
+const (
+	// RoleMappingPolicyAdditive grants the declared roles and leaves other role mappings alone.
+	RoleMappingPolicyAdditive RoleMappingPolicy = "Additive"
+	// RoleMappingPolicyExact grants the declared roles and revokes all others.
+	RoleMappingPolicyExact RoleMappingPolicy = "Exact"
+)
+
+// ServiceAccount declares the roles granted to the service account user of a client.
+type ServiceAccount struct {
+	// RealmRoles granted to the service account
+	// +optional
+	RealmRoles []string `json:"realmRoles,omitempty"`
+	// ClientRoles granted to the service account, by clientId of the client defining the roles
+	// +optional
+	ClientRoles map[string][]string `json:"clientRoles,omitempty"`
+	// RoleMappingPolicy selects whether roles not declared are left alone ("Additive", default) or revoked
+	// ("Exact"). The default-roles-<realm> role Keycloak grants to every user is never revoked.
+	// +kubebuilder:validation:Enum=Additive;Exact
+	// +optional
+	RoleMappingPolicy RoleMappingPolicy `json:"roleMappingPolicy,omitempty"`
+}
+
+// PrunePolicy selects what happens to Keycloak objects a resource manages a list of but does not declare.
+type PrunePolicy string
+
+const (
+	// PrunePolicyRetain leaves Keycloak objects that are not declared alone.
+	PrunePolicyRetain PrunePolicy = "Retain"
+	// PrunePolicyPrune deletes Keycloak objects that are not declared.
+	PrunePolicyPrune PrunePolicy = "Prune"
+)
+
+// ClientRole declares a role of the client.
+type ClientRole struct {
+	// Name of the role
+	Name string `json:"name"`
+	// Description of the role
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Attributes of the role
+	// +optional
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// Composites lists the roles this role includes. When set, the composites of the role in Keycloak
+	// are made to match exactly, and when omitted they are left alone.
+	// +optional
+	Composites *RoleComposites `json:"composites,omitempty"`
+}
+
+// RoleComposites references the roles included in a composite role.
+type RoleComposites struct {
+	// Realm roles included in the role
+	// +optional
+	Realm []string `json:"realm,omitempty"`
+	// Client roles included in the role, by clientId of the client defining them
+	// +optional
+	Client map[string][]string `json:"client,omitempty"`
 }
 
+// RotateSecretAnnotation requests a rotation of the client secret each time its value changes,
+// e.g. when set to the current time.
+const RotateSecretAnnotation = "keycloak.pewty.fr/rotate-secret"
+
+// AdoptionPolicy selects whether the operator takes over Keycloak objects it did not create.
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyNever only manages Keycloak objects created by the resource.
+	AdoptionPolicyNever AdoptionPolicy = "Never"
+	// AdoptionPolicyIfUnowned adopts Keycloak objects that are not managed by another resource.
+	AdoptionPolicyIfUnowned AdoptionPolicy = "IfUnowned"
+	// AdoptionPolicyAlways adopts Keycloak objects even when they are managed by another resource.
+	AdoptionPolicyAlways AdoptionPolicy = "Always"
+)
+
+// ClientSpec defines the desired state of Client.
+// +kubebuilder:validation:XValidation:rule="has(self.realm) || has(self.realmRef)",message="realm or realmRef is required"
 type ClientSpec struct {
-	Realm *string `json:"realm"`
+	// ConnectionRef selects the Keycloak server managing this client.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak client is deleted with this resource ("Delete")
+	// or left in place ("Retain"). The operator-wide --default-deletion-policy applies when omitted,
+	// and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak client with the same clientId is taken over:
+	// "Never" (default) only manages clients created by this resource, "IfUnowned" also adopts clients
+	// no other resource manages, and "Always" takes over clients managed by another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// SecretRotation rotates the client secret periodically, keeping the previous one valid for a grace period.
+	// +optional
+	SecretRotation *SecretRotation `json:"secretRotation,omitempty"`
+	// Realm of the client. It is required unless realmRef is set.
+	// +optional
+	Realm *string `json:"realm,omitempty"`
+	// RealmRef references the Realm resource managing the realm of the client, in the same namespace.
+	// The client is only reconciled once the Realm is Ready, and realm defaults to its realm.
+	// +optional
+	RealmRef *RealmReference `json:"realmRef,omitempty"`
 	// SecretRef references a Kubernetes Secret containing the client ID and secret.
 	// The operator will read credentials from this secret and update it with generated values.
+	// The secret is created by the operator when client.clientId is set.
 	SecretRef ClientSecretReference `json:"secretRef"`
-	Client    ClientRepresentation  `json:"client"`
+	// SecretTemplate adds templated keys, labels and annotations to the secret, e.g. the token endpoint.
+	// +optional
+	SecretTemplate *SecretTemplate `json:"secretTemplate,omitempty"`
+	// ServiceAccount grants realm and client roles to the service account of the client.
+	// It requires client.serviceAccountsEnabled.
+	// +optional
+	ServiceAccount *ServiceAccount `json:"serviceAccount,omitempty"`
+	// Roles declares the roles of the client.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Roles []ClientRole `json:"roles,omitempty"`
+	// RolePrunePolicy selects whether client roles not listed in roles are left alone ("Retain", default)
+	// or deleted ("Prune"). Roles are only pruned when roles lists at least one role.
+	// +kubebuilder:validation:Enum=Retain;Prune
+	// +optional
+	RolePrunePolicy PrunePolicy `json:"rolePrunePolicy,omitempty"`
+	// Authorization configures the Authorization Services of the client.
+	// It requires client.authorizationServicesEnabled.
+	// +optional
+	Authorization *Authorization `json:"authorization,omitempty"`
+	// Exports writes installation documents of the client, such as keycloak.json, to a ConfigMap or Secret
+	// after each successful sync.
+	// +optional
+	Exports *ClientExports       `json:"exports,omitempty"`
+	Client  ClientRepresentation `json:"client"`
 }
 
 type ClientRepresentation struct {
-	ID                                 *string                        `json:"id,omitempty"`
-	Name                               *string                        `json:"name,omitempty"`
-	Description                        *string                        `json:"description,omitempty"`
-	Type                               *string                        `json:"type,omitempty"`
-	RootURL                            *string                        `json:"rootUrl,omitempty"`
-	AdminURL                           *string                        `json:"adminUrl,omitempty"`
-	BaseURL                            *string                        `json:"baseUrl,omitempty"`
-	SurrogateAuthRequired              *bool                          `json:"surrogateAuthRequired,omitempty"`
-	Enabled                            *bool                          `json:"enabled,omitempty"`
-	AlwaysDisplayInConsole             *bool                          `json:"alwaysDisplayInConsole,omitempty"`
-	ClientAuthenticatorType            *string                        `json:"clientAuthenticatorType,omitempty"`
-	RegistrationAccessToken            *string                        `json:"registrationAccessToken,omitempty"`
-	DefaultRoles                       []string                       `json:"defaultRoles,omitempty"`
-	RedirectUris                       []string                       `json:"redirectUris,omitempty"`
-	WebOrigins                         []string                       `json:"webOrigins,omitempty"`
-	NotBefore                          *int32                         `json:"notBefore,omitempty"`
-	BearerOnly                         *bool                          `json:"bearerOnly,omitempty"`
-	ConsentRequired                    *bool                          `json:"consentRequired,omitempty"`
-	StandardFlowEnabled                *bool                          `json:"standardFlowEnabled,omitempty"`
-	ImplicitFlowEnabled                *bool                          `json:"implicitFlowEnabled,omitempty"`
-	DirectAccessGrantsEnabled          *bool                          `json:"directAccessGrantsEnabled,omitempty"`
-	ServiceAccountsEnabled             *bool                          `json:"serviceAccountsEnabled,omitempty"`
-	AuthorizationServicesEnabled       *bool                          `json:"authorizationServicesEnabled,omitempty"`
-	DirectGrantsOnly                   *bool                          `json:"directGrantsOnly,omitempty"`
-	PublicClient                       *bool                          `json:"publicClient,omitempty"`
-	FrontchannelLogout                 *bool                          `json:"frontchannelLogout,omitempty"`
-	Protocol                           *string                        `json:"protocol,omitempty"`
-	Attributes                         map[string]string              `json:"attributes,omitempty"`
-	AuthenticationFlowBindingOverrides map[string]string              `json:"authenticationFlowBindingOverrides,omitempty"`
-	FullScopeAllowed                   *bool                          `json:"fullScopeAllowed,omitempty"`
-	NodeReRegistrationTimeout          *int32                         `json:"nodeReRegistrationTimeout,omitempty"`
-	RegisteredNodes                    map[string]int32               `json:"registeredNodes,omitempty"`
-	ProtocolMappers                    []ProtocolMapperRepresentation `json:"protocolMappers,omitempty"`
-	ClientTemplate                     *string                        `json:"clientTemplate,omitempty"`
-	UseTemplateConfig                  *bool                          `json:"useTemplateConfig,omitempty"`
-	UseTemplateScope                   *bool                          `json:"useTemplateScope,omitempty"`
-	UseTemplateMappers                 *bool                          `json:"useTemplateMappers,omitempty"`
-	DefaultClientScopes                []string                       `json:"defaultClientScopes,omitempty"`
-	OptionalClientScopes               []string                       `json:"optionalClientScopes,omitempty"`
-	// AuthorizationSettings omitted due to CRD complexity - can be managed via Keycloak API directly
-	Access map[string]bool `json:"access,omitempty"`
-	Origin *string         `json:"origin,omitempty"`
+	ID *string `json:"id,omitempty"`
+	// ClientID of the Keycloak client. When set, the operator creates the secret referenced by
+	// secretRef if it does not exist, owned by this resource, and the clientId in the secret is ignored.
+	// +optional
+	ClientID                           *string           `json:"clientId,omitempty"`
+	Name                               *string           `json:"name,omitempty"`
+	Description                        *string           `json:"description,omitempty"`
+	Type                               *string           `json:"type,omitempty"`
+	RootURL                            *string           `json:"rootUrl,omitempty"`
+	AdminURL                           *string           `json:"adminUrl,omitempty"`
+	BaseURL                            *string           `json:"baseUrl,omitempty"`
+	SurrogateAuthRequired              *bool             `json:"surrogateAuthRequired,omitempty"`
+	Enabled                            *bool             `json:"enabled,omitempty"`
+	AlwaysDisplayInConsole             *bool             `json:"alwaysDisplayInConsole,omitempty"`
+	ClientAuthenticatorType            *string           `json:"clientAuthenticatorType,omitempty"`
+	RegistrationAccessToken            *string           `json:"registrationAccessToken,omitempty"`
+	DefaultRoles                       []string          `json:"defaultRoles,omitempty"`
+	RedirectUris                       []string          `json:"redirectUris,omitempty"`
+	WebOrigins                         []string          `json:"webOrigins,omitempty"`
+	NotBefore                          *int32            `json:"notBefore,omitempty"`
+	BearerOnly                         *bool             `json:"bearerOnly,omitempty"`
+	ConsentRequired                    *bool             `json:"consentRequired,omitempty"`
+	StandardFlowEnabled                *bool             `json:"standardFlowEnabled,omitempty"`
+	ImplicitFlowEnabled                *bool             `json:"implicitFlowEnabled,omitempty"`
+	DirectAccessGrantsEnabled          *bool             `json:"directAccessGrantsEnabled,omitempty"`
+	ServiceAccountsEnabled             *bool             `json:"serviceAccountsEnabled,omitempty"`
+	AuthorizationServicesEnabled       *bool             `json:"authorizationServicesEnabled,omitempty"`
+	DirectGrantsOnly                   *bool             `json:"directGrantsOnly,omitempty"`
+	PublicClient                       *bool             `json:"publicClient,omitempty"`
+	FrontchannelLogout                 *bool             `json:"frontchannelLogout,omitempty"`
+	Protocol                           *string           `json:"protocol,omitempty"`
+	Attributes                         map[string]string `json:"attributes,omitempty"`
+	AuthenticationFlowBindingOverrides map[string]string `json:"authenticationFlowBindingOverrides,omitempty"`
+	FullScopeAllowed                   *bool             `json:"fullScopeAllowed,omitempty"`
+	NodeReRegistrationTimeout          *int32            `json:"nodeReRegistrationTimeout,omitempty"`
+	RegisteredNodes                    map[string]int32  `json:"registeredNodes,omitempty"`
+	// ProtocolMappers of the client, matched by name. Mappers not listed are deleted, all of them with an
+	// empty list. The mappers are left alone when omitted.
+	// +optional
+	ProtocolMappers      []ProtocolMapperRepresentation `json:"protocolMappers,omitzero"`
+	ClientTemplate       *string                        `json:"clientTemplate,omitempty"`
+	UseTemplateConfig    *bool                          `json:"useTemplateConfig,omitempty"`
+	UseTemplateScope     *bool                          `json:"useTemplateScope,omitempty"`
+	UseTemplateMappers   *bool                          `json:"useTemplateMappers,omitempty"`
+	DefaultClientScopes  []string                       `json:"defaultClientScopes,omitempty"`
+	OptionalClientScopes []string                       `json:"optionalClientScopes,omitempty"`
+	Access               map[string]bool                `json:"access,omitempty"`
+	Origin               *string                        `json:"origin,omitempty"`
 }
 
 // ProtocolMapperRepresentation represents a protocol mapper for a client.
@@ -105,6 +357,43 @@ type ClientStatus struct {
 	// For Kubernetes API conventions, see:
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
 
+	// ID is the internal Keycloak ID of the client. Once known, the client is looked up by this ID
+	// rather than by clientId, so that changing the clientId renames the client in place.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm the client was last synced to
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// ClientID is the clientId the client was last synced with
+	// +optional
+	ClientID string `json:"clientId,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the client was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+	// SpecHash is a hash of the client representation last applied to Keycloak
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+	// Issuer is the URL of the realm issuing tokens for the client
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+	// WellKnownURL is the OpenID Connect discovery endpoint of the realm
+	// +optional
+	WellKnownURL string `json:"wellKnownURL,omitempty"`
+	// LastSecretRotationTime is when the operator last rotated the client secret
+	// +optional
+	LastSecretRotationTime *metav1.Time `json:"lastSecretRotationTime,omitempty"`
+	// PendingSecretRotationTime is when the operator started rotating the client secret, until the new secret
+	// is written to the credentials Secret. A rotation interrupted in between is completed with the secret
+	// Keycloak generated instead of being started again.
+	// +optional
+	PendingSecretRotationTime *metav1.Time `json:"pendingSecretRotationTime,omitempty"`
+	// SecretRotationRequest is the last value of the keycloak.pewty.fr/rotate-secret annotation acted upon
+	// +optional
+	SecretRotationRequest string `json:"secretRotationRequest,omitempty"`
+
 	// conditions represent the current state of the Client resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
 	//
@@ -122,6 +411,10 @@ type ClientStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="ClientID",type=string,JSONPath=`.status.clientId`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Client is the Schema for the clients API
 type Client struct {
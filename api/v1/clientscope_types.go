@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RealmAssignment selects whether the realm assigns a client scope to the clients created in it.
+type RealmAssignment string
+
+const (
+	// RealmAssignmentDefault assigns the client scope to new clients as a default scope.
+	RealmAssignmentDefault RealmAssignment = "Default"
+	// RealmAssignmentOptional assigns the client scope to new clients as an optional scope.
+	RealmAssignmentOptional RealmAssignment = "Optional"
+	// RealmAssignmentNone does not assign the client scope to new clients.
+	RealmAssignmentNone RealmAssignment = "None"
+)
+
+// ClientScopeSpec defines the desired state of ClientScope.
+// +kubebuilder:validation:XValidation:rule="has(self.realm) || has(self.realmRef)",message="realm or realmRef is required"
+type ClientScopeSpec struct {
+	// ConnectionRef selects the Keycloak server managing this client scope.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak client scope is deleted with this resource ("Delete")
+	// or left in place ("Retain"). The operator-wide --default-deletion-policy applies when omitted,
+	// and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak client scope with the same name is taken over:
+	// "Never" (default) only manages client scopes created by this resource, "IfUnowned" also adopts
+	// client scopes no other resource manages, and "Always" takes over client scopes managed by another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Realm of the client scope. It is required unless realmRef is set.
+	// +optional
+	Realm *string `json:"realm,omitempty"`
+	// RealmRef references the Realm resource managing the realm of the client scope, in the same namespace.
+	// The client scope is only reconciled once the Realm is Ready, and realm defaults to its realm.
+	// +optional
+	RealmRef *RealmReference `json:"realmRef,omitempty"`
+	// Name of the client scope in Keycloak, as referenced by clients (default: the name of the resource).
+	// Changing it renames the client scope.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Description of the client scope
+	// +optional
+	Description *string `json:"description,omitempty"`
+	// Protocol of the client scope
+	// +kubebuilder:validation:Enum=openid-connect;saml
+	// +kubebuilder:default=openid-connect
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+	// Attributes of the client scope, e.g. "include.in.token.scope" or "display.on.consent.screen".
+	// Attributes not listed are left alone.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// ProtocolMappers of the client scope, matched by name. Mappers not listed are deleted, all of them
+	// with an empty list. The mappers are left alone when omitted.
+	// +optional
+	ProtocolMappers []ProtocolMapperRepresentation `json:"protocolMappers,omitzero"`
+	// RealmAssignment selects whether the realm assigns the client scope to the clients created in it,
+	// as a "Default" or "Optional" scope, or not at all ("None"). The realm assignment is left alone when omitted.
+	// +kubebuilder:validation:Enum=Default;Optional;None
+	// +optional
+	RealmAssignment RealmAssignment `json:"realmAssignment,omitempty"`
+}
+
+// ClientScopeStatus defines the observed state of ClientScope.
+type ClientScopeStatus struct {
+	// ID is the internal Keycloak ID of the client scope. Once known, the client scope is looked up by this ID
+	// rather than by name, so that changing spec.name renames the client scope in place.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm the client scope was last synced to
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// Name is the name the client scope was last synced with
+	// +optional
+	Name string `json:"name,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the client scope was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// conditions represent the current state of the ClientScope resource.
+	// The "Ready" condition reports whether the client scope is in sync with Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="Scope",type=string,JSONPath=`.status.name`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClientScope is the Schema for the clientscopes API
+type ClientScope struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of ClientScope
+	// +required
+	Spec ClientScopeSpec `json:"spec"`
+
+	// status defines the observed state of ClientScope
+	// +optional
+	Status ClientScopeStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClientScopeList contains a list of ClientScope
+type ClientScopeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClientScope `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClientScope{}, &ClientScopeList{})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GroupReference references a Group resource in the same namespace.
+type GroupReference struct {
+	// Name of the Group resource
+	Name string `json:"name"`
+}
+
+// GroupSpec defines the desired state of Group.
+// +kubebuilder:validation:XValidation:rule="has(self.realm) || has(self.realmRef)",message="realm or realmRef is required"
+type GroupSpec struct {
+	// ConnectionRef selects the Keycloak server managing this group.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak group is deleted with this resource ("Delete")
+	// or left in place ("Retain"). The operator-wide --default-deletion-policy applies when omitted,
+	// and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// Deleting a group deletes its subgroups in Keycloak.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak group with the same path is taken over:
+	// "Never" (default) only manages groups created by this resource, "IfUnowned" also adopts groups
+	// no other resource manages, and "Always" takes over groups managed by another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Realm of the group. It is required unless realmRef is set.
+	// +optional
+	Realm *string `json:"realm,omitempty"`
+	// RealmRef references the Realm resource managing the realm of the group, in the same namespace.
+	// The group is only reconciled once the Realm is Ready, and realm defaults to its realm.
+	// +optional
+	RealmRef *RealmReference `json:"realmRef,omitempty"`
+	// Name of the group in Keycloak (default: the name of the resource). Changing it renames the group.
+	// +kubebuilder:validation:Pattern=`^[^/]*$`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// ParentRef references the Group resource of the parent group, in the same namespace, making this group
+	// one of its subgroups. The group is only reconciled once the parent is Ready, and changing parentRef
+	// moves the group. The group is a top-level group when omitted.
+	// +optional
+	ParentRef *GroupReference `json:"parentRef,omitempty"`
+	// Attributes of the group. Attributes not listed are left alone.
+	// +optional
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// RealmRoles granted to the members of the group
+	// +optional
+	RealmRoles []string `json:"realmRoles,omitempty"`
+	// ClientRoles granted to the members of the group, by clientId of the client defining the roles
+	// +optional
+	ClientRoles map[string][]string `json:"clientRoles,omitempty"`
+	// RoleMappingPolicy selects whether roles not declared are left alone ("Additive", default) or revoked
+	// ("Exact").
+	// +kubebuilder:validation:Enum=Additive;Exact
+	// +optional
+	RoleMappingPolicy RoleMappingPolicy `json:"roleMappingPolicy,omitempty"`
+}
+
+// GroupStatus defines the observed state of Group.
+type GroupStatus struct {
+	// ID is the internal Keycloak ID of the group. Once known, the group is looked up by this ID
+	// rather than by path, so that changing spec.name or spec.parentRef renames or moves the group in place.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm the group was last synced to
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// Path is the path of the group in Keycloak, e.g. "/engineering/backend"
+	// +optional
+	Path string `json:"path,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the group was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// conditions represent the current state of the Group resource.
+	// The "Ready" condition reports whether the group is in sync with Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.status.path`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Group is the Schema for the groups API
+type Group struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of Group
+	// +required
+	Spec GroupSpec `json:"spec"`
+
+	// status defines the observed state of Group
+	// +optional
+	Status GroupStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// GroupList contains a list of Group
+type GroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []Group `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Group{}, &GroupList{})
+}
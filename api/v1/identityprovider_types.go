@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IdentityProviderSecretReference references the key of a Secret holding the client secret of an identity provider.
+type IdentityProviderSecretReference struct {
+	// Name of the secret in the same namespace as the resource
+	Name string `json:"name"`
+	// Key in the secret (default: "clientSecret")
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// IdentityProviderMapper maps the claims or assertions of an identity provider to brokered users.
+type IdentityProviderMapper struct {
+	// Name of the mapper, unique within the identity provider
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// IdentityProviderMapper is the type of the mapper, e.g. "oidc-user-attribute-idp-mapper"
+	// or "hardcoded-role-idp-mapper"
+	// +kubebuilder:validation:MinLength=1
+	IdentityProviderMapper string `json:"identityProviderMapper"`
+	// Config of the mapper, e.g. "syncMode", "claim" or "user.attribute"
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// IdentityProviderSpec defines the desired state of IdentityProvider. Besides the operator settings, fields
+// mirror the Keycloak identity provider representation and are left alone in Keycloak when omitted.
+// +kubebuilder:validation:XValidation:rule="has(self.realm) || has(self.realmRef)",message="realm or realmRef is required"
+// +kubebuilder:validation:XValidation:rule="!has(self.config) || !('clientSecret' in self.config)",message="the client secret must be set through clientSecretRef"
+type IdentityProviderSpec struct {
+	// ConnectionRef selects the Keycloak server managing this identity provider.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak identity provider is deleted with this resource ("Delete")
+	// or left in place ("Retain"). The operator-wide --default-deletion-policy applies when omitted,
+	// and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// Deleting an identity provider removes the links of the users brokered through it.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak identity provider with the same alias is taken over:
+	// "Never" (default) only manages identity providers created by this resource, "IfUnowned" also adopts
+	// identity providers no other resource manages, and "Always" takes over identity providers managed by
+	// another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Realm of the identity provider. It is required unless realmRef is set.
+	// +optional
+	Realm *string `json:"realm,omitempty"`
+	// RealmRef references the Realm resource managing the realm of the identity provider, in the same namespace.
+	// The identity provider is only reconciled once the Realm is Ready, and realm defaults to its realm.
+	// +optional
+	RealmRef *RealmReference `json:"realmRef,omitempty"`
+	// Alias of the identity provider in Keycloak, part of its redirect URI (default: the name of the resource).
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="alias is immutable"
+	// +optional
+	Alias string `json:"alias,omitempty"`
+	// ProviderID is the type of the identity provider, e.g. "oidc", "saml", "github" or "microsoft"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="providerId is immutable"
+	ProviderID string `json:"providerId"`
+	// +optional
+	DisplayName *string `json:"displayName,omitempty"`
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// TrustEmail skips the verification of the emails the identity provider asserts
+	// +optional
+	TrustEmail *bool `json:"trustEmail,omitempty"`
+	// StoreToken stores the tokens of the identity provider after authenticating users
+	// +optional
+	StoreToken *bool `json:"storeToken,omitempty"`
+	// LinkOnly only lets users link their account to the identity provider, not log in through it
+	// +optional
+	LinkOnly *bool `json:"linkOnly,omitempty"`
+	// FirstBrokerLoginFlowAlias is the authentication flow run the first time a user logs in through the
+	// identity provider (Keycloak default: "first broker login")
+	// +optional
+	FirstBrokerLoginFlowAlias *string `json:"firstBrokerLoginFlowAlias,omitempty"`
+	// PostBrokerLoginFlowAlias is the authentication flow run after each login through the identity provider
+	// +optional
+	PostBrokerLoginFlowAlias *string `json:"postBrokerLoginFlowAlias,omitempty"`
+	// Config of the identity provider, e.g. "clientId", "authorizationUrl", "tokenUrl" or "defaultScope".
+	// Keys not listed are left alone. The client secret is read from clientSecretRef.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+	// ClientSecretRef references the Secret key holding the client secret of the identity provider.
+	// +optional
+	ClientSecretRef *IdentityProviderSecretReference `json:"clientSecretRef,omitempty"`
+	// Mappers of the identity provider, matched by name. Mappers not listed are deleted, all of them with an
+	// empty list. The mappers are left alone when omitted.
+	// +optional
+	Mappers []IdentityProviderMapper `json:"mappers,omitzero"`
+}
+
+// IdentityProviderStatus defines the observed state of IdentityProvider.
+type IdentityProviderStatus struct {
+	// ID is the internal Keycloak ID of the identity provider
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm the identity provider was last synced to
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// Alias is the alias the identity provider was last synced with
+	// +optional
+	Alias string `json:"alias,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the identity provider was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+	// ClientSecretVersion is the resource version of the client secret Secret last applied
+	// +optional
+	ClientSecretVersion string `json:"clientSecretVersion,omitempty"`
+
+	// conditions represent the current state of the IdentityProvider resource.
+	// The "Ready" condition reports whether the identity provider is in sync with Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="Alias",type=string,JSONPath=`.status.alias`
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.spec.providerId`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// IdentityProvider is the Schema for the identityproviders API
+type IdentityProvider struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of IdentityProvider
+	// +required
+	Spec IdentityProviderSpec `json:"spec"`
+
+	// status defines the observed state of IdentityProvider
+	// +optional
+	Status IdentityProviderStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// IdentityProviderList contains a list of IdentityProvider
+type IdentityProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []IdentityProvider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IdentityProvider{}, &IdentityProviderList{})
+}
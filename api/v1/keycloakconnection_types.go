@@ -0,0 +1,199 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConnectionCredentialsReference references the Secret holding the credentials the operator
+// uses to authenticate against the Keycloak admin API.
+type ConnectionCredentialsReference struct {
+	// Name of the secret
+	Name string `json:"name"`
+	// Namespace of the secret. Only used by ClusterKeycloakConnection; a KeycloakConnection
+	// always reads the secret from its own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Key in the secret for the username (default: "username")
+	// +optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+	// Key in the secret for the password (default: "password")
+	// +optional
+	PasswordKey string `json:"passwordKey,omitempty"`
+	// Key in the secret for the client ID (default: "clientId")
+	// +optional
+	ClientIDKey string `json:"clientIdKey,omitempty"`
+	// Key in the secret for the client secret (default: "clientSecret")
+	// +optional
+	ClientSecretKey string `json:"clientSecretKey,omitempty"`
+	// Key in the secret for the PEM encoded private key (default: "privateKey")
+	// +optional
+	PrivateKeyKey string `json:"privateKeyKey,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret.
+type SecretKeyReference struct {
+	// Name of the secret
+	Name string `json:"name"`
+	// Key in the secret (default: "ca.crt")
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TLSSecretReference references a kubernetes.io/tls Secret.
+type TLSSecretReference struct {
+	// Name of the secret
+	Name string `json:"name"`
+	// Key in the secret for the PEM encoded certificate (default: "tls.crt")
+	// +optional
+	CertificateKey string `json:"certificateKey,omitempty"`
+	// Key in the secret for the PEM encoded private key (default: "tls.key")
+	// +optional
+	PrivateKeyKey string `json:"privateKeyKey,omitempty"`
+}
+
+// ConnectionTLS configures the TLS settings used to reach Keycloak.
+// Secrets are read from the namespace of the credentials secret.
+type ConnectionTLS struct {
+	// CASecretRef references a Secret key holding a PEM encoded CA bundle used to verify the
+	// Keycloak server certificate, in addition to caBundle.
+	// +optional
+	CASecretRef *SecretKeyReference `json:"caSecretRef,omitempty"`
+	// ClientCertificateSecretRef references the Secret holding the client certificate and key
+	// presented to Keycloak for mutual TLS.
+	// +optional
+	ClientCertificateSecretRef *TLSSecretReference `json:"clientCertificateSecretRef,omitempty"`
+}
+
+// KeycloakConnectionSpec defines how the operator reaches and authenticates against a Keycloak server.
+type KeycloakConnectionSpec struct {
+	// URL of the Keycloak server, e.g. https://keycloak.example.com
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// Realm used to authenticate the operator (default: "master").
+	// Managed resources choose their own realm.
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// AuthMethod selects how the operator authenticates:
+	// - "password": admin username and password through the admin-cli client
+	// - "client_credentials": confidential client ID and secret
+	// - "private_key_jwt": confidential client ID and a client assertion signed with its private key
+	// +kubebuilder:validation:Enum=password;client_credentials;private_key_jwt
+	// +kubebuilder:default=password
+	// +optional
+	AuthMethod string `json:"authMethod,omitempty"`
+	// CredentialsSecretRef references the Secret containing the operator credentials.
+	CredentialsSecretRef ConnectionCredentialsReference `json:"credentialsSecretRef"`
+	// CABundle is a PEM encoded CA bundle used to verify the Keycloak server certificate.
+	// The system trust store is used when empty.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+	// TLS configures a CA bundle Secret and a client certificate for mutual TLS.
+	// +optional
+	TLS *ConnectionTLS `json:"tls,omitempty"`
+	// ProxyURL of the HTTP(S) proxy used to reach Keycloak, e.g. http://proxy.example.com:3128.
+	// The HTTPS_PROXY and NO_PROXY environment variables of the operator apply when empty.
+	// +kubebuilder:validation:Pattern=`^(https?|socks5)://`
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// Timeout of each request sent to Keycloak, e.g. "30s". Requests are not bounded when empty.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// KeycloakConnectionStatus defines the observed state of a Keycloak connection.
+type KeycloakConnectionStatus struct {
+	// conditions represent the current state of the connection.
+	// The "Ready" condition reports whether the operator could authenticate against Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KeycloakConnection is the Schema for the keycloakconnections API.
+// It can only be referenced by resources in its own namespace.
+type KeycloakConnection struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of KeycloakConnection
+	// +required
+	Spec KeycloakConnectionSpec `json:"spec"`
+
+	// status defines the observed state of KeycloakConnection
+	// +optional
+	Status KeycloakConnectionStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// KeycloakConnectionList contains a list of KeycloakConnection
+type KeycloakConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []KeycloakConnection `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterKeycloakConnection is the Schema for the clusterkeycloakconnections API.
+// It can be referenced by resources in any namespace.
+type ClusterKeycloakConnection struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of ClusterKeycloakConnection
+	// +required
+	Spec KeycloakConnectionSpec `json:"spec"`
+
+	// status defines the observed state of ClusterKeycloakConnection
+	// +optional
+	Status KeycloakConnectionStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterKeycloakConnectionList contains a list of ClusterKeycloakConnection
+type ClusterKeycloakConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClusterKeycloakConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KeycloakConnection{}, &KeycloakConnectionList{})
+	SchemeBuilder.Register(&ClusterKeycloakConnection{}, &ClusterKeycloakConnectionList{})
+}
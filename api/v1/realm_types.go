@@ -0,0 +1,282 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RealmReference references a Realm resource in the same namespace.
+type RealmReference struct {
+	// Name of the Realm resource
+	Name string `json:"name"`
+}
+
+// PasswordSecretReference references the key of a Secret holding a password.
+type PasswordSecretReference struct {
+	// Name of the secret in the same namespace as the resource
+	Name string `json:"name"`
+	// Key in the secret (default: "password")
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// SMTPServer configures the server the realm sends emails through.
+type SMTPServer struct {
+	// Host of the SMTP server
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+	// Port of the SMTP server, e.g. "587"
+	// +optional
+	Port string `json:"port,omitempty"`
+	// From is the sender address of the emails
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+	// FromDisplayName is the display name of the sender
+	// +optional
+	FromDisplayName string `json:"fromDisplayName,omitempty"`
+	// ReplyTo is the reply-to address of the emails
+	// +optional
+	ReplyTo string `json:"replyTo,omitempty"`
+	// ReplyToDisplayName is the display name of the reply-to address
+	// +optional
+	ReplyToDisplayName string `json:"replyToDisplayName,omitempty"`
+	// EnvelopeFrom is the bounce address of the emails
+	// +optional
+	EnvelopeFrom string `json:"envelopeFrom,omitempty"`
+	// SSL connects to the SMTP server over TLS
+	// +optional
+	SSL bool `json:"ssl,omitempty"`
+	// StartTLS upgrades the connection to the SMTP server with STARTTLS
+	// +optional
+	StartTLS bool `json:"starttls,omitempty"`
+	// Auth authenticates against the SMTP server with user and the password of passwordSecretRef
+	// +optional
+	Auth bool `json:"auth,omitempty"`
+	// User authenticating against the SMTP server
+	// +optional
+	User string `json:"user,omitempty"`
+	// PasswordSecretRef references the Secret key holding the password of user
+	// +optional
+	PasswordSecretRef *PasswordSecretReference `json:"passwordSecretRef,omitempty"`
+}
+
+// RealmSpec defines the desired state of Realm. Besides the operator settings, fields mirror the
+// Keycloak realm representation and are left alone in Keycloak when omitted. Durations are in seconds.
+type RealmSpec struct {
+	// ConnectionRef selects the Keycloak server managing this realm.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak realm, with everything it contains, is deleted with this
+	// resource ("Delete") or left in place ("Retain"). The operator-wide --default-deletion-policy applies
+	// when omitted, and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak realm with the same name is taken over:
+	// "Never" (default) only manages realms created by this resource, "IfUnowned" also adopts realms
+	// no other resource manages, and "Always" takes over realms managed by another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Realm is the name of the realm in Keycloak (default: the name of the resource).
+	// Changing it renames the realm.
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// +optional
+	DisplayName *string `json:"displayName,omitempty"`
+	// +optional
+	DisplayNameHTML *string `json:"displayNameHtml,omitempty"`
+	// Attributes of the realm. Attributes not listed are left alone.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Login settings
+
+	// +kubebuilder:validation:Enum=all;external;none
+	// +optional
+	SslRequired *string `json:"sslRequired,omitempty"`
+	// +optional
+	RegistrationAllowed *bool `json:"registrationAllowed,omitempty"`
+	// +optional
+	RegistrationEmailAsUsername *bool `json:"registrationEmailAsUsername,omitempty"`
+	// +optional
+	EditUsernameAllowed *bool `json:"editUsernameAllowed,omitempty"`
+	// +optional
+	ResetPasswordAllowed *bool `json:"resetPasswordAllowed,omitempty"`
+	// +optional
+	RememberMe *bool `json:"rememberMe,omitempty"`
+	// +optional
+	VerifyEmail *bool `json:"verifyEmail,omitempty"`
+	// +optional
+	LoginWithEmailAllowed *bool `json:"loginWithEmailAllowed,omitempty"`
+	// +optional
+	DuplicateEmailsAllowed *bool `json:"duplicateEmailsAllowed,omitempty"`
+
+	// Tokens
+
+	// DefaultSignatureAlgorithm of the tokens, e.g. "RS256"
+	// +optional
+	DefaultSignatureAlgorithm *string `json:"defaultSignatureAlgorithm,omitempty"`
+	// +optional
+	RevokeRefreshToken *bool `json:"revokeRefreshToken,omitempty"`
+	// +optional
+	RefreshTokenMaxReuse *int32 `json:"refreshTokenMaxReuse,omitempty"`
+	// +optional
+	AccessTokenLifespan *int32 `json:"accessTokenLifespan,omitempty"`
+	// +optional
+	AccessTokenLifespanForImplicitFlow *int32 `json:"accessTokenLifespanForImplicitFlow,omitempty"`
+	// +optional
+	AccessCodeLifespan *int32 `json:"accessCodeLifespan,omitempty"`
+	// +optional
+	AccessCodeLifespanUserAction *int32 `json:"accessCodeLifespanUserAction,omitempty"`
+	// +optional
+	AccessCodeLifespanLogin *int32 `json:"accessCodeLifespanLogin,omitempty"`
+	// +optional
+	ActionTokenGeneratedByUserLifespan *int32 `json:"actionTokenGeneratedByUserLifespan,omitempty"`
+	// +optional
+	ActionTokenGeneratedByAdminLifespan *int32 `json:"actionTokenGeneratedByAdminLifespan,omitempty"`
+
+	// SSO sessions
+
+	// +optional
+	SsoSessionIdleTimeout *int32 `json:"ssoSessionIdleTimeout,omitempty"`
+	// +optional
+	SsoSessionMaxLifespan *int32 `json:"ssoSessionMaxLifespan,omitempty"`
+	// +optional
+	SsoSessionIdleTimeoutRememberMe *int32 `json:"ssoSessionIdleTimeoutRememberMe,omitempty"`
+	// +optional
+	SsoSessionMaxLifespanRememberMe *int32 `json:"ssoSessionMaxLifespanRememberMe,omitempty"`
+	// +optional
+	OfflineSessionIdleTimeout *int32 `json:"offlineSessionIdleTimeout,omitempty"`
+	// +optional
+	OfflineSessionMaxLifespanEnabled *bool `json:"offlineSessionMaxLifespanEnabled,omitempty"`
+	// +optional
+	OfflineSessionMaxLifespan *int32 `json:"offlineSessionMaxLifespan,omitempty"`
+
+	// Brute force detection
+
+	// +optional
+	BruteForceProtected *bool `json:"bruteForceProtected,omitempty"`
+	// +optional
+	PermanentLockout *bool `json:"permanentLockout,omitempty"`
+	// +optional
+	MaxFailureWaitSeconds *int32 `json:"maxFailureWaitSeconds,omitempty"`
+	// +optional
+	MinimumQuickLoginWaitSeconds *int32 `json:"minimumQuickLoginWaitSeconds,omitempty"`
+	// +optional
+	WaitIncrementSeconds *int32 `json:"waitIncrementSeconds,omitempty"`
+	// +optional
+	QuickLoginCheckMilliSeconds *int64 `json:"quickLoginCheckMilliSeconds,omitempty"`
+	// +optional
+	MaxDeltaTimeSeconds *int32 `json:"maxDeltaTimeSeconds,omitempty"`
+	// +optional
+	FailureFactor *int32 `json:"failureFactor,omitempty"`
+
+	// SMTPServer the realm sends emails through
+	// +optional
+	SMTPServer *SMTPServer `json:"smtpServer,omitempty"`
+
+	// Themes
+
+	// +optional
+	LoginTheme *string `json:"loginTheme,omitempty"`
+	// +optional
+	AccountTheme *string `json:"accountTheme,omitempty"`
+	// +optional
+	AdminTheme *string `json:"adminTheme,omitempty"`
+	// +optional
+	EmailTheme *string `json:"emailTheme,omitempty"`
+
+	// Internationalization
+
+	// +optional
+	InternationalizationEnabled *bool `json:"internationalizationEnabled,omitempty"`
+	// +optional
+	SupportedLocales []string `json:"supportedLocales,omitempty"`
+	// +optional
+	DefaultLocale *string `json:"defaultLocale,omitempty"`
+}
+
+// RealmStatus defines the observed state of Realm.
+type RealmStatus struct {
+	// ID is the internal Keycloak ID of the realm
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm is the name the realm was last synced with. Once known, the realm is looked up by this name,
+	// so that changing spec.realm renames the realm in place.
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the realm was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+	// SMTPPasswordVersion is the resource version of the SMTP password Secret last applied
+	// +optional
+	SMTPPasswordVersion string `json:"smtpPasswordVersion,omitempty"`
+
+	// conditions represent the current state of the Realm resource.
+	// The "Ready" condition reports whether the realm is in sync with Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Realm is the Schema for the realms API
+type Realm struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of Realm
+	// +required
+	Spec RealmSpec `json:"spec"`
+
+	// status defines the observed state of Realm
+	// +optional
+	Status RealmStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// RealmList contains a list of Realm
+type RealmList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []Realm `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Realm{}, &RealmList{})
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RealmRoleSpec defines the desired state of RealmRole.
+// +kubebuilder:validation:XValidation:rule="has(self.realm) || has(self.realmRef)",message="realm or realmRef is required"
+type RealmRoleSpec struct {
+	// ConnectionRef selects the Keycloak server managing this role.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak role is deleted with this resource ("Delete")
+	// or left in place ("Retain"). The operator-wide --default-deletion-policy applies when omitted,
+	// and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak realm role with the same name is taken over:
+	// "Never" (default) only manages roles created by this resource, "IfUnowned" also adopts roles
+	// no other resource manages, and "Always" takes over roles managed by another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Realm of the role. It is required unless realmRef is set.
+	// +optional
+	Realm *string `json:"realm,omitempty"`
+	// RealmRef references the Realm resource managing the realm of the role, in the same namespace.
+	// The role is only reconciled once the Realm is Ready, and realm defaults to its realm.
+	// +optional
+	RealmRef *RealmReference `json:"realmRef,omitempty"`
+	// Name of the role in Keycloak (default: the name of the resource). Changing it renames the role.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Description of the role
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Attributes of the role. Attributes not listed are left alone.
+	// +optional
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// Composites lists the roles this role includes. When set, the composites of the role in Keycloak
+	// are made to match exactly, and when omitted they are left alone.
+	// +optional
+	Composites *RoleComposites `json:"composites,omitempty"`
+}
+
+// RealmRoleStatus defines the observed state of RealmRole.
+type RealmRoleStatus struct {
+	// ID is the internal Keycloak ID of the role. Once known, the role is looked up by this ID
+	// rather than by name, so that changing spec.name renames the role in place.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm the role was last synced to
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// Name is the name the role was last synced with
+	// +optional
+	Name string `json:"name,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the role was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// conditions represent the current state of the RealmRole resource.
+	// The "Ready" condition reports whether the role is in sync with Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="Role",type=string,JSONPath=`.status.name`
+// +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RealmRole is the Schema for the realmroles API
+type RealmRole struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of RealmRole
+	// +required
+	Spec RealmRoleSpec `json:"spec"`
+
+	// status defines the observed state of RealmRole
+	// +optional
+	Status RealmRoleStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// RealmRoleList contains a list of RealmRole
+type RealmRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []RealmRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RealmRole{}, &RealmRoleList{})
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProfilePolicy selects how the profile fields of a user are reconciled.
+type ProfilePolicy string
+
+const (
+	// ProfilePolicyEnforce keeps the profile fields of the user as declared.
+	ProfilePolicyEnforce ProfilePolicy = "Enforce"
+	// ProfilePolicyCreateOnly sets the profile fields when the user is created and leaves them alone afterwards.
+	ProfilePolicyCreateOnly ProfilePolicy = "CreateOnly"
+)
+
+// MembershipPolicy selects how declared group memberships are reconciled.
+type MembershipPolicy string
+
+const (
+	// MembershipPolicyAdditive joins the declared groups and leaves other memberships alone.
+	MembershipPolicyAdditive MembershipPolicy = "Additive"
+	// MembershipPolicyExact joins the declared groups and leaves all others.
+	MembershipPolicyExact MembershipPolicy = "Exact"
+)
+
+// InitialPassword references the password set on a user when it is created.
+type InitialPassword struct {
+	// SecretRef references the key of the Secret holding the password
+	SecretRef PasswordSecretReference `json:"secretRef"`
+	// Temporary requires the user to change the password at the first login
+	// +optional
+	Temporary bool `json:"temporary,omitempty"`
+}
+
+// UserSpec defines the desired state of User.
+// +kubebuilder:validation:XValidation:rule="has(self.realm) || has(self.realmRef)",message="realm or realmRef is required"
+type UserSpec struct {
+	// ConnectionRef selects the Keycloak server managing this user.
+	// The operator-wide connection configured through KEYCLOAK_* environment variables is used when omitted.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+	// SyncPolicy controls periodic resync and drift handling.
+	// +optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+	// DeletionPolicy selects whether the Keycloak user is deleted with this resource ("Delete")
+	// or left in place ("Retain"). The operator-wide --default-deletion-policy applies when omitted,
+	// and the keycloak.pewty.fr/deletion-policy annotation overrides both.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy selects whether an existing Keycloak user with the same username is taken over:
+	// "Never" (default) only manages users created by this resource, "IfUnowned" also adopts users
+	// no other resource manages, and "Always" takes over users managed by another resource.
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Always
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Realm of the user. It is required unless realmRef is set.
+	// +optional
+	Realm *string `json:"realm,omitempty"`
+	// RealmRef references the Realm resource managing the realm of the user, in the same namespace.
+	// The user is only reconciled once the Realm is Ready, and realm defaults to its realm.
+	// +optional
+	RealmRef *RealmReference `json:"realmRef,omitempty"`
+	// Username of the user in Keycloak (default: the name of the resource). Keycloak stores usernames in
+	// lowercase. Changing it renames the user, provided the realm allows editing usernames.
+	// +optional
+	Username string `json:"username,omitempty"`
+	// Enabled allows the user to log in. Users are created enabled unless it is false, and it is only kept
+	// as declared when set, whatever the profilePolicy.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// ProfilePolicy selects whether the profile fields, email, firstName, lastName and attributes, are kept
+	// as declared ("Enforce", default) or only set when the user is created ("CreateOnly"), leaving later
+	// changes made by the user alone. It does not cover username, enabled and emailVerified, which are kept
+	// as declared when set.
+	// +kubebuilder:validation:Enum=Enforce;CreateOnly
+	// +optional
+	ProfilePolicy ProfilePolicy `json:"profilePolicy,omitempty"`
+	// Email of the user
+	// +optional
+	Email *string `json:"email,omitempty"`
+	// EmailVerified marks the email of the user as verified
+	// +optional
+	EmailVerified *bool `json:"emailVerified,omitempty"`
+	// FirstName of the user
+	// +optional
+	FirstName *string `json:"firstName,omitempty"`
+	// LastName of the user
+	// +optional
+	LastName *string `json:"lastName,omitempty"`
+	// Attributes of the user. Attributes not listed are left alone.
+	// +optional
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// RequiredActions the user must complete at the next login, e.g. "UPDATE_PASSWORD" or "VERIFY_EMAIL".
+	// They are only set when the user is created, as Keycloak removes them once completed.
+	// +optional
+	RequiredActions []string `json:"requiredActions,omitempty"`
+	// InitialPassword sets the password of the user when it is created. Later changes of the Secret
+	// are ignored.
+	// +optional
+	InitialPassword *InitialPassword `json:"initialPassword,omitempty"`
+	// Groups the user is a member of, by path, e.g. "/engineering/backend"
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+	// GroupMembershipPolicy selects whether groups not declared are left alone ("Additive", default) or
+	// left by the user ("Exact").
+	// +kubebuilder:validation:Enum=Additive;Exact
+	// +optional
+	GroupMembershipPolicy MembershipPolicy `json:"groupMembershipPolicy,omitempty"`
+	// RealmRoles granted to the user
+	// +optional
+	RealmRoles []string `json:"realmRoles,omitempty"`
+	// ClientRoles granted to the user, by clientId of the client defining the roles
+	// +optional
+	ClientRoles map[string][]string `json:"clientRoles,omitempty"`
+	// RoleMappingPolicy selects whether roles not declared are left alone ("Additive", default) or revoked
+	// ("Exact"). The default-roles-<realm> role Keycloak grants to every user is never revoked.
+	// +kubebuilder:validation:Enum=Additive;Exact
+	// +optional
+	RoleMappingPolicy RoleMappingPolicy `json:"roleMappingPolicy,omitempty"`
+}
+
+// UserStatus defines the observed state of User.
+type UserStatus struct {
+	// ID is the internal Keycloak ID of the user. Once known, the user is looked up by this ID
+	// rather than by username, so that changing spec.username renames the user in place.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Realm the user was last synced to
+	// +optional
+	Realm string `json:"realm,omitempty"`
+	// Username is the username the user was last synced with
+	// +optional
+	Username string `json:"username,omitempty"`
+	// ObservedGeneration is the generation of the resource last synced to Keycloak
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncedTime is when the user was last found or made up to date in Keycloak
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// conditions represent the current state of the User resource.
+	// The "Ready" condition reports whether the user is in sync with Keycloak.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Realm",type=string,JSONPath=`.status.realm`
+// +kubebuilder:printcolumn:name="Username",type=string,JSONPath=`.status.username`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// User is the Schema for the users API
+type User struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of User
+	// +required
+	Spec UserSpec `json:"spec"`
+
+	// status defines the observed state of User
+	// +optional
+	Status UserStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// UserList contains a list of User
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []User `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&User{}, &UserList{})
+}
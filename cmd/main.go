@@ -20,12 +20,12 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
-	gocloak "github.com/Nerzal/gocloak/v13"
 	"github.com/go-logr/zerologr"
 	"github.com/rs/zerolog"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -40,6 +40,7 @@ import (
 
 	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
 	"github.com/pewty-fr/keycloak-client-operator/internal/controller"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -65,6 +66,8 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var logLevel string
+	var resyncInterval time.Duration
+	var defaultDeletionPolicy string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -84,6 +87,12 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&resyncInterval, "resync-interval", 10*time.Minute,
+		"How often resources are compared with Keycloak to detect drift, unless their syncPolicy overrides it. "+
+			"Use 0 to disable periodic resync.")
+	flag.StringVar(&defaultDeletionPolicy, "default-deletion-policy", string(keycloakv1.DeletionPolicyDelete),
+		"Whether Keycloak objects are deleted with their resource (Delete) or left in place (Retain), "+
+			"unless the resource sets spec.deletionPolicy or the keycloak.pewty.fr/deletion-policy annotation.")
 	flag.Parse()
 
 	// Setup zerolog with JSON output
@@ -99,6 +108,13 @@ func main() {
 	logger := zerologr.New(&zerologInstance)
 	ctrl.SetLogger(logger)
 
+	switch keycloakv1.DeletionPolicy(defaultDeletionPolicy) {
+	case keycloakv1.DeletionPolicyDelete, keycloakv1.DeletionPolicyRetain:
+	default:
+		setupLog.Error(nil, "Invalid --default-deletion-policy, must be Delete or Retain", "value", defaultDeletionPolicy)
+		os.Exit(1)
+	}
+
 	// Redirect klog to our logger (this handles leader election and other k8s client-go logs)
 	klog.SetLogger(logger)
 
@@ -193,48 +209,135 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize Keycloak client
-	keycloakURL := os.Getenv("KEYCLOAK_URL")
-	keycloakUser := os.Getenv("KEYCLOAK_USER")
-	keycloakPass := os.Getenv("KEYCLOAK_PASSWORD")
-	keycloakRealm := os.Getenv("KEYCLOAK_REALM")
-
-	if keycloakURL == "" || keycloakUser == "" || keycloakPass == "" || keycloakRealm == "" {
-		setupLog.Error(nil, "Keycloak configuration is required",
-			"KEYCLOAK_URL", keycloakURL != "",
-			"KEYCLOAK_USER", keycloakUser != "",
-			"KEYCLOAK_PASSWORD", keycloakPass != "",
-			"KEYCLOAK_REALM", keycloakRealm != "")
-		os.Exit(1)
-	}
-
-	keycloakClient := gocloak.NewClient(keycloakURL)
-	setupLog.Info("Initialized Keycloak client", "url", keycloakURL)
-
-	// Validate Keycloak credentials by attempting to login
-	setupLog.Info("Validating Keycloak credentials...")
+	// Initialize the default Keycloak connection, used by resources without a connectionRef
 	ctx := ctrl.SetupSignalHandler()
-	token, err := keycloakClient.LoginClient(ctx, keycloakUser, keycloakPass, keycloakRealm)
+	var defaultConnection *keycloak.Connection
+	keycloakConfig, configured, err := keycloak.ConfigFromEnv()
 	if err != nil {
-		setupLog.Error(err, "Failed to authenticate with Keycloak - invalid credentials or unreachable server",
-			"url", keycloakURL,
-			"user", keycloakUser)
+		setupLog.Error(err, "Keycloak configuration is invalid")
 		os.Exit(1)
 	}
-	setupLog.Info("Successfully authenticated with Keycloak", "realm", "master", "tokenType", token.TokenType)
+	if !configured {
+		setupLog.Info("No default Keycloak connection configured, resources must set a connectionRef")
+	} else {
+		keycloakConfig.Name = "default"
+		defaultConnection, err = keycloak.NewConnection(keycloakConfig)
+		if err != nil {
+			setupLog.Error(err, "Keycloak configuration is invalid")
+			os.Exit(1)
+		}
+		setupLog.Info("Initialized Keycloak client", "url", keycloakConfig.URL, "authMethod", defaultConnection.AuthMethod())
+
+		// Validate Keycloak credentials by attempting to login
+		setupLog.Info("Validating Keycloak credentials...")
+		if _, err := defaultConnection.Token(ctx); err != nil {
+			setupLog.Error(err, "Failed to authenticate with Keycloak - invalid credentials or unreachable server",
+				"url", keycloakConfig.URL,
+				"authMethod", defaultConnection.AuthMethod())
+			os.Exit(1)
+		}
+		setupLog.Info("Successfully authenticated with Keycloak", "realm", defaultConnection.Realm())
+	}
+
+	connections := &controller.ConnectionResolver{
+		Client:  mgr.GetClient(),
+		Default: defaultConnection,
+		Cache:   keycloak.NewConnectionCache(),
+	}
 
 	if err := (&controller.ClientReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		KeycloakClient: keycloakClient,
-		KeycloakURL:    keycloakURL,
-		KeycloakUser:   keycloakUser,
-		KeycloakPass:   keycloakPass,
-		KeycloakRealm:  keycloakRealm,
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("client-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Client")
 		os.Exit(1)
 	}
+	if err := (&controller.KeycloakConnectionReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Connections: connections,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KeycloakConnection")
+		os.Exit(1)
+	}
+	if err := (&controller.ClusterKeycloakConnectionReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Connections: connections,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterKeycloakConnection")
+		os.Exit(1)
+	}
+	if err := (&controller.RealmReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("realm-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Realm")
+		os.Exit(1)
+	}
+	if err := (&controller.ClientScopeReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("clientscope-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClientScope")
+		os.Exit(1)
+	}
+	if err := (&controller.RealmRoleReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("realmrole-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RealmRole")
+		os.Exit(1)
+	}
+	if err := (&controller.GroupReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("group-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Group")
+		os.Exit(1)
+	}
+	if err := (&controller.UserReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("user-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "User")
+		os.Exit(1)
+	}
+	if err := (&controller.IdentityProviderReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Recorder:              mgr.GetEventRecorder("identityprovider-controller"),
+		Connections:           connections,
+		DefaultDeletionPolicy: keycloakv1.DeletionPolicy(defaultDeletionPolicy),
+		ResyncInterval:        resyncInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "IdentityProvider")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
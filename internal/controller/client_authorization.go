@@ -0,0 +1,621 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// Time policy settings by the key Keycloak stores them under in the policy configuration.
+var timeConfigKeys = []struct {
+	key   string
+	value func(*keycloakv1.TimeCondition) string
+}{
+	{"nbf", func(t *keycloakv1.TimeCondition) string { return t.NotBefore }},
+	{"noa", func(t *keycloakv1.TimeCondition) string { return t.NotOnOrAfter }},
+	{"dayMonth", func(t *keycloakv1.TimeCondition) string { return t.DayMonth }},
+	{"dayMonthEnd", func(t *keycloakv1.TimeCondition) string { return t.DayMonthEnd }},
+	{"month", func(t *keycloakv1.TimeCondition) string { return t.Month }},
+	{"monthEnd", func(t *keycloakv1.TimeCondition) string { return t.MonthEnd }},
+	{"year", func(t *keycloakv1.TimeCondition) string { return t.Year }},
+	{"yearEnd", func(t *keycloakv1.TimeCondition) string { return t.YearEnd }},
+	{"hour", func(t *keycloakv1.TimeCondition) string { return t.Hour }},
+	{"hourEnd", func(t *keycloakv1.TimeCondition) string { return t.HourEnd }},
+	{"minute", func(t *keycloakv1.TimeCondition) string { return t.Minute }},
+	{"minuteEnd", func(t *keycloakv1.TimeCondition) string { return t.MinuteEnd }},
+}
+
+// namedChanges lists the objects, matched by name, to create, update and delete.
+type namedChanges[T any] struct {
+	create []T
+	update []T
+	delete []string
+}
+
+// empty reports whether the objects already match.
+func (c namedChanges[T]) empty() bool {
+	return len(c.create) == 0 && len(c.update) == 0 && len(c.delete) == 0
+}
+
+// planNamed matches the desired and live objects by name. Desired objects missing in Keycloak are created,
+// those that differ are updated and, when prune is set, live objects not desired are deleted. Declaring no
+// object leaves those in Keycloak alone.
+func planNamed[D, L any](desired []D, live []L, desiredName func(D) string, liveName func(L) string, differs func(D, L) bool, prune bool) namedChanges[D] {
+	var changes namedChanges[D]
+	if len(desired) == 0 {
+		return changes
+	}
+
+	liveByName := make(map[string]L, len(live))
+	for _, object := range live {
+		liveByName[liveName(object)] = object
+	}
+	desiredNames := make(map[string]bool, len(desired))
+	for _, object := range desired {
+		desiredNames[desiredName(object)] = true
+		liveObject, ok := liveByName[desiredName(object)]
+		switch {
+		case !ok:
+			changes.create = append(changes.create, object)
+		case differs(object, liveObject):
+			changes.update = append(changes.update, object)
+		}
+	}
+	if prune {
+		for _, object := range live {
+			if name := liveName(object); !desiredNames[name] {
+				changes.delete = append(changes.delete, name)
+			}
+		}
+	}
+	return changes
+}
+
+// authorizationPlan lists the changes that make the resource server of a client match spec.authorization.
+type authorizationPlan struct {
+	// settings holds the resource server settings to apply, nil when they match
+	settings    *gocloak.ResourceServerRepresentation
+	scopes      namedChanges[keycloakv1.AuthorizationScope]
+	resources   namedChanges[keycloakv1.AuthorizationResource]
+	policies    namedChanges[keycloakv1.AuthorizationPolicy]
+	permissions namedChanges[keycloakv1.AuthorizationPermission]
+}
+
+// drifted returns the JSON names of the spec fields the plan changes.
+func (p authorizationPlan) drifted() []string {
+	if p.settings != nil || !p.scopes.empty() || !p.resources.empty() || !p.policies.empty() || !p.permissions.empty() {
+		return []string{"authorization"}
+	}
+	return nil
+}
+
+// validateAuthorization refuses Authorization Services settings on a client without them.
+func validateAuthorization(kcClient *keycloakv1.Client) error {
+	if kcClient.Spec.Authorization != nil && !gocloak.PBool(kcClient.Spec.Client.AuthorizationServicesEnabled) {
+		return fmt.Errorf("authorization requires client.authorizationServicesEnabled")
+	}
+	return nil
+}
+
+// planAuthorization compares spec.authorization with the live resource server, as exported by Keycloak
+// with every reference by name. Fields left empty in the spec are not compared.
+func planAuthorization(spec *keycloakv1.Authorization, live *gocloak.ResourceServerRepresentation) authorizationPlan {
+	var plan authorizationPlan
+	if spec == nil {
+		return plan
+	}
+	if live == nil {
+		live = &gocloak.ResourceServerRepresentation{}
+	}
+	prune := spec.PrunePolicy == keycloakv1.PrunePolicyPrune
+
+	// Keycloak resets the settings missing in an update, so the live ones are sent along
+	settings := gocloak.ResourceServerRepresentation{
+		PolicyEnforcementMode:         live.PolicyEnforcementMode,
+		DecisionStrategy:              live.DecisionStrategy,
+		AllowRemoteResourceManagement: live.AllowRemoteResourceManagement,
+	}
+	changed := false
+	if spec.PolicyEnforcementMode != "" && spec.PolicyEnforcementMode != string(ptrValue(live.PolicyEnforcementMode)) {
+		settings.PolicyEnforcementMode = gocloak.PolicyEnforcementModeP(gocloak.PolicyEnforcementMode(spec.PolicyEnforcementMode))
+		changed = true
+	}
+	if spec.DecisionStrategy != "" && spec.DecisionStrategy != string(ptrValue(live.DecisionStrategy)) {
+		settings.DecisionStrategy = gocloak.DecisionStrategyP(gocloak.DecisionStrategy(spec.DecisionStrategy))
+		changed = true
+	}
+	if spec.AllowRemoteResourceManagement != nil && *spec.AllowRemoteResourceManagement != gocloak.PBool(live.AllowRemoteResourceManagement) {
+		settings.AllowRemoteResourceManagement = spec.AllowRemoteResourceManagement
+		changed = true
+	}
+	if changed {
+		plan.settings = &settings
+	}
+
+	plan.scopes = planNamed(spec.Scopes, ptrValue(live.Scopes),
+		func(s keycloakv1.AuthorizationScope) string { return s.Name },
+		func(s gocloak.ScopeRepresentation) string { return gocloak.PString(s.Name) },
+		func(s keycloakv1.AuthorizationScope, l gocloak.ScopeRepresentation) bool {
+			return len(diffFields(scopeRepresentation(s), l, "id")) > 0
+		}, prune)
+
+	plan.resources = planNamed(spec.Resources, ptrValue(live.Resources),
+		func(r keycloakv1.AuthorizationResource) string { return r.Name },
+		func(r gocloak.ResourceRepresentation) string { return gocloak.PString(r.Name) },
+		func(r keycloakv1.AuthorizationResource, l gocloak.ResourceRepresentation) bool {
+			if len(diffFields(resourceRepresentation(r), l, "_id", "scopes", "owner")) > 0 {
+				return true
+			}
+			var liveScopes []string
+			for _, scope := range ptrValue(l.Scopes) {
+				liveScopes = append(liveScopes, gocloak.PString(scope.Name))
+			}
+			return len(r.Scopes) > 0 && !sameElements(r.Scopes, liveScopes)
+		}, prune)
+
+	// Keycloak lists policies and permissions together, permissions being the resource and scope policies
+	var livePolicies, livePermissions []gocloak.PolicyRepresentation
+	for _, policy := range ptrValue(live.Policies) {
+		if isPermission(gocloak.PString(policy.Type)) {
+			livePermissions = append(livePermissions, policy)
+		} else {
+			livePolicies = append(livePolicies, policy)
+		}
+	}
+	livePolicyName := func(p gocloak.PolicyRepresentation) string { return gocloak.PString(p.Name) }
+
+	plan.policies = planNamed(spec.Policies, livePolicies,
+		func(p keycloakv1.AuthorizationPolicy) string { return p.Name }, livePolicyName,
+		func(p keycloakv1.AuthorizationPolicy, l gocloak.PolicyRepresentation) bool {
+			return policyDiffers(p.Description, p.Type, p.Logic, p.DecisionStrategy, policyConfig(p), l)
+		}, prune)
+
+	plan.permissions = planNamed(spec.Permissions, livePermissions,
+		func(p keycloakv1.AuthorizationPermission) string { return p.Name }, livePolicyName,
+		func(p keycloakv1.AuthorizationPermission, l gocloak.PolicyRepresentation) bool {
+			return policyDiffers(p.Description, p.Type, "", p.DecisionStrategy, permissionConfig(p), l)
+		}, prune)
+
+	return plan
+}
+
+// isPermission reports whether a policy type is the type of a permission.
+func isPermission(policyType string) bool {
+	return policyType == "resource" || policyType == "scope"
+}
+
+// policyDiffers reports whether a live policy or permission differs from the declared fields and configuration.
+func policyDiffers(description, policyType, logic, decisionStrategy string, config map[string]string, live gocloak.PolicyRepresentation) bool {
+	if description != "" && description != gocloak.PString(live.Description) ||
+		policyType != gocloak.PString(live.Type) ||
+		logic != "" && logic != string(ptrValue(live.Logic)) ||
+		decisionStrategy != "" && decisionStrategy != string(ptrValue(live.DecisionStrategy)) {
+		return true
+	}
+	liveConfig := ptrValue(live.Config)
+	for key, value := range config {
+		liveValue, ok := liveConfig[key]
+		if !ok || !jsonEqual(value, liveValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyConfig returns the configuration Keycloak exports for a declared policy, with references by name.
+func policyConfig(policy keycloakv1.AuthorizationPolicy) map[string]string {
+	config := map[string]string{}
+	switch policy.Type {
+	case "role":
+		roles := make([]map[string]any, 0, len(policy.Roles))
+		for _, role := range policy.Roles {
+			roles = append(roles, map[string]any{"id": role.Role, "required": role.Required})
+		}
+		config["roles"] = mustJSON(roles)
+	case "group":
+		groups := make([]map[string]any, 0, len(policy.Groups))
+		for _, group := range policy.Groups {
+			groups = append(groups, map[string]any{"path": group.Path, "extendChildren": group.ExtendChildren})
+		}
+		config["groups"] = mustJSON(groups)
+		if policy.GroupsClaim != "" {
+			config["groupsClaim"] = policy.GroupsClaim
+		}
+	case "client":
+		config["clients"] = mustJSON(policy.Clients)
+	case "user":
+		config["users"] = mustJSON(policy.Users)
+	case "time":
+		if policy.Time != nil {
+			for _, setting := range timeConfigKeys {
+				if value := setting.value(policy.Time); value != "" {
+					config[setting.key] = value
+				}
+			}
+		}
+	case "aggregate":
+		config["applyPolicies"] = mustJSON(policy.Policies)
+	}
+	return config
+}
+
+// permissionConfig returns the configuration Keycloak exports for a declared permission, with references by name.
+func permissionConfig(permission keycloakv1.AuthorizationPermission) map[string]string {
+	config := map[string]string{}
+	if len(permission.Resources) > 0 {
+		config["resources"] = mustJSON(permission.Resources)
+	}
+	if permission.ResourceType != "" {
+		config["defaultResourceType"] = permission.ResourceType
+	}
+	if len(permission.Scopes) > 0 {
+		config["scopes"] = mustJSON(permission.Scopes)
+	}
+	if len(permission.Policies) > 0 {
+		config["applyPolicies"] = mustJSON(permission.Policies)
+	}
+	return config
+}
+
+// jsonEqual reports whether two configuration values are equal, JSON arrays being compared regardless of order.
+func jsonEqual(desired, live string) bool {
+	var desiredValue, liveValue any
+	if json.Unmarshal([]byte(desired), &desiredValue) != nil || json.Unmarshal([]byte(live), &liveValue) != nil {
+		return desired == live
+	}
+	desiredItems, desiredIsArray := desiredValue.([]any)
+	liveItems, liveIsArray := liveValue.([]any)
+	if !desiredIsArray || !liveIsArray {
+		return reflect.DeepEqual(desiredValue, liveValue)
+	}
+	canonical := func(items []any) []string {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			values = append(values, mustJSON(item))
+		}
+		return values
+	}
+	return sameElements(canonical(desiredItems), canonical(liveItems))
+}
+
+// sameElements reports whether two string slices hold the same elements regardless of order.
+func sameElements(a, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+// mustJSON encodes a value that always encodes, maps being encoded with sorted keys.
+func mustJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// ptrValue returns the value of a GoCloak pointer field, or its zero value when unset.
+func ptrValue[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return *value
+}
+
+// optionalString returns a GoCloak string pointer, nil for an empty string.
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// scopeRepresentation converts a declared scope.
+func scopeRepresentation(scope keycloakv1.AuthorizationScope) gocloak.ScopeRepresentation {
+	return gocloak.ScopeRepresentation{
+		Name:        gocloak.StringP(scope.Name),
+		DisplayName: optionalString(scope.DisplayName),
+		IconURI:     optionalString(scope.IconURI),
+	}
+}
+
+// resourceRepresentation converts a declared resource, its scopes being referenced by name.
+func resourceRepresentation(resource keycloakv1.AuthorizationResource) gocloak.ResourceRepresentation {
+	rep := gocloak.ResourceRepresentation{
+		Name:               gocloak.StringP(resource.Name),
+		DisplayName:        optionalString(resource.DisplayName),
+		Type:               optionalString(resource.Type),
+		OwnerManagedAccess: resource.OwnerManagedAccess,
+	}
+	if len(resource.URIs) > 0 {
+		uris := slices.Clone(resource.URIs)
+		rep.URIs = &uris
+	}
+	if len(resource.Scopes) > 0 {
+		scopes := make([]gocloak.ScopeRepresentation, 0, len(resource.Scopes))
+		for _, name := range resource.Scopes {
+			scopes = append(scopes, gocloak.ScopeRepresentation{Name: gocloak.StringP(name)})
+		}
+		rep.Scopes = &scopes
+	}
+	if len(resource.Attributes) > 0 {
+		attributes := resource.Attributes
+		rep.Attributes = &attributes
+	}
+	return rep
+}
+
+// policyRepresentation converts a declared policy, Keycloak resolving its references by name.
+func policyRepresentation(policy keycloakv1.AuthorizationPolicy) gocloak.PolicyRepresentation {
+	rep := gocloak.PolicyRepresentation{
+		Name:        gocloak.StringP(policy.Name),
+		Description: optionalString(policy.Description),
+		Type:        gocloak.StringP(policy.Type),
+	}
+	if policy.Logic != "" {
+		rep.Logic = gocloak.LogicP(gocloak.Logic(policy.Logic))
+	}
+	if policy.DecisionStrategy != "" {
+		rep.DecisionStrategy = gocloak.DecisionStrategyP(gocloak.DecisionStrategy(policy.DecisionStrategy))
+	}
+	switch policy.Type {
+	case "role":
+		roles := make([]gocloak.RoleDefinition, 0, len(policy.Roles))
+		for _, role := range policy.Roles {
+			roles = append(roles, gocloak.RoleDefinition{ID: gocloak.StringP(role.Role), Required: gocloak.BoolP(role.Required)})
+		}
+		rep.Roles = &roles
+	case "group":
+		groups := make([]gocloak.GroupDefinition, 0, len(policy.Groups))
+		for _, group := range policy.Groups {
+			groups = append(groups, gocloak.GroupDefinition{Path: gocloak.StringP(group.Path), ExtendChildren: gocloak.BoolP(group.ExtendChildren)})
+		}
+		rep.Groups = &groups
+		rep.GroupsClaim = optionalString(policy.GroupsClaim)
+	case "client":
+		rep.Clients = &policy.Clients
+	case "user":
+		rep.Users = &policy.Users
+	case "time":
+		if t := policy.Time; t != nil {
+			rep.TimePolicyRepresentation = gocloak.TimePolicyRepresentation{
+				NotBefore:    optionalString(t.NotBefore),
+				NotOnOrAfter: optionalString(t.NotOnOrAfter),
+				DayMonth:     optionalString(t.DayMonth),
+				DayMonthEnd:  optionalString(t.DayMonthEnd),
+				Month:        optionalString(t.Month),
+				MonthEnd:     optionalString(t.MonthEnd),
+				Year:         optionalString(t.Year),
+				YearEnd:      optionalString(t.YearEnd),
+				Hour:         optionalString(t.Hour),
+				HourEnd:      optionalString(t.HourEnd),
+				Minute:       optionalString(t.Minute),
+				MinuteEnd:    optionalString(t.MinuteEnd),
+			}
+		}
+	case "aggregate":
+		rep.Policies = &policy.Policies
+	}
+	return rep
+}
+
+// permissionRepresentation converts a declared permission, Keycloak resolving its references by name.
+func permissionRepresentation(permission keycloakv1.AuthorizationPermission) gocloak.PermissionRepresentation {
+	rep := gocloak.PermissionRepresentation{
+		Name:         gocloak.StringP(permission.Name),
+		Description:  optionalString(permission.Description),
+		Type:         gocloak.StringP(permission.Type),
+		ResourceType: optionalString(permission.ResourceType),
+	}
+	if permission.DecisionStrategy != "" {
+		rep.DecisionStrategy = gocloak.DecisionStrategyP(gocloak.DecisionStrategy(permission.DecisionStrategy))
+	}
+	if len(permission.Resources) > 0 {
+		rep.Resources = &permission.Resources
+	}
+	if len(permission.Scopes) > 0 {
+		rep.Scopes = &permission.Scopes
+	}
+	if len(permission.Policies) > 0 {
+		rep.Policies = &permission.Policies
+	}
+	return rep
+}
+
+// fetchAuthorization returns the plan making the resource server of the Keycloak client idOfClient match the
+// spec. The plan is empty when the spec declares no authorization.
+func fetchAuthorization(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient string, spec *keycloakv1.Authorization) (authorizationPlan, error) {
+	if spec == nil {
+		return authorizationPlan{}, nil
+	}
+	live, err := gc.GetResourceServer(ctx, token, realm, idOfClient)
+	if err != nil {
+		return authorizationPlan{}, fmt.Errorf("failed to get authorization settings: %w", err)
+	}
+	return planAuthorization(spec, live), nil
+}
+
+// authorizationIDs holds the internal IDs of the objects of a resource server, by name.
+type authorizationIDs struct {
+	scopes    map[string]string
+	resources map[string]string
+	policies  map[string]string
+}
+
+// fetchAuthorizationIDs lists the objects of the resource server of the client idOfClient. The exported
+// settings the plan is based on carry no IDs.
+func fetchAuthorizationIDs(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient string) (authorizationIDs, error) {
+	unlimited := gocloak.IntP(-1)
+	ids := authorizationIDs{scopes: map[string]string{}, resources: map[string]string{}, policies: map[string]string{}}
+
+	scopes, err := gc.GetScopes(ctx, token, realm, idOfClient, gocloak.GetScopeParams{Max: unlimited})
+	if err != nil {
+		return ids, fmt.Errorf("failed to list authorization scopes: %w", err)
+	}
+	for _, scope := range scopes {
+		ids.scopes[gocloak.PString(scope.Name)] = gocloak.PString(scope.ID)
+	}
+	resources, err := gc.GetResources(ctx, token, realm, idOfClient, gocloak.GetResourceParams{Max: unlimited})
+	if err != nil {
+		return ids, fmt.Errorf("failed to list authorization resources: %w", err)
+	}
+	for _, resource := range resources {
+		ids.resources[gocloak.PString(resource.Name)] = gocloak.PString(resource.ID)
+	}
+	// Policies include permissions
+	policies, err := gc.GetPolicies(ctx, token, realm, idOfClient, gocloak.GetPolicyParams{Max: unlimited})
+	if err != nil {
+		return ids, fmt.Errorf("failed to list authorization policies: %w", err)
+	}
+	for _, policy := range policies {
+		ids.policies[gocloak.PString(policy.Name)] = gocloak.PString(policy.ID)
+	}
+	return ids, nil
+}
+
+// applyAuthorization applies plan to the resource server of the Keycloak client idOfClient. Objects are created
+// and updated in dependency order, scopes first and permissions last, and pruned in the reverse order.
+func applyAuthorization(ctx context.Context, conn *keycloak.Connection, token, realm, idOfClient string, plan authorizationPlan) error {
+	if len(plan.drifted()) == 0 {
+		return nil
+	}
+	gc := conn.Client
+
+	if plan.settings != nil {
+		if err := conn.UpdateResourceServer(ctx, token, realm, idOfClient, *plan.settings); err != nil {
+			return fmt.Errorf("failed to update resource server: %w", err)
+		}
+	}
+	if plan.scopes.empty() && plan.resources.empty() && plan.policies.empty() && plan.permissions.empty() {
+		return nil
+	}
+
+	ids, err := fetchAuthorizationIDs(ctx, gc, token, realm, idOfClient)
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range plan.scopes.create {
+		if _, err := gc.CreateScope(ctx, token, realm, idOfClient, scopeRepresentation(scope)); err != nil {
+			return fmt.Errorf("failed to create authorization scope %s: %w", scope.Name, err)
+		}
+	}
+	for _, scope := range plan.scopes.update {
+		rep := scopeRepresentation(scope)
+		rep.ID = gocloak.StringP(ids.scopes[scope.Name])
+		if err := gc.UpdateScope(ctx, token, realm, idOfClient, rep); err != nil {
+			return fmt.Errorf("failed to update authorization scope %s: %w", scope.Name, err)
+		}
+	}
+
+	for _, resource := range plan.resources.create {
+		if _, err := gc.CreateResource(ctx, token, realm, idOfClient, resourceRepresentation(resource)); err != nil {
+			return fmt.Errorf("failed to create authorization resource %s: %w", resource.Name, err)
+		}
+	}
+	for _, resource := range plan.resources.update {
+		rep := resourceRepresentation(resource)
+		rep.ID = gocloak.StringP(ids.resources[resource.Name])
+		if err := gc.UpdateResource(ctx, token, realm, idOfClient, rep); err != nil {
+			return fmt.Errorf("failed to update authorization resource %s: %w", resource.Name, err)
+		}
+	}
+
+	// Policies are applied in declaration order, so that aggregate policies follow the policies they reference
+	for _, policy := range plan.policies.create {
+		if _, err := gc.CreatePolicy(ctx, token, realm, idOfClient, policyRepresentation(policy)); err != nil {
+			return fmt.Errorf("failed to create authorization policy %s: %w", policy.Name, err)
+		}
+	}
+	for _, policy := range plan.policies.update {
+		rep := policyRepresentation(policy)
+		rep.ID = gocloak.StringP(ids.policies[policy.Name])
+		if err := gc.UpdatePolicy(ctx, token, realm, idOfClient, rep); err != nil {
+			return fmt.Errorf("failed to update authorization policy %s: %w", policy.Name, err)
+		}
+	}
+
+	for _, permission := range plan.permissions.create {
+		if _, err := gc.CreatePermission(ctx, token, realm, idOfClient, permissionRepresentation(permission)); err != nil {
+			return fmt.Errorf("failed to create authorization permission %s: %w", permission.Name, err)
+		}
+	}
+	for _, permission := range plan.permissions.update {
+		rep := permissionRepresentation(permission)
+		rep.ID = gocloak.StringP(ids.policies[permission.Name])
+		if err := gc.UpdatePermission(ctx, token, realm, idOfClient, rep); err != nil {
+			return fmt.Errorf("failed to update authorization permission %s: %w", permission.Name, err)
+		}
+	}
+
+	deletions := []struct {
+		names  []string
+		ids    map[string]string
+		kind   string
+		delete func(ctx context.Context, token, realm, idOfClient, id string) error
+	}{
+		{plan.permissions.delete, ids.policies, "permission", gc.DeletePermission},
+		{plan.policies.delete, ids.policies, "policy", gc.DeletePolicy},
+		{plan.resources.delete, ids.resources, "resource", gc.DeleteResource},
+		{plan.scopes.delete, ids.scopes, "scope", gc.DeleteScope},
+	}
+	for _, deletion := range deletions {
+		for _, name := range deletion.names {
+			id, ok := deletion.ids[name]
+			if !ok {
+				// Deleted along with another object
+				continue
+			}
+			if err := deletion.delete(ctx, token, realm, idOfClient, id); err != nil && !keycloak.IsNotFound(err) {
+				return fmt.Errorf("failed to delete authorization %s %s: %w", deletion.kind, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// planClientAuthorization fetches the resource server of the Keycloak client idOfClient and plans its changes,
+// reporting failures in the Ready condition.
+func (r *ClientReconciler) planClientAuthorization(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, idOfClient string) (authorizationPlan, error) {
+	plan, err := fetchAuthorization(ctx, gc, token, *kcClient.Spec.Realm, idOfClient, kcClient.Spec.Authorization)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to get authorization settings from Keycloak")
+		setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, "AuthorizationFailed", err.Error())
+	}
+	return plan, err
+}
+
+// syncAuthorization applies plan to the resource server of the Keycloak client idOfClient and reports
+// failures in the Ready condition.
+func (r *ClientReconciler) syncAuthorization(ctx context.Context, conn *keycloak.Connection, token string, kcClient *keycloakv1.Client, idOfClient string, plan authorizationPlan) error {
+	if err := applyAuthorization(ctx, conn, token, *kcClient.Spec.Realm, idOfClient, plan); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update authorization settings in Keycloak")
+		setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, "AuthorizationFailed", err.Error())
+		return err
+	}
+	return nil
+}
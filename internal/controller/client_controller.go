@@ -17,8 +17,15 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	gocloak "github.com/Nerzal/gocloak/v13"
 	corev1 "k8s.io/api/core/v1"
@@ -26,12 +33,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
 )
 
 const clientFinalizer = "keycloak.pewty.fr/finalizer"
@@ -39,18 +49,23 @@ const clientFinalizer = "keycloak.pewty.fr/finalizer"
 // ClientReconciler reconciles a Client object
 type ClientReconciler struct {
 	client.Client
-	Scheme         *runtime.Scheme
-	KeycloakClient *gocloak.GoCloak
-	KeycloakURL    string
-	KeycloakUser   string
-	KeycloakPass   string
-	KeycloakRealm  string
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to clients whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often clients are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
 }
 
 // +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clients,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clients/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clients/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=keycloakconnections;clusterkeycloakconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,8 +91,16 @@ func (r *ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	// Take the realm of the referenced Realm resource, only in memory
+	realmReady, err := r.resolveRealmRef(ctx, &kcClient)
+	if err != nil {
+		logger.Error(err, "Failed to resolve realmRef")
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "InvalidRealm", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	// Validate required fields
-	if kcClient.Spec.Realm == nil {
+	if kcClient.Spec.Realm == nil && kcClient.Spec.RealmRef == nil {
 		err := fmt.Errorf("realm is required")
 		logger.Error(err, "Invalid Client spec")
 		return ctrl.Result{}, err
@@ -88,153 +111,444 @@ func (r *ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	// Get client credentials from referenced secret
-	clientID, clientSecret, err := r.getClientCredentials(ctx, &kcClient)
-	if err != nil {
-		logger.Error(err, "Failed to get client credentials from secret")
-		r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "SecretReadFailed", fmt.Sprintf("Failed to read secret: %v", err))
-		return ctrl.Result{}, err
-	}
-
-	// Authenticate with Keycloak
-	token, err := r.KeycloakClient.LoginClient(ctx, r.KeycloakUser, r.KeycloakPass, r.KeycloakRealm)
-	if err != nil {
-		logger.Error(err, "Failed to authenticate with Keycloak")
-		r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "AuthenticationFailed", fmt.Sprintf("Failed to authenticate: %v", err))
-		return ctrl.Result{}, err
-	}
-
 	// 2. Handle deletion logic with finalizer
 	if !kcClient.DeletionTimestamp.IsZero() {
-		if controllerutil.ContainsFinalizer(&kcClient, clientFinalizer) {
-			// Resource is being deleted, perform cleanup
-			// Get clientID from secret before deletion
-			deleteClientID, _, err := r.getClientCredentials(ctx, &kcClient)
-			if err != nil {
-				logger.Error(err, "Failed to get client credentials for deletion, skipping Keycloak cleanup")
-				// Continue with finalizer removal even if we can't read the secret
-			} else {
-				if err := r.deleteClientInKeycloak(ctx, r.KeycloakClient, token.AccessToken, &kcClient, deleteClientID); err != nil {
-					logger.Error(err, "Failed to delete client in Keycloak")
-					r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "DeletionFailed", fmt.Sprintf("Failed to delete: %v", err))
-					return ctrl.Result{}, err
-				}
-			}
-
-			// Remove finalizer to allow deletion
-			controllerutil.RemoveFinalizer(&kcClient, clientFinalizer)
-			if err := r.Update(ctx, &kcClient); err != nil {
-				logger.Error(err, "Failed to remove finalizer")
-				return ctrl.Result{}, err
-			}
-		}
-		return ctrl.Result{}, nil
+		return r.reconcileDelete(ctx, &kcClient)
 	}
 
 	// 3. Add finalizer if not present
 	if !controllerutil.ContainsFinalizer(&kcClient, clientFinalizer) {
+		// Patch the finalizers only, the realm resolved above must not be written to the spec
+		patch := client.MergeFrom(kcClient.DeepCopy())
 		controllerutil.AddFinalizer(&kcClient, clientFinalizer)
-		if err := r.Update(ctx, &kcClient); err != nil {
+		if err := r.Patch(ctx, &kcClient, patch); err != nil {
 			logger.Error(err, "Failed to add finalizer")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Wait for the referenced Realm, its changes trigger a new reconciliation
+	if !realmReady {
+		message := fmt.Sprintf("Waiting for Realm %s to be Ready", kcClient.Spec.RealmRef.Name)
+		logger.Info("Realm is not ready, waiting", "realmRef", kcClient.Spec.RealmRef.Name)
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "RealmNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	// Wait for the ClientScope resources declaring the client scopes of the client, their changes trigger a
+	// new reconciliation
+	pendingScopes, err := r.pendingClientScopes(ctx, &kcClient)
+	if err != nil {
+		logger.Error(err, "Failed to check client scopes")
+		return ctrl.Result{}, err
+	}
+	if len(pendingScopes) > 0 {
+		message := fmt.Sprintf("Waiting for ClientScope %s to be Ready", strings.Join(pendingScopes, ", "))
+		logger.Info("Client scopes are not ready, waiting", "clientScopes", pendingScopes)
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "ClientScopeNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	// Refuse templates that cannot render before changing anything in Keycloak
+	if err := validateSecretTemplate(&kcClient); err != nil {
+		logger.Error(err, "Invalid secret template")
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "InvalidSecretTemplate", err.Error())
+		return ctrl.Result{}, err
+	}
+	if err := validateServiceAccount(&kcClient); err != nil {
+		logger.Error(err, "Invalid service account")
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "InvalidServiceAccount", err.Error())
+		return ctrl.Result{}, err
+	}
+	if err := validateAuthorization(&kcClient); err != nil {
+		logger.Error(err, "Invalid authorization settings")
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "InvalidAuthorization", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// Get client credentials from referenced secret
+	clientID, clientSecret, err := r.getClientCredentials(ctx, &kcClient)
+	if err != nil {
+		logger.Error(err, "Failed to get client credentials from secret")
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "SecretReadFailed", fmt.Sprintf("Failed to read secret: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	// Resolve the Keycloak connection and get an admin token
+	conn, token, err := connect(ctx, r.Client, r.Connections, &kcClient, kcClient.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gc := conn.Client
+
 	// 4. Check if client exists in Keycloak
-	clients, err := r.KeycloakClient.GetClients(ctx, token.AccessToken, *kcClient.Spec.Realm, gocloak.GetClientsParams{
-		ClientID: &clientID,
-	})
+	existingClient, err := r.findClient(ctx, gc, token, &kcClient, clientID)
 	if err != nil {
 		logger.Error(err, "Failed to query Keycloak clients")
-		r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query clients: %v", err))
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query clients: %v", err))
 		return ctrl.Result{}, err
 	}
 
-	if len(clients) == 0 {
+	if existingClient == nil {
 		// 5. Client doesn't exist, create it
 		logger.Info("Creating client in Keycloak", "clientID", clientID)
 
-		newClient := r.convertToGoCloak(&kcClient.Spec.Client, clientID, clientSecret)
-		clientID, err := r.KeycloakClient.CreateClient(ctx, token.AccessToken, *kcClient.Spec.Realm, newClient)
+		newClient := r.desiredClient(&kcClient, clientID, clientSecret)
+		id, err := gc.CreateClient(ctx, token, *kcClient.Spec.Realm, newClient)
 		if err != nil {
 			logger.Error(err, "Failed to create client in Keycloak")
-			r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
 			return ctrl.Result{}, err
 		}
+		kcClient.Status.ID = id
+		// A rotation requested before the client existed is fulfilled by its first secret
+		kcClient.Status.SecretRotationRequest = kcClient.Annotations[keycloakv1.RotateSecretAnnotation]
 
-		logger.Info("Successfully created client in Keycloak", "clientID", clientID, "id", clientID)
+		logger.Info("Successfully created client in Keycloak", "clientID", clientID, "id", id)
 
 		// Get the created client to retrieve generated secret
-		createdClient, err := r.KeycloakClient.GetClient(ctx, token.AccessToken, *kcClient.Spec.Realm, clientID)
+		createdClient, err := gc.GetClient(ctx, token, *kcClient.Spec.Realm, id)
 		if err != nil {
 			logger.Error(err, "Failed to get created client details")
-			r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Client created but failed to retrieve: %v", err))
+			setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Client created but failed to retrieve: %v", err))
+			return ctrl.Result{}, err
+		}
+
+		// Keycloak creates the protocol mappers and assigns the client scopes sent with a new client, make sure none was left out
+		mapperPlan := planProtocolMappers(protocolMappers(&newClient), protocolMappers(createdClient), clientProtocol(createdClient))
+		if err := applyProtocolMappers(ctx, gc, token, *kcClient.Spec.Realm, id, mapperPlan); err != nil {
+			logger.Error(err, "Failed to create protocol mappers in Keycloak")
+			setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "ProtocolMappersFailed", fmt.Sprintf("Failed to create protocol mappers: %v", err))
+			return ctrl.Result{}, err
+		}
+		if err := r.syncClientScopes(ctx, gc, token, &kcClient, id, planClientScopes(newClient, *createdClient)); err != nil {
+			return ctrl.Result{}, err
+		}
+		rolesPlan, err := r.planRoles(ctx, gc, token, &kcClient, id, clientID)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncClientRoles(ctx, gc, token, &kcClient, id, rolesPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		authzPlan, err := r.planClientAuthorization(ctx, gc, token, &kcClient, id)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncAuthorization(ctx, conn, token, &kcClient, id, authzPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		accountPlan, err := r.planServiceAccount(ctx, gc, token, &kcClient, id)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncServiceAccountRoles(ctx, gc, token, &kcClient, accountPlan); err != nil {
 			return ctrl.Result{}, err
 		}
 
 		// Update secret with credentials
-		if err := r.updateSecretWithCredentials(ctx, &kcClient, createdClient.ClientID, createdClient.Secret); err != nil {
+		if err := r.updateSecretWithCredentials(ctx, &kcClient, conn, createdClient.ClientID, createdClient.Secret); err != nil {
 			logger.Error(err, "Failed to update secret with credentials")
-			r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "SecretUpdateFailed", fmt.Sprintf("Failed to update secret: %v", err))
+			setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "SecretUpdateFailed", fmt.Sprintf("Failed to update secret: %v", err))
 			return ctrl.Result{}, err
 		}
 
-		r.updateStatus(ctx, &kcClient, metav1.ConditionTrue, "Created", "Client successfully created in Keycloak")
+		markSynced(&kcClient, conn, newClient)
+		setReady(ctx, r.Client, &kcClient, metav1.ConditionTrue, "Created", "Client successfully created in Keycloak")
 	} else {
-		// 6. Client exists, update it
-		logger.Info("Updating client in Keycloak", "clientID", clientID)
+		// 6. Client exists, update it if it differs from the desired state
+		adopting, ok := claimObject(ctx, r.Client, r.Recorder, &kcClient, kcClient.Spec.AdoptionPolicy,
+			clientAttributes(existingClient), "Keycloak client "+clientID)
+		if !ok {
+			return ctrl.Result{RequeueAfter: r.resyncInterval(&kcClient)}, nil
+		}
+		kcClient.Status.ID = gocloak.PString(existingClient.ID)
 
-		existingClient := clients[0]
-		updatedClient := r.convertToGoCloak(&kcClient.Spec.Client, clientID, clientSecret)
+		// The secret Keycloak generated for a pending rotation is not reverted, the rotation completes below
+		desiredSecret := clientSecret
+		if kcClient.Status.PendingSecretRotationTime != nil {
+			desiredSecret = ""
+		}
+		updatedClient := r.desiredClient(&kcClient, clientID, desiredSecret)
+		// Keycloak applies any secret it is sent, an empty one would blank the live secret
+		if desiredSecret == "" {
+			updatedClient.Secret = nil
+		}
 
 		// Preserve the internal ID from the existing client
 		updatedClient.ID = existingClient.ID
 
-		err := r.KeycloakClient.UpdateClient(ctx, token.AccessToken, *kcClient.Spec.Realm, updatedClient)
+		// Protocol mappers, client scopes and roles are compared separately, they are not changed by client updates
+		protocol := clientProtocol(existingClient)
+		if updatedClient.Protocol != nil {
+			protocol = *updatedClient.Protocol
+		}
+		mapperPlan := planProtocolMappers(protocolMappers(&updatedClient), protocolMappers(existingClient), protocol)
+		scopePlan := planClientScopes(updatedClient, *existingClient)
+		rolesPlan, err := r.planRoles(ctx, gc, token, &kcClient, *existingClient.ID, clientID)
 		if err != nil {
-			logger.Error(err, "Failed to update client in Keycloak")
-			r.updateStatus(ctx, &kcClient, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
 			return ctrl.Result{}, err
 		}
+		// The resource server and the service account user only exist once enabled, they are planned after the update otherwise
+		var authzPlan authorizationPlan
+		authorizationEnabled := gocloak.PBool(existingClient.AuthorizationServicesEnabled)
+		if authorizationEnabled {
+			if authzPlan, err = r.planClientAuthorization(ctx, gc, token, &kcClient, *existingClient.ID); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		var accountPlan serviceAccountRolePlan
+		serviceAccountEnabled := gocloak.PBool(existingClient.ServiceAccountsEnabled)
+		if serviceAccountEnabled {
+			if accountPlan, err = r.planServiceAccount(ctx, gc, token, &kcClient, *existingClient.ID); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		clientDrifted := diffClient(updatedClient, *existingClient)
+		drifted := slices.Clone(clientDrifted)
+		if !mapperPlan.empty() {
+			drifted = append(drifted, "protocolMappers")
+		}
+		drifted = append(drifted, scopePlan.drifted()...)
+		drifted = append(drifted, rolesPlan.drifted()...)
+		drifted = append(drifted, authzPlan.drifted()...)
+		drifted = append(drifted, accountPlan.drifted()...)
+		// A new clientId in the Secret renames the client, while a client renamed in Keycloak drifted
+		renaming := slices.Contains(drifted, "clientId") && clientID != kcClient.Status.ClientID
+		if renaming {
+			logger.Info("Renaming client in Keycloak", "from", gocloak.PString(existingClient.ClientID), "to", clientID)
+		}
+
+		// Differences on a resource already applied at this generation were made in Keycloak directly
+		if len(drifted) > 0 && !adopting && !renaming && isSynced(kcClient.Status.Conditions, kcClient.Generation) {
+			if !recordDrift(ctx, r.Recorder, &kcClient, &kcClient.Status.Conditions, driftPolicy(&kcClient), drifted) {
+				if err := writeStatus(ctx, r.Client, &kcClient); err != nil {
+					logger.Error(err, "Failed to update Client status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: r.resyncInterval(&kcClient)}, nil
+			}
+		} else {
+			clearDrift(&kcClient.Status.Conditions, kcClient.Generation)
+		}
 
-		// Update secret with current credentials (in case secret was regenerated)
-		if updatedClient.Secret != nil {
-			if err := r.updateSecretWithCredentials(ctx, &kcClient, updatedClient.ClientID, updatedClient.Secret); err != nil {
-				logger.Error(err, "Failed to update secret with credentials")
-				// Don't fail the reconciliation for secret update failures
+		if len(clientDrifted) > 0 {
+			logger.Info("Updating client in Keycloak", "clientID", clientID, "fields", clientDrifted)
+			err := gc.UpdateClient(ctx, token, *kcClient.Spec.Realm, updatedClient)
+			if err != nil {
+				logger.Error(err, "Failed to update client in Keycloak")
+				setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+				return ctrl.Result{}, err
 			}
+			logger.Info("Successfully updated client in Keycloak", "clientID", clientID)
 		}
 
-		logger.Info("Successfully updated client in Keycloak", "clientID", clientID)
-		r.updateStatus(ctx, &kcClient, metav1.ConditionTrue, "Updated", "Client successfully updated in Keycloak")
+		if !mapperPlan.empty() {
+			logger.Info("Updating protocol mappers in Keycloak", "clientID", clientID,
+				"create", len(mapperPlan.create), "update", len(mapperPlan.update), "delete", len(mapperPlan.delete))
+			if err := applyProtocolMappers(ctx, gc, token, *kcClient.Spec.Realm, *existingClient.ID, mapperPlan); err != nil {
+				logger.Error(err, "Failed to update protocol mappers in Keycloak")
+				setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "ProtocolMappersFailed", fmt.Sprintf("Failed to update protocol mappers: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+
+		if err := r.syncClientScopes(ctx, gc, token, &kcClient, *existingClient.ID, scopePlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncClientRoles(ctx, gc, token, &kcClient, *existingClient.ID, rolesPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		if !authorizationEnabled {
+			if authzPlan, err = r.planClientAuthorization(ctx, gc, token, &kcClient, *existingClient.ID); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.syncAuthorization(ctx, conn, token, &kcClient, *existingClient.ID, authzPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		if !serviceAccountEnabled {
+			if accountPlan, err = r.planServiceAccount(ctx, gc, token, &kcClient, *existingClient.ID); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.syncServiceAccountRoles(ctx, gc, token, &kcClient, accountPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Keep the secret in sync with the credentials in use, Keycloak generates the client secret when none is set
+		currentSecret := updatedClient.Secret
+		if desiredSecret == "" {
+			currentSecret = existingClient.Secret
+		}
+
+		// Rotate the secret when scheduled or requested, public clients have none. The rotation is recorded
+		// first, so that failing to write the new secret does not rotate it again.
+		now := time.Now()
+		reason := secretRotationDue(&kcClient, now)
+		pending := kcClient.Status.PendingSecretRotationTime
+		if (reason != "" || pending != nil) && !gocloak.PBool(updatedClient.PublicClient) {
+			if pending == nil {
+				pending = &metav1.Time{Time: now}
+				kcClient.Status.PendingSecretRotationTime = pending
+				if err := r.Status().Update(ctx, &kcClient); err != nil {
+					logger.Error(err, "Failed to record the secret rotation")
+					return ctrl.Result{}, err
+				}
+			} else if reason == "" {
+				reason = "resumed"
+			}
+			logger.Info("Rotating client secret", "clientID", clientID, "reason", reason)
+			previous := clientSecret
+			if previous == "" {
+				previous = gocloak.PString(existingClient.Secret)
+			}
+			rotated, err := r.rotateSecret(ctx, gc, token, &kcClient, *existingClient.ID, previous, pending.Time)
+			if err != nil {
+				logger.Error(err, "Failed to rotate client secret")
+				setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "SecretRotationFailed", fmt.Sprintf("Failed to rotate secret: %v", err))
+				return ctrl.Result{}, err
+			}
+
+			if err := r.writeRotatedSecret(ctx, &kcClient, conn, clientID, rotated, previous); err != nil {
+				logger.Error(err, "Failed to update secret with rotated credentials")
+				setReady(ctx, r.Client, &kcClient, metav1.ConditionFalse, "SecretUpdateFailed", fmt.Sprintf("Failed to update secret: %v", err))
+				return ctrl.Result{}, err
+			}
+
+			kcClient.Status.LastSecretRotationTime = pending
+			kcClient.Status.PendingSecretRotationTime = nil
+			kcClient.Status.SecretRotationRequest = kcClient.Annotations[keycloakv1.RotateSecretAnnotation]
+			r.Recorder.Eventf(&kcClient, nil, corev1.EventTypeNormal, "SecretRotated", "Rotate",
+				"Client secret rotated (%s)", reason)
+			currentSecret = &rotated
+		}
+		if err := r.updateSecretWithCredentials(ctx, &kcClient, conn, updatedClient.ClientID, currentSecret); err != nil {
+			logger.Error(err, "Failed to update secret with credentials")
+			// Don't fail the reconciliation for secret update failures
+		}
+
+		markSynced(&kcClient, conn, updatedClient)
+		if len(drifted) > 0 {
+			setReady(ctx, r.Client, &kcClient, metav1.ConditionTrue, "Updated", "Client successfully updated in Keycloak")
+		} else {
+			setReady(ctx, r.Client, &kcClient, metav1.ConditionTrue, "UpToDate", "Client is up to date in Keycloak")
+		}
+	}
+
+	// 7. Export the installation documents of the client once it is in sync
+	changed, exportErr := r.reconcileExports(ctx, conn, token, &kcClient)
+	if errors.Is(exportErr, errExportNotOwned) {
+		// Retrying does not help until the object is removed or the export renamed
+		logger.Info("Refusing to overwrite export object", "name", kcClient.Spec.Exports.Name)
+		r.Recorder.Eventf(&kcClient, nil, corev1.EventTypeWarning, "Conflict", "Export", exportErr.Error())
+		exportErr = nil
+	} else if exportErr != nil {
+		logger.Error(exportErr, "Failed to export installation documents")
+		r.Recorder.Eventf(&kcClient, nil, corev1.EventTypeWarning, "ExportFailed", "Export", exportErr.Error())
+	}
+	if changed {
+		if err := r.Status().Update(ctx, &kcClient); err != nil {
+			logger.Error(err, "Failed to update Client status")
+			return ctrl.Result{}, err
+		}
+	}
+	if exportErr != nil {
+		return ctrl.Result{}, exportErr
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.requeueAfter(&kcClient)}, nil
 }
 
-// deleteClientInKeycloak deletes a client from Keycloak if it exists
-func (r *ClientReconciler) deleteClientInKeycloak(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, clientID string) error {
+// reconcileDelete removes the client from Keycloak and releases the finalizer
+func (r *ClientReconciler) reconcileDelete(ctx context.Context, kcClient *keycloakv1.Client) (ctrl.Result, error) {
+	// Without a realm, the referenced Realm is gone and the client was never synced: there is nothing to clean up
+	var cleanup func() error
+	if kcClient.Spec.Realm != nil {
+		cleanup = func() error { return r.cleanupKeycloak(ctx, kcClient) }
+	}
+	return finalize(ctx, r.Client, r.Recorder, kcClient, clientFinalizer, kcClient.Spec.DeletionPolicy,
+		r.DefaultDeletionPolicy, "Keycloak client "+cmp.Or(kcClient.Status.ClientID, kcClient.Name), cleanup)
+}
+
+// cleanupKeycloak deletes the client from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *ClientReconciler) cleanupKeycloak(ctx context.Context, kcClient *keycloakv1.Client) error {
+	logger := logf.FromContext(ctx)
+
+	// Get clientID from secret before deletion, it is only needed when the internal ID is unknown
+	deleteClientID, _, err := r.getClientCredentials(ctx, kcClient)
+	if err != nil {
+		if kcClient.Status.ID == "" {
+			logger.Error(err, "Failed to get client credentials for deletion, skipping Keycloak cleanup")
+			// Continue with finalizer removal even if we can't read the secret
+			return nil
+		}
+		deleteClientID = ""
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, kcClient, kcClient.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	gc := conn.Client
+	return deleteOwned(ctx, r.Client, kcClient, "Keycloak client "+cmp.Or(deleteClientID, kcClient.Status.ClientID),
+		func() (*gocloak.Client, error) { return r.findClient(ctx, gc, token, kcClient, deleteClientID) },
+		clientAttributes,
+		func(existing *gocloak.Client) error {
+			return gc.DeleteClient(ctx, token, *kcClient.Spec.Realm, gocloak.PString(existing.ID))
+		})
+}
+
+// resolveRealmRef sets the realm of a client referencing a Realm resource to the realm of that Realm, and
+// reports whether the Realm is Ready. Clients without a realmRef are always ready.
+func (r *ClientReconciler) resolveRealmRef(ctx context.Context, kcClient *keycloakv1.Client) (bool, error) {
+	return resolveRealm(ctx, r, kcClient, kcClient.Spec.RealmRef, &kcClient.Spec.Realm, kcClient.Status.Realm)
+}
+
+// findClient looks up the Keycloak client of kcClient. The internal ID recorded in the status is
+// preferred, so that a clientId change renames the client instead of creating a new one, and the
+// clientId is used until it is known. It returns nil when the client or its realm does not exist.
+func (r *ClientReconciler) findClient(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, clientID string) (*gocloak.Client, error) {
 	logger := logf.FromContext(ctx)
 
+	// The recorded ID is meaningless once the client moves to another realm
+	if kcClient.Status.ID != "" && (kcClient.Status.Realm == "" || kcClient.Status.Realm == *kcClient.Spec.Realm) {
+		existingClient, err := gc.GetClient(ctx, token, *kcClient.Spec.Realm, kcClient.Status.ID)
+		if err == nil {
+			return existingClient, nil
+		}
+		if !keycloak.IsNotFound(err) {
+			return nil, err
+		}
+		logger.Info("Client no longer exists in Keycloak, looking it up by clientId", "id", kcClient.Status.ID, "clientID", clientID)
+	}
+
+	if clientID == "" {
+		return nil, nil
+	}
 	clients, err := gc.GetClients(ctx, token, *kcClient.Spec.Realm, gocloak.GetClientsParams{
 		ClientID: &clientID,
 	})
+	if keycloak.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to query client: %w", err)
+		return nil, err
 	}
-
-	if len(clients) > 0 {
-		err := gc.DeleteClient(ctx, token, *kcClient.Spec.Realm, *clients[0].ID)
-		if err != nil {
-			return fmt.Errorf("failed to delete client: %w", err)
-		}
-		logger.Info("Successfully deleted client from Keycloak", "clientID", clientID)
-	} else {
-		logger.Info("Client not found in Keycloak, nothing to delete", "clientID", clientID)
+	if len(clients) == 0 {
+		return nil, nil
 	}
+	return clients[0], nil
+}
 
-	return nil
+// desiredClient returns the Keycloak client described by the spec, marked as managed by kcClient
+func (r *ClientReconciler) desiredClient(kcClient *keycloakv1.Client, clientID string, clientSecret string) gocloak.Client {
+	desired := r.convertToGoCloak(&kcClient.Spec.Client, clientID, clientSecret)
+	attributes := withOwner(kcClient.Spec.Client.Attributes, kcClient)
+	desired.Attributes = &attributes
+	return desired
 }
 
 // convertToGoCloak converts the CRD ClientRepresentation to gocloak.Client
@@ -294,30 +608,19 @@ func (r *ClientReconciler) convertToGoCloak(clientRep *keycloakv1.ClientRepresen
 	}
 
 	// Convert protocol mappers
-	if len(clientRep.ProtocolMappers) > 0 {
-		protocolMappers := make([]gocloak.ProtocolMapperRepresentation, len(clientRep.ProtocolMappers))
-		for i, pm := range clientRep.ProtocolMappers {
-			protocolMappers[i] = gocloak.ProtocolMapperRepresentation{
-				ID:             pm.ID,
-				Name:           pm.Name,
-				Protocol:       pm.Protocol,
-				ProtocolMapper: pm.ProtocolMapper,
-				Config:         &pm.Config,
-			}
-		}
+	if clientRep.ProtocolMappers != nil {
+		protocolMappers := toProtocolMappers(clientRep.ProtocolMappers)
 		gc.ProtocolMappers = &protocolMappers
 	}
 
 	return gc
 }
 
-// getClientCredentials reads clientID and clientSecret from the referenced Kubernetes Secret
-func (r *ClientReconciler) getClientCredentials(ctx context.Context, kcClient *keycloakv1.Client) (string, string, error) {
-	logger := logf.FromContext(ctx)
-
+// credentialKeys returns the keys holding the client ID and secret in the referenced Secret
+func credentialKeys(kcClient *keycloakv1.Client) (clientIDKey string, clientSecretKey string) {
 	// Default keys
-	clientIDKey := "clientId"
-	clientSecretKey := "clientSecret"
+	clientIDKey = "clientId"
+	clientSecretKey = "clientSecret"
 
 	if kcClient.Spec.SecretRef.ClientIDKey != "" {
 		clientIDKey = kcClient.Spec.SecretRef.ClientIDKey
@@ -325,6 +628,16 @@ func (r *ClientReconciler) getClientCredentials(ctx context.Context, kcClient *k
 	if kcClient.Spec.SecretRef.ClientSecretKey != "" {
 		clientSecretKey = kcClient.Spec.SecretRef.ClientSecretKey
 	}
+	return clientIDKey, clientSecretKey
+}
+
+// getClientCredentials reads clientID and clientSecret from the referenced Kubernetes Secret.
+// The clientId set in the spec takes precedence, and the secret may then not exist yet.
+func (r *ClientReconciler) getClientCredentials(ctx context.Context, kcClient *keycloakv1.Client) (string, string, error) {
+	logger := logf.FromContext(ctx)
+
+	clientIDKey, clientSecretKey := credentialKeys(kcClient)
+	specClientID := gocloak.PString(kcClient.Spec.Client.ClientID)
 
 	// Get the secret
 	secret := &corev1.Secret{}
@@ -335,17 +648,24 @@ func (r *ClientReconciler) getClientCredentials(ctx context.Context, kcClient *k
 
 	if err := r.Get(ctx, secretName, secret); err != nil {
 		if apierrors.IsNotFound(err) {
+			if specClientID != "" {
+				logger.Info("Secret not found, it will be created with the credentials generated by Keycloak", "secret", kcClient.Spec.SecretRef.Name)
+				return specClientID, "", nil
+			}
 			return "", "", fmt.Errorf("secret %s not found in namespace %s", kcClient.Spec.SecretRef.Name, kcClient.Namespace)
 		}
 		return "", "", fmt.Errorf("failed to get secret: %w", err)
 	}
 
 	// Read clientID
-	clientIDBytes, ok := secret.Data[clientIDKey]
-	if !ok || len(clientIDBytes) == 0 {
-		return "", "", fmt.Errorf("key %s not found in secret %s", clientIDKey, kcClient.Spec.SecretRef.Name)
+	clientID := specClientID
+	if clientID == "" {
+		clientIDBytes, ok := secret.Data[clientIDKey]
+		if !ok || len(clientIDBytes) == 0 {
+			return "", "", fmt.Errorf("key %s not found in secret %s", clientIDKey, kcClient.Spec.SecretRef.Name)
+		}
+		clientID = string(clientIDBytes)
 	}
-	clientID := string(clientIDBytes)
 
 	// Read clientSecret
 	clientSecretBytes, ok := secret.Data[clientSecretKey]
@@ -358,8 +678,10 @@ func (r *ClientReconciler) getClientCredentials(ctx context.Context, kcClient *k
 	return clientID, clientSecret, nil
 }
 
-// updateSecretWithCredentials updates the referenced Kubernetes Secret with client credentials
-func (r *ClientReconciler) updateSecretWithCredentials(ctx context.Context, kcClient *keycloakv1.Client, clientID *string, clientSecret *string) error {
+// updateSecretWithCredentials updates the referenced Kubernetes Secret with client credentials and the
+// keys rendered from the secret template.
+// The secret is created, owned by kcClient, when it does not exist and the spec sets the clientId.
+func (r *ClientReconciler) updateSecretWithCredentials(ctx context.Context, kcClient *keycloakv1.Client, conn *keycloak.Connection, clientID *string, clientSecret *string) error {
 	logger := logf.FromContext(ctx)
 
 	if clientID == nil || clientSecret == nil {
@@ -367,16 +689,22 @@ func (r *ClientReconciler) updateSecretWithCredentials(ctx context.Context, kcCl
 		return nil
 	}
 
-	// Default keys
-	clientIDKey := "clientId"
-	clientSecretKey := "clientSecret"
-
-	if kcClient.Spec.SecretRef.ClientIDKey != "" {
-		clientIDKey = kcClient.Spec.SecretRef.ClientIDKey
+	content, err := credentialsContent(kcClient, conn, *clientID, *clientSecret)
+	if err != nil {
+		return err
 	}
-	if kcClient.Spec.SecretRef.ClientSecretKey != "" {
-		clientSecretKey = kcClient.Spec.SecretRef.ClientSecretKey
+	// The previous secret is dropped once Keycloak no longer accepts it
+	if previousSecretExpired(kcClient, time.Now()) {
+		content.Data[previousSecretKey(kcClient)] = nil
 	}
+	return r.writeSecret(ctx, kcClient, content)
+}
+
+// writeSecret writes content to the referenced Kubernetes Secret. Labels and annotations are added to
+// the existing ones, and what a previous secret template rendered but content no longer has is removed.
+// The secret is created, owned by kcClient, when it does not exist and the spec sets the clientId.
+func (r *ClientReconciler) writeSecret(ctx context.Context, kcClient *keycloakv1.Client, content secretContent) error {
+	logger := logf.FromContext(ctx)
 
 	// Get the secret
 	secret := &corev1.Secret{}
@@ -386,15 +714,75 @@ func (r *ClientReconciler) updateSecretWithCredentials(ctx context.Context, kcCl
 	}
 
 	if err := r.Get(ctx, secretName, secret); err != nil {
+		if apierrors.IsNotFound(err) && kcClient.Spec.Client.ClientID != nil {
+			return r.createSecretWithCredentials(ctx, kcClient, content)
+		}
 		return fmt.Errorf("failed to get secret: %w", err)
 	}
 
+	pruneTemplateKeys(&content, secret)
+
+	// Only write the secret when it changes, so that workloads watching it are not restarted needlessly
+	changed := false
+	for key, value := range content.Data {
+		current, ok := secret.Data[key]
+		if value == nil && ok || value != nil && !bytes.Equal(current, value) {
+			changed = true
+		}
+	}
+	for key, value := range content.Labels {
+		if current, ok := secret.Labels[key]; !ok || current != value {
+			changed = true
+		}
+	}
+	for key, value := range content.Annotations {
+		if current, ok := secret.Annotations[key]; !ok || current != value {
+			changed = true
+		}
+	}
+	for _, key := range content.RemovedLabels {
+		if _, ok := secret.Labels[key]; ok {
+			changed = true
+		}
+	}
+	for _, key := range content.RemovedAnnotations {
+		if _, ok := secret.Annotations[key]; ok {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
 	// Update secret data
 	if secret.Data == nil {
 		secret.Data = make(map[string][]byte)
 	}
-	secret.Data[clientIDKey] = []byte(*clientID)
-	secret.Data[clientSecretKey] = []byte(*clientSecret)
+	for key, value := range content.Data {
+		if value == nil {
+			delete(secret.Data, key)
+		} else {
+			secret.Data[key] = value
+		}
+	}
+	if len(content.Labels) > 0 {
+		if secret.Labels == nil {
+			secret.Labels = make(map[string]string)
+		}
+		maps.Copy(secret.Labels, content.Labels)
+	}
+	if len(content.Annotations) > 0 {
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		maps.Copy(secret.Annotations, content.Annotations)
+	}
+	for _, key := range content.RemovedLabels {
+		delete(secret.Labels, key)
+	}
+	for _, key := range content.RemovedAnnotations {
+		delete(secret.Annotations, key)
+	}
 
 	if err := r.Update(ctx, secret); err != nil {
 		return fmt.Errorf("failed to update secret: %w", err)
@@ -404,41 +792,57 @@ func (r *ClientReconciler) updateSecretWithCredentials(ctx context.Context, kcCl
 	return nil
 }
 
-// updateStatus updates the Client resource status
-func (r *ClientReconciler) updateStatus(ctx context.Context, kcClient *keycloakv1.Client, status metav1.ConditionStatus, reason, message string) {
+// createSecretWithCredentials creates the referenced Kubernetes Secret, owned by kcClient so that it is
+// garbage collected with it
+func (r *ClientReconciler) createSecretWithCredentials(ctx context.Context, kcClient *keycloakv1.Client, content secretContent) error {
 	logger := logf.FromContext(ctx)
 
-	condition := metav1.Condition{
-		Type:               "Ready",
-		Status:             status,
-		ObservedGeneration: kcClient.Generation,
-		LastTransitionTime: metav1.Now(),
-		Reason:             reason,
-		Message:            message,
+	data := maps.Clone(content.Data)
+	maps.DeleteFunc(data, func(_ string, value []byte) bool { return value == nil })
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        kcClient.Spec.SecretRef.Name,
+			Namespace:   kcClient.Namespace,
+			Labels:      content.Labels,
+			Annotations: content.Annotations,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
 	}
-
-	// Update or append the condition
-	updated := false
-	for i, cond := range kcClient.Status.Conditions {
-		if cond.Type == condition.Type {
-			kcClient.Status.Conditions[i] = condition
-			updated = true
-			break
-		}
-	}
-	if !updated {
-		kcClient.Status.Conditions = append(kcClient.Status.Conditions, condition)
+	if err := controllerutil.SetControllerReference(kcClient, secret, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set secret owner: %w", err)
 	}
 
-	if err := r.Status().Update(ctx, kcClient); err != nil {
-		logger.Error(err, "Failed to update Client status")
+	if err := r.Create(ctx, secret); err != nil {
+		return fmt.Errorf("failed to create secret: %w", err)
 	}
+
+	logger.Info("Successfully created secret with client credentials", "secret", kcClient.Spec.SecretRef.Name)
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClientReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexRealmRef(mgr, &keycloakv1.Client{}, func(obj client.Object) *keycloakv1.RealmReference {
+		return obj.(*keycloakv1.Client).Spec.RealmRef
+	}); err != nil {
+		return err
+	}
+	// Index clients by the client scopes they reference, to reconcile them when a ClientScope becomes Ready
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &keycloakv1.Client{}, clientScopeNameIndex,
+		func(obj client.Object) []string {
+			return referencedClientScopes(obj.(*keycloakv1.Client))
+		}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&keycloakv1.Client{}).
+		For(&keycloakv1.Client{}, ignoreStatusUpdates).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&keycloakv1.Realm{}, handler.EnqueueRequestsFromMapFunc(requestsForRealm(r.Client, &keycloakv1.ClientList{})),
+			realmReadinessChanged).
+		Watches(&keycloakv1.ClientScope{}, handler.EnqueueRequestsFromMapFunc(r.clientsForClientScope)).
 		Named("client").
 		Complete(r)
 }
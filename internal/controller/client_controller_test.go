@@ -18,18 +18,32 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"time"
 
+	gocloak "github.com/Nerzal/gocloak/v13"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
 )
 
 const (
@@ -445,7 +459,7 @@ var _ = Describe("Client Controller", func() {
 			}
 			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 
-			By("Manually calling updateStatus")
+			By("Manually calling setReady")
 			reconciler := &ClientReconciler{
 				Client: k8sClient,
 				Scheme: k8sClient.Scheme(),
@@ -458,7 +472,7 @@ var _ = Describe("Client Controller", func() {
 			}).Should(Succeed())
 
 			// Update the status
-			reconciler.updateStatus(ctx, createdClient, metav1.ConditionTrue, "TestReason", "Test message")
+			setReady(ctx, reconciler.Client, createdClient, metav1.ConditionTrue, "TestReason", "Test message")
 
 			By("Verifying status condition was updated")
 			Eventually(func() bool {
@@ -521,7 +535,7 @@ var _ = Describe("Client Controller", func() {
 				return k8sClient.Get(ctx, typeNamespacedNameUpdate, createdClient)
 			}).Should(Succeed())
 
-			reconciler.updateStatus(ctx, createdClient, metav1.ConditionTrue, "UpdatedReason", "Updated message")
+			setReady(ctx, reconciler.Client, createdClient, metav1.ConditionTrue, "UpdatedReason", "Updated message")
 
 			By("Verifying status condition was updated, not appended")
 			Eventually(func() bool {
@@ -543,6 +557,87 @@ var _ = Describe("Client Controller", func() {
 		})
 	})
 
+	Context("When writing credentials to the secret", func() {
+		const secretName = "test-credentials-secret"
+
+		ctx := context.Background()
+
+		It("Should only update the secret when its data changes", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"},
+				Data:       map[string][]byte{"clientId": []byte(testClientID), "clientSecret": []byte("secret")},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			}()
+			resourceVersion := secret.ResourceVersion
+
+			reconciler := &ClientReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "default"},
+				Spec:       keycloakv1.ClientSpec{SecretRef: keycloakv1.ClientSecretReference{Name: secretName}},
+			}
+
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("secret"))).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.ResourceVersion).To(Equal(resourceVersion))
+
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("rotated"))).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.ResourceVersion).NotTo(Equal(resourceVersion))
+			Expect(secret.Data["clientSecret"]).To(Equal([]byte("rotated")))
+		})
+
+		It("Should create and own the secret when the spec sets the clientId", func() {
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-owned-credentials", Namespace: "default"},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr("master"),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "test-owned-credentials", ClientSecretKey: "password"},
+					Client:    keycloakv1.ClientRepresentation{ClientID: strPtr(testClientID)},
+				},
+			}
+			Expect(k8sClient.Create(ctx, kcClient)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, kcClient)).To(Succeed())
+			}()
+			reconciler := &ClientReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			clientID, clientSecret, err := reconciler.getClientCredentials(ctx, kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientID).To(Equal(testClientID))
+			Expect(clientSecret).To(BeEmpty())
+
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("generated"))).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-owned-credentials", Namespace: "default"}, secret)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			}()
+			Expect(secret.Data).To(Equal(map[string][]byte{"clientId": []byte(testClientID), "password": []byte("generated")}))
+			Expect(metav1.IsControlledBy(secret, kcClient)).To(BeTrue())
+
+			// The secret is read back on the next reconcile, the clientId of the spec still wins
+			kcClient.Spec.Client.ClientID = strPtr("renamed")
+			clientID, clientSecret, err = reconciler.getClientCredentials(ctx, kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientID).To(Equal("renamed"))
+			Expect(clientSecret).To(Equal("generated"))
+		})
+
+		It("Should still require the secret when the spec does not set the clientId", func() {
+			reconciler := &ClientReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-missing-credentials", Namespace: "default"},
+				Spec:       keycloakv1.ClientSpec{SecretRef: keycloakv1.ClientSecretReference{Name: "test-missing-credentials"}},
+			}
+			_, _, err := reconciler.getClientCredentials(ctx, kcClient)
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("generated"))).NotTo(Succeed())
+		})
+	})
+
 	Context("When testing finalizer logic", func() {
 		const resourceName = "test-finalizer-client"
 
@@ -665,6 +760,1166 @@ var _ = Describe("Client Controller", func() {
 		})
 	})
 
+	Context("When checking ownership of an existing Keycloak client", func() {
+		var kcClient *keycloakv1.Client
+
+		BeforeEach(func() {
+			kcClient = &keycloakv1.Client{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default", UID: "uid-1"}}
+		})
+
+		It("Should mark the desired client without changing the spec", func() {
+			kcClient.Spec.Client.Attributes = map[string]string{"pkce.code.challenge.method": "S256"}
+			desired := (&ClientReconciler{}).desiredClient(kcClient, testClientID, "")
+
+			Expect(*desired.Attributes).To(HaveKeyWithValue(ownerUIDAttribute, "uid-1"))
+			Expect(*desired.Attributes).To(HaveKeyWithValue(ownerAttribute, "default/owner"))
+			Expect(*desired.Attributes).To(HaveKeyWithValue("pkce.code.challenge.method", "S256"))
+			Expect(kcClient.Spec.Client.Attributes).To(HaveLen(1))
+		})
+
+		It("Should manage clients it owns", func() {
+			attributes := withOwner(nil, kcClient)
+			adopting, conflict := checkAdoption(kcClient, "", attributes, false)
+			Expect(adopting).To(BeFalse())
+			Expect(conflict).To(BeEmpty())
+			Expect(isOwner(kcClient, attributes, false)).To(BeTrue())
+		})
+
+		DescribeTable("Should apply the adoption policy to unowned clients",
+			func(policy keycloakv1.AdoptionPolicy, expectAdopt bool) {
+				adopting, conflict := checkAdoption(kcClient, policy, map[string]string{}, false)
+				Expect(adopting).To(Equal(expectAdopt))
+				Expect(conflict == "").To(Equal(expectAdopt))
+			},
+			Entry("Never by default", keycloakv1.AdoptionPolicy(""), false),
+			Entry("Never", keycloakv1.AdoptionPolicyNever, false),
+			Entry("IfUnowned", keycloakv1.AdoptionPolicyIfUnowned, true),
+			Entry("Always", keycloakv1.AdoptionPolicyAlways, true),
+		)
+
+		DescribeTable("Should apply the adoption policy to clients owned by another resource",
+			func(policy keycloakv1.AdoptionPolicy, expectAdopt bool) {
+				other := &keycloakv1.Client{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-b", UID: "uid-2"}}
+				attributes := withOwner(nil, other)
+				adopting, conflict := checkAdoption(kcClient, policy, attributes, true)
+				Expect(adopting).To(Equal(expectAdopt))
+				if !expectAdopt {
+					Expect(conflict).To(ContainSubstring("already managed by team-b/other"))
+				}
+				Expect(isOwner(kcClient, attributes, true)).To(BeFalse())
+			},
+			Entry("Never", keycloakv1.AdoptionPolicyNever, false),
+			Entry("IfUnowned", keycloakv1.AdoptionPolicyIfUnowned, false),
+			Entry("Always", keycloakv1.AdoptionPolicyAlways, true),
+		)
+
+		It("Should keep managing clients synced before ownership markers existed", func() {
+			adopting, conflict := checkAdoption(kcClient, keycloakv1.AdoptionPolicyNever, nil, true)
+			Expect(adopting).To(BeFalse())
+			Expect(conflict).To(BeEmpty())
+			Expect(isOwner(kcClient, nil, true)).To(BeTrue())
+			Expect(isOwner(kcClient, nil, false)).To(BeFalse())
+		})
+	})
+
+	Context("When looking up the Keycloak client", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/admin/realms/test-realm/clients/known-id":
+					_, _ = fmt.Fprint(w, `{"id":"known-id","clientId":"old-client-id"}`)
+				case r.URL.Path == "/admin/realms/test-realm/clients" && r.URL.Query().Get("clientId") == testClientID:
+					_, _ = fmt.Fprintf(w, `[{"id":"other-id","clientId":%q}]`, testClientID)
+				case r.URL.Path == "/admin/realms/test-realm/clients":
+					_, _ = fmt.Fprint(w, `[]`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find client"}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newClient := func(id string) *keycloakv1.Client {
+			realm := testRealm
+			return &keycloakv1.Client{
+				Spec:   keycloakv1.ClientSpec{Realm: &realm},
+				Status: keycloakv1.ClientStatus{ID: id},
+			}
+		}
+
+		It("Should prefer the internal ID recorded in the status", func() {
+			found, err := (&ClientReconciler{}).findClient(context.Background(), conn.Client, "token", newClient("known-id"), testClientID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*found.ID).To(Equal("known-id"))
+			Expect(*found.ClientID).To(Equal("old-client-id"))
+		})
+
+		It("Should fall back to the clientId when the recorded client is gone", func() {
+			found, err := (&ClientReconciler{}).findClient(context.Background(), conn.Client, "token", newClient("deleted-id"), testClientID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*found.ID).To(Equal("other-id"))
+		})
+
+		It("Should report a missing client", func() {
+			found, err := (&ClientReconciler{}).findClient(context.Background(), conn.Client, "token", newClient(""), "unknown")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+	})
+
+	Context("When rotating the client secret", func() {
+		var (
+			server        *httptest.Server
+			conn          *keycloak.Connection
+			updated       map[string]any
+			currentSecret string
+		)
+
+		BeforeEach(func() {
+			updated = nil
+			currentSecret = "old-secret"
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/clients/rotated-id/client-secret":
+					_, _ = fmt.Fprintf(w, `{"type":"secret","value":%q}`, currentSecret)
+				case r.Method == http.MethodPost && r.URL.Path == "/admin/realms/test-realm/clients/rotated-id/client-secret":
+					Expect(currentSecret).To(Equal("old-secret"), "the secret was regenerated twice")
+					currentSecret = "new-secret"
+					_, _ = fmt.Fprint(w, `{"type":"secret","value":"new-secret"}`)
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/clients/rotated-id":
+					_, _ = fmt.Fprint(w, `{"id":"rotated-id","clientId":"app","secret":"new-secret","attributes":{"pkce.code.challenge.method":"S256"}}`)
+				case r.Method == http.MethodPut && r.URL.Path == "/admin/realms/test-realm/clients/rotated-id":
+					Expect(json.NewDecoder(r.Body).Decode(&updated)).To(Succeed())
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find client"}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should rotate on schedule or when the annotation changes", func() {
+			now := time.Now()
+			kcClient := &keycloakv1.Client{}
+			kcClient.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+			Expect(secretRotationDue(kcClient, now)).To(BeEmpty())
+
+			kcClient.Spec.SecretRotation = &keycloakv1.SecretRotation{Interval: &metav1.Duration{Duration: time.Hour}}
+			Expect(secretRotationDue(kcClient, now)).To(Equal("scheduled"))
+			kcClient.Status.LastSecretRotationTime = &metav1.Time{Time: now.Add(-time.Minute)}
+			Expect(secretRotationDue(kcClient, now)).To(BeEmpty())
+
+			kcClient.Annotations = map[string]string{keycloakv1.RotateSecretAnnotation: "2025-01-01T00:00:00Z"}
+			Expect(secretRotationDue(kcClient, now)).To(Equal("requested"))
+			kcClient.Status.SecretRotationRequest = "2025-01-01T00:00:00Z"
+			Expect(secretRotationDue(kcClient, now)).To(BeEmpty())
+		})
+
+		It("Should requeue for the end of the grace period and the next rotation", func() {
+			now := time.Now()
+			kcClient := &keycloakv1.Client{}
+			Expect(secretRotationRequeue(kcClient, now)).To(BeZero())
+
+			kcClient.Spec.SecretRotation = &keycloakv1.SecretRotation{
+				Interval:    &metav1.Duration{Duration: time.Hour},
+				GracePeriod: &metav1.Duration{Duration: 10 * time.Minute},
+			}
+			kcClient.Status.LastSecretRotationTime = &metav1.Time{Time: now}
+			Expect(secretRotationRequeue(kcClient, now)).To(Equal(10 * time.Minute))
+			Expect(previousSecretExpired(kcClient, now)).To(BeFalse())
+
+			later := now.Add(20 * time.Minute)
+			Expect(secretRotationRequeue(kcClient, later)).To(Equal(40 * time.Minute))
+			Expect(previousSecretExpired(kcClient, later)).To(BeTrue())
+		})
+
+		It("Should keep the previous secret valid during the grace period", func() {
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{
+				Realm:          strPtr(testRealm),
+				SecretRotation: &keycloakv1.SecretRotation{GracePeriod: &metav1.Duration{Duration: time.Hour}},
+			}}
+			now := time.Unix(1700000000, 0)
+
+			secret, err := (&ClientReconciler{}).rotateSecret(context.Background(), conn.Client, "token", kcClient, "rotated-id", "old-secret", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret).To(Equal("new-secret"))
+			Expect(updated).NotTo(HaveKey("secret"))
+			Expect(updated["attributes"]).To(Equal(map[string]any{
+				"pkce.code.challenge.method":            "S256",
+				"client.secret.rotated":                 "old-secret",
+				"client.secret.rotated.creation.time":   "1700000000",
+				"client.secret.rotated.expiration.time": "1700003600",
+			}))
+		})
+
+		It("Should not keep the previous secret without a grace period", func() {
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{Realm: strPtr(testRealm)}}
+
+			secret, err := (&ClientReconciler{}).rotateSecret(context.Background(), conn.Client, "token", kcClient, "rotated-id", "old-secret", time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret).To(Equal("new-secret"))
+			Expect(updated).To(BeNil())
+		})
+
+		It("Should complete a rotation Keycloak already regenerated the secret for", func() {
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{Realm: strPtr(testRealm)}}
+			currentSecret = "new-secret"
+
+			secret, err := (&ClientReconciler{}).rotateSecret(context.Background(), conn.Client, "token", kcClient, "rotated-id", "old-secret", time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret).To(Equal("new-secret"))
+		})
+
+		It("Should rotate the secret once when writing the new secret fails", func() {
+			ctx := context.Background()
+			fk := newFakeKeycloak()
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid", Generation: 1},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "app-credentials"},
+					Client:    keycloakv1.ClientRepresentation{ClientID: strPtr("app")},
+				},
+			}
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"clientId": []byte("app"), "clientSecret": []byte("old-secret")},
+			}
+			c := newFakeCluster(kcClient, credentials)
+			reconciler := &ClientReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(fk.get("/admin/realms/test-realm/clients/app-id")).To(HaveKeyWithValue("secret", "old-secret"))
+			fk.reset()
+
+			By("requesting a rotation while the credentials Secret cannot be written")
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			kcClient.Annotations = map[string]string{keycloakv1.RotateSecretAnnotation: "1"}
+			Expect(c.Update(ctx, kcClient)).To(Succeed())
+			c.fail = func(obj client.Object) error {
+				if _, ok := obj.(*corev1.Secret); ok {
+					return fmt.Errorf("secret is read-only")
+				}
+				return nil
+			}
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(MatchError(ContainSubstring("secret is read-only")))
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			Expect(kcClient.Status.PendingSecretRotationTime).NotTo(BeNil())
+
+			By("resuming the rotation with the secret Keycloak generated")
+			c.fail = nil
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(HaveExactElements("POST /admin/realms/test-realm/clients/app-id/client-secret"))
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(credentials), credentials)).To(Succeed())
+			Expect(credentials.Data).To(HaveKeyWithValue("clientSecret", []byte("generated-secret-1")))
+			Expect(fk.get("/admin/realms/test-realm/clients/app-id")).To(HaveKeyWithValue("secret", "generated-secret-1"))
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			Expect(kcClient.Status.PendingSecretRotationTime).To(BeNil())
+			Expect(kcClient.Status.LastSecretRotationTime).NotTo(BeNil())
+			Expect(kcClient.Status.SecretRotationRequest).To(Equal("1"))
+		})
+
+		It("Should keep the secret Keycloak generated when the client drifts during a pending rotation", func() {
+			ctx := context.Background()
+			fk := newFakeKeycloak()
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid", Generation: 1},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "app-credentials"},
+					Client:    keycloakv1.ClientRepresentation{ClientID: strPtr("app"), Description: strPtr("App")},
+				},
+			}
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"clientId": []byte("app"), "clientSecret": []byte("old-secret")},
+			}
+			c := newFakeCluster(kcClient, credentials)
+			reconciler := &ClientReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("leaving a rotation pending while the credentials Secret cannot be written")
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			kcClient.Annotations = map[string]string{keycloakv1.RotateSecretAnnotation: "1"}
+			Expect(c.Update(ctx, kcClient)).To(Succeed())
+			c.fail = func(obj client.Object) error {
+				if _, ok := obj.(*corev1.Secret); ok {
+					return fmt.Errorf("secret is read-only")
+				}
+				return nil
+			}
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(MatchError(ContainSubstring("secret is read-only")))
+
+			By("correcting a field changed in Keycloak while resuming the rotation")
+			fk.get("/admin/realms/test-realm/clients/app-id")["description"] = "Changed in Keycloak"
+			fk.reset()
+			c.fail = nil
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(ContainElement("PUT /admin/realms/test-realm/clients/app-id"))
+			Expect(fk.recorded()).NotTo(ContainElement("POST /admin/realms/test-realm/clients/app-id/client-secret"))
+			Expect(fk.body("PUT /admin/realms/test-realm/clients/app-id")).NotTo(ContainSubstring(`"secret"`))
+			Expect(fk.get("/admin/realms/test-realm/clients/app-id")).To(And(
+				HaveKeyWithValue("description", "App"), HaveKeyWithValue("secret", "generated-secret-1")))
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(credentials), credentials)).To(Succeed())
+			Expect(credentials.Data).To(HaveKeyWithValue("clientSecret", []byte("generated-secret-1")))
+		})
+	})
+
+	Context("When rendering the secret template", func() {
+		newClient := func(template *keycloakv1.SecretTemplate) *keycloakv1.Client {
+			return &keycloakv1.Client{Spec: keycloakv1.ClientSpec{Realm: strPtr("demo"), SecretTemplate: template}}
+		}
+
+		It("Should only write the credentials without a template", func() {
+			content, err := credentialsContent(newClient(nil), nil, testClientID, "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content.Data).To(Equal(map[string][]byte{"clientId": []byte(testClientID), "clientSecret": []byte("secret")}))
+			Expect(content.Labels).To(BeEmpty())
+		})
+
+		It("Should render keys, labels and annotations with the credentials and endpoints", func() {
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: "https://sso.example.com", Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+			kcClient := newClient(&keycloakv1.SecretTemplate{
+				Data: map[string]string{
+					"issuer": "{{ .Issuer }}",
+					"oidc.env": "OIDC_CLIENT_ID={{ .ClientID }}\nOIDC_CLIENT_SECRET={{ .ClientSecret | quote }}\n" +
+						"OIDC_TOKEN_URL={{ .TokenEndpoint }}\nOIDC_JWKS_URI={{ .JWKSURI }}",
+				},
+				Labels:      map[string]string{"keycloak.pewty.fr/realm": "{{ .Realm }}"},
+				Annotations: map[string]string{"keycloak.pewty.fr/url": "{{ .URL }}"},
+			})
+
+			content, err := credentialsContent(kcClient, conn, testClientID, "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content.Data["issuer"])).To(Equal("https://sso.example.com/realms/demo"))
+			Expect(string(content.Data["oidc.env"])).To(Equal("OIDC_CLIENT_ID=" + testClientID + "\nOIDC_CLIENT_SECRET=\"secret\"\n" +
+				"OIDC_TOKEN_URL=https://sso.example.com/realms/demo/protocol/openid-connect/token\n" +
+				"OIDC_JWKS_URI=https://sso.example.com/realms/demo/protocol/openid-connect/certs"))
+			Expect(content.Labels).To(Equal(map[string]string{"keycloak.pewty.fr/realm": "demo"}))
+			Expect(content.Annotations).To(HaveKeyWithValue("keycloak.pewty.fr/url", "https://sso.example.com"))
+			Expect(content.Annotations).To(HaveKeyWithValue(templateKeysAnnotation,
+				`{"data":["issuer","oidc.env"],"labels":["keycloak.pewty.fr/realm"],"annotations":["keycloak.pewty.fr/url"]}`))
+		})
+
+		It("Should remove the keys, labels and annotations the template no longer renders", func() {
+			c := newFakeCluster()
+			reconciler := &ClientReconciler{Client: c, Scheme: c.Scheme()}
+			kcClient := newClient(&keycloakv1.SecretTemplate{
+				Data:        map[string]string{"issuer": "{{ .Issuer }}", "realm": "{{ .Realm }}"},
+				Labels:      map[string]string{"team": "platform", "realm": "{{ .Realm }}"},
+				Annotations: map[string]string{"owner": "platform"},
+			})
+			const secretName = "test-template-secret"
+			kcClient.Name = "test-template"
+			kcClient.Namespace = "default"
+			kcClient.Spec.SecretRef.Name = secretName
+			kcClient.Spec.Client.ClientID = strPtr(testClientID)
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("secret"))).To(Succeed())
+
+			kcClient.Spec.SecretTemplate = &keycloakv1.SecretTemplate{Data: map[string]string{"realm": "{{ .Realm }}"}}
+			secret := &corev1.Secret{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+			secret.Labels["app"] = "demo"
+			secret.Data["extra"] = []byte("kept")
+			Expect(c.Update(ctx, secret)).To(Succeed())
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("secret"))).To(Succeed())
+
+			Expect(c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey("realm"))
+			Expect(secret.Data).To(HaveKey("extra"))
+			Expect(secret.Data).NotTo(HaveKey("issuer"))
+			Expect(secret.Labels).To(Equal(map[string]string{"app": "demo"}))
+			Expect(secret.Annotations).To(Equal(map[string]string{templateKeysAnnotation: `{"data":["realm"]}`}))
+
+			kcClient.Spec.SecretTemplate = nil
+			Expect(reconciler.updateSecretWithCredentials(ctx, kcClient, nil, strPtr(testClientID), strPtr("secret"))).To(Succeed())
+			Expect(c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey("extra"))
+			Expect(secret.Data).NotTo(HaveKey("realm"))
+			Expect(secret.Annotations).To(BeEmpty())
+		})
+
+		It("Should reject invalid templates and keys reserved for the credentials", func() {
+			Expect(validateSecretTemplate(newClient(&keycloakv1.SecretTemplate{
+				Data: map[string]string{"issuer": "{{ .Issuer "},
+			}))).To(MatchError(ContainSubstring("invalid secretTemplate.data.issuer")))
+			Expect(validateSecretTemplate(newClient(&keycloakv1.SecretTemplate{
+				Labels: map[string]string{"team": "{{ .Team }}"},
+			}))).To(MatchError(ContainSubstring("failed to render secretTemplate.labels.team")))
+			Expect(validateSecretTemplate(newClient(&keycloakv1.SecretTemplate{
+				Data: map[string]string{"clientSecret": "{{ .ClientSecret }}"},
+			}))).To(MatchError(ContainSubstring("reserved")))
+		})
+	})
+
+	Context("When exporting installation documents", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/admin/realms/test-realm/clients/exported-id/installation/providers/keycloak-oidc-keycloak-json":
+					_, _ = fmt.Fprint(w, `{"realm":"test-realm","resource":"app"}`)
+				case "/admin/realms/test-realm/clients/exported-id/installation/providers/mod-auth-mellon":
+					_, _ = w.Write([]byte{0x50, 0x4b, 0x03, 0x04, 0xff})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should keep binary documents out of the ConfigMap data", func() {
+			data, binaryData := splitBinary(map[string][]byte{"keycloak.json": []byte("{}"), "mellon.zip": {0xff}})
+			Expect(data).To(Equal(map[string]string{"keycloak.json": "{}"}))
+			Expect(binaryData).To(Equal(map[string][]byte{"mellon.zip": {0xff}}))
+		})
+
+		It("Should write the documents to a ConfigMap owned by the Client", func() {
+			ctx := context.Background()
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-exports", Namespace: "default"},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "test-exports"},
+					Exports: &keycloakv1.ClientExports{
+						Kind: keycloakv1.ExportKindConfigMap,
+						Name: "test-exports-adapter",
+						Providers: []keycloakv1.InstallationProvider{
+							{ID: "keycloak-oidc-keycloak-json", Key: "keycloak.json"},
+							{ID: "mod-auth-mellon"},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, kcClient)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, kcClient)).To(Succeed())
+			}()
+			kcClient.Status.ID = "exported-id"
+			reconciler := &ClientReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			changed, err := reconciler.reconcileExports(ctx, conn, "token", kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(kcClient.Status.Conditions, "Exported")).To(BeTrue())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-exports-adapter", Namespace: "default"}, configMap)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+			}()
+			Expect(configMap.Data).To(Equal(map[string]string{"keycloak.json": `{"realm":"test-realm","resource":"app"}`}))
+			Expect(configMap.BinaryData).To(HaveKey("mod-auth-mellon"))
+			Expect(metav1.IsControlledBy(configMap, kcClient)).To(BeTrue())
+
+			changed, err = reconciler.reconcileExports(ctx, conn, "token", kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+
+		It("Should refuse to overwrite a ConfigMap the Client does not own", func() {
+			existing := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "adapter", Namespace: "default"},
+				Data:       map[string]string{"app.conf": "kept"},
+			}
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-exports", Namespace: "default", UID: "test-exports-uid"},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "credentials"},
+					Exports: &keycloakv1.ClientExports{
+						Kind:      keycloakv1.ExportKindConfigMap,
+						Name:      "adapter",
+						Providers: []keycloakv1.InstallationProvider{{ID: "keycloak-oidc-keycloak-json"}},
+					},
+				},
+			}
+			kcClient.Status.ID = "exported-id"
+			c := newFakeCluster(existing, kcClient)
+			reconciler := &ClientReconciler{Client: c, Scheme: c.Scheme()}
+
+			changed, err := reconciler.reconcileExports(context.Background(), conn, "token", kcClient)
+			Expect(err).To(MatchError(errExportNotOwned))
+			Expect(changed).To(BeTrue())
+			condition := meta.FindStatusCondition(kcClient.Status.Conditions, "Exported")
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Conflict"))
+
+			Expect(c.Get(context.Background(), client.ObjectKeyFromObject(existing), existing)).To(Succeed())
+			Expect(existing.Data).To(Equal(map[string]string{"app.conf": "kept"}))
+			Expect(existing.OwnerReferences).To(BeEmpty())
+		})
+
+		It("Should report unknown providers and refuse to overwrite the credentials secret", func() {
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{
+				Realm:     strPtr(testRealm),
+				SecretRef: keycloakv1.ClientSecretReference{Name: "credentials"},
+				Exports: &keycloakv1.ClientExports{
+					Name:      "adapter",
+					Providers: []keycloakv1.InstallationProvider{{ID: "unknown"}},
+				},
+			}}
+			kcClient.Status.ID = "exported-id"
+			Expect(exportKind(kcClient.Spec.Exports)).To(Equal(keycloakv1.ExportKindSecret))
+
+			_, err := (&ClientReconciler{}).reconcileExports(context.Background(), conn, "token", kcClient)
+			Expect(err).To(MatchError(ContainSubstring("failed to export unknown")))
+			Expect(meta.IsStatusConditionFalse(kcClient.Status.Conditions, "Exported")).To(BeTrue())
+
+			kcClient.Spec.Exports.Kind = keycloakv1.ExportKindSecret
+			kcClient.Spec.Exports.Name = "credentials"
+			_, err = (&ClientReconciler{}).reconcileExports(context.Background(), conn, "token", kcClient)
+			Expect(err).To(MatchError(ContainSubstring("credentials secret")))
+		})
+	})
+
+	Context("When reconciling protocol mappers", func() {
+		mapper := func(id, name, claim string) gocloak.ProtocolMapperRepresentation {
+			return gocloak.ProtocolMapperRepresentation{
+				ID:             strPtr(id),
+				Name:           strPtr(name),
+				Protocol:       strPtr(protocolOIDC),
+				ProtocolMapper: strPtr("oidc-usermodel-attribute-mapper"),
+				Config:         &map[string]string{"claim.name": claim},
+			}
+		}
+
+		It("Should match mappers by name", func() {
+			desired := []gocloak.ProtocolMapperRepresentation{
+				{Name: strPtr("email"), ProtocolMapper: strPtr("oidc-usermodel-attribute-mapper"), Config: &map[string]string{"claim.name": "email"}},
+				mapper("", "team", "team_name"),
+				mapper("", "tenant", "tenant"),
+			}
+			live := []gocloak.ProtocolMapperRepresentation{
+				mapper("email-id", "email", "email"),
+				mapper("team-id", "team", "team"),
+				mapper("legacy-id", "legacy", "legacy"),
+			}
+
+			plan := planProtocolMappers(desired, live, protocolOIDC)
+			Expect(plan.create).To(HaveLen(1))
+			Expect(*plan.create[0].Name).To(Equal("tenant"))
+			Expect(plan.update).To(HaveLen(1))
+			Expect(*plan.update[0].ID).To(Equal("team-id"))
+			Expect(plan.delete).To(HaveLen(1))
+			Expect(*plan.delete[0].ID).To(Equal("legacy-id"))
+
+			Expect(planProtocolMappers(desired[:1], live[:1], protocolOIDC).empty()).To(BeTrue())
+		})
+
+		It("Should leave the mappers alone when the client declares none", func() {
+			live := []gocloak.ProtocolMapperRepresentation{mapper("email-id", "email", "email")}
+			Expect(planProtocolMappers(nil, live, protocolOIDC).empty()).To(BeTrue())
+		})
+
+		It("Should delete every mapper when the client declares an empty list", func() {
+			live := []gocloak.ProtocolMapperRepresentation{mapper("email-id", "email", "email")}
+			plan := planProtocolMappers([]gocloak.ProtocolMapperRepresentation{}, live, protocolOIDC)
+			Expect(plan.create).To(BeEmpty())
+			Expect(plan.update).To(BeEmpty())
+			Expect(plan.delete).To(HaveLen(1))
+			Expect(*plan.delete[0].ID).To(Equal("email-id"))
+		})
+
+		It("Should apply the plan through the protocol mapper endpoints", func() {
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				if r.Method == http.MethodPost {
+					w.Header().Set("Location", r.URL.Path+"/new-id")
+					w.WriteHeader(http.StatusCreated)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			plan := protocolMapperPlan{
+				create: []gocloak.ProtocolMapperRepresentation{mapper("", "tenant", "tenant")},
+				update: []gocloak.ProtocolMapperRepresentation{mapper("team-id", "team", "team_name")},
+				delete: []gocloak.ProtocolMapperRepresentation{mapper("legacy-id", "legacy", "legacy")},
+			}
+			Expect(applyProtocolMappers(context.Background(), conn.Client, "token", testRealm, "client-id", plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/test-realm/clients/client-id/protocol-mappers/models/legacy-id",
+				"PUT /admin/realms/test-realm/clients/client-id/protocol-mappers/models/team-id",
+				"POST /admin/realms/test-realm/clients/client-id/protocol-mappers/models",
+			}))
+		})
+	})
+
+	Context("When assigning client scopes", func() {
+		scopes := func(names ...string) *[]string { return &names }
+
+		It("Should plan the scopes to assign and unassign", func() {
+			desired := gocloak.Client{
+				DefaultClientScopes:  scopes("profile", "email", "team"),
+				OptionalClientScopes: scopes("offline_access", "roles"),
+			}
+			live := gocloak.Client{
+				DefaultClientScopes:  scopes("profile", "email", "roles", "web-origins"),
+				OptionalClientScopes: scopes("offline_access", "address"),
+			}
+
+			plan := planClientScopes(desired, live)
+			Expect(plan.addDefault).To(Equal([]string{"team"}))
+			Expect(plan.removeDefault).To(Equal([]string{"roles", "web-origins"}))
+			Expect(plan.addOptional).To(Equal([]string{"roles"}))
+			Expect(plan.removeOptional).To(Equal([]string{"address"}))
+			Expect(plan.drifted()).To(Equal([]string{"defaultClientScopes", "optionalClientScopes"}))
+		})
+
+		It("Should leave undeclared scopes alone but move scopes between kinds", func() {
+			live := gocloak.Client{
+				DefaultClientScopes:  scopes("profile", "email"),
+				OptionalClientScopes: scopes("offline_access", "phone"),
+			}
+			Expect(planClientScopes(gocloak.Client{}, live).drifted()).To(BeEmpty())
+
+			plan := planClientScopes(gocloak.Client{DefaultClientScopes: scopes("profile", "email", "phone")}, live)
+			Expect(plan.addDefault).To(Equal([]string{"phone"}))
+			Expect(plan.removeOptional).To(Equal([]string{"phone"}))
+		})
+
+		It("Should resolve scope names and refuse scopes missing in the realm", func() {
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/client-scopes" {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = fmt.Fprint(w, `[{"id":"team-id","name":"team"},{"id":"address-id","name":"address"}]`)
+					return
+				}
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			plan := clientScopePlan{addDefault: []string{"team"}, removeOptional: []string{"address"}}
+			Expect(applyClientScopes(context.Background(), conn.Client, "token", testRealm, "client-id", plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/test-realm/clients/client-id/optional-client-scopes/address-id",
+				"PUT /admin/realms/test-realm/clients/client-id/default-client-scopes/team-id",
+			}))
+
+			calls = nil
+			plan = clientScopePlan{addDefault: []string{"team", "missing"}}
+			err = applyClientScopes(context.Background(), conn.Client, "token", testRealm, "client-id", plan)
+			Expect(err).To(MatchError("client scopes not found in realm: missing"))
+			Expect(calls).To(BeEmpty())
+		})
+	})
+
+	Context("When granting roles to the service account", func() {
+		role := func(id, name string) gocloak.Role { return gocloak.Role{ID: &id, Name: &name} }
+		live := func() *gocloak.MappingsRepresentation {
+			return &gocloak.MappingsRepresentation{
+				RealmMappings: &[]gocloak.Role{role("default-id", "default-roles-test-realm"), role("admin-id", "admin")},
+				ClientMappings: map[string]*gocloak.ClientMappingsRepresentation{
+					"realm-management": {
+						ID:       strPtr("realm-management-id"),
+						Client:   strPtr("realm-management"),
+						Mappings: &[]gocloak.Role{role("view-users-id", "view-users"), role("manage-users-id", "manage-users")},
+					},
+				},
+			}
+		}
+
+		It("Should grant declared roles and leave others alone by default", func() {
+			spec := &keycloakv1.ServiceAccount{
+				RealmRoles:  []string{"admin", "reader"},
+				ClientRoles: map[string][]string{"realm-management": {"view-users"}, "billing": {"invoice"}},
+			}
+
+			plan := planServiceAccountRoles(spec, live(), testRealm)
+			Expect(plan.realm.grant).To(Equal([]string{"reader"}))
+			Expect(plan.realm.revoke).To(BeEmpty())
+			Expect(plan.clients).To(HaveLen(1))
+			Expect(plan.clients["billing"].grant).To(Equal([]string{"invoice"}))
+			Expect(plan.drifted()).To(Equal([]string{"serviceAccount"}))
+
+			spec.RealmRoles = []string{"admin"}
+			spec.ClientRoles = map[string][]string{"realm-management": {"view-users"}}
+			Expect(planServiceAccountRoles(spec, live(), testRealm).drifted()).To(BeEmpty())
+		})
+
+		It("Should revoke roles not declared with the Exact policy, except the default roles", func() {
+			spec := &keycloakv1.ServiceAccount{
+				ClientRoles:       map[string][]string{"realm-management": {"view-users"}},
+				RoleMappingPolicy: keycloakv1.RoleMappingPolicyExact,
+			}
+
+			plan := planServiceAccountRoles(spec, live(), testRealm)
+			Expect(plan.realm.grant).To(BeEmpty())
+			Expect(plan.realm.revoke).To(Equal([]gocloak.Role{role("admin-id", "admin")}))
+			Expect(plan.clients["realm-management"].revoke).To(Equal([]gocloak.Role{role("manage-users-id", "manage-users")}))
+
+			spec.ClientRoles = nil
+			plan = planServiceAccountRoles(spec, live(), testRealm)
+			Expect(plan.clients["realm-management"].revoke).To(HaveLen(2))
+		})
+
+		It("Should refuse service account roles on a client without a service account", func() {
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{ServiceAccount: &keycloakv1.ServiceAccount{}}}
+			Expect(validateServiceAccount(kcClient)).To(MatchError("serviceAccount requires client.serviceAccountsEnabled"))
+
+			enabled := true
+			kcClient.Spec.Client.ServiceAccountsEnabled = &enabled
+			Expect(validateServiceAccount(kcClient)).To(Succeed())
+		})
+
+		It("Should resolve roles and refuse roles missing in the realm", func() {
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/roles/reader":
+					_, _ = fmt.Fprint(w, `{"id":"reader-id","name":"reader"}`)
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/clients":
+					if r.URL.Query().Get("clientId") == "billing" {
+						_, _ = fmt.Fprint(w, `[{"id":"billing-id","clientId":"billing"}]`)
+					} else {
+						_, _ = fmt.Fprint(w, `[]`)
+					}
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/clients/billing-id/roles/invoice":
+					_, _ = fmt.Fprint(w, `{"id":"invoice-id","name":"invoice"}`)
+				case r.Method == http.MethodGet:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find role"}`)
+				default:
+					calls = append(calls, r.Method+" "+r.URL.Path)
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			plan := serviceAccountRolePlan{
+				userID: "user-id",
+				roleMappingPlan: roleMappingPlan{
+					realm: roleGrants{grant: []string{"reader"}, revoke: []gocloak.Role{role("admin-id", "admin")}},
+					clients: map[string]roleGrants{
+						"billing":          {grant: []string{"invoice"}},
+						"realm-management": {revoke: []gocloak.Role{role("manage-users-id", "manage-users")}},
+					},
+					clientIDs: map[string]string{"realm-management": "realm-management-id"},
+				},
+			}
+			Expect(applyServiceAccountRoles(context.Background(), conn.Client, "token", testRealm, plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/test-realm/users/user-id/role-mappings/realm",
+				"POST /admin/realms/test-realm/users/user-id/role-mappings/realm",
+				"POST /admin/realms/test-realm/users/user-id/role-mappings/clients/billing-id",
+				"DELETE /admin/realms/test-realm/users/user-id/role-mappings/clients/realm-management-id",
+			}))
+
+			calls = nil
+			plan = serviceAccountRolePlan{
+				userID: "user-id",
+				roleMappingPlan: roleMappingPlan{
+					realm:   roleGrants{grant: []string{"reader", "writer"}},
+					clients: map[string]roleGrants{"billing": {grant: []string{"refund"}}, "shipping": {grant: []string{"ship"}}},
+				},
+			}
+			err = applyServiceAccountRoles(context.Background(), conn.Client, "token", testRealm, plan)
+			Expect(err).To(MatchError("roles not found in realm: billing/refund, shipping, writer"))
+			Expect(calls).To(BeEmpty())
+		})
+	})
+
+	Context("When declaring client roles", func() {
+		role := func(id, name, description string) *gocloak.Role {
+			return &gocloak.Role{ID: &id, Name: &name, Description: &description}
+		}
+
+		It("Should plan the roles to create, update and prune", func() {
+			desired := []keycloakv1.ClientRole{
+				{Name: "reader", Description: "Read access"},
+				{Name: "writer", Description: "Write access", Attributes: map[string][]string{"level": {"2"}}},
+				{Name: "auditor", Attributes: map[string][]string{"scope": {"all"}}},
+				{Name: "admin"},
+			}
+			writer := role("writer-id", "writer", "Write")
+			writer.Attributes = &map[string][]string{"level": {"1"}, "team": {"core"}}
+			live := []*gocloak.Role{
+				role("reader-id", "reader", "Read access"), writer, role("auditor-id", "auditor", "Audit access"), role("legacy-id", "legacy", ""),
+			}
+
+			plan := planClientRoles(desired, live, false)
+			Expect(plan.create).To(HaveLen(1))
+			Expect(*plan.create[0].Name).To(Equal("admin"))
+			Expect(plan.update).To(HaveLen(2))
+			Expect(*plan.update[0].ID).To(Equal("writer-id"))
+			Expect(*plan.update[0].Description).To(Equal("Write access"))
+			Expect(*plan.update[0].Attributes).To(Equal(map[string][]string{"level": {"2"}, "team": {"core"}}))
+			By("keeping the description set in Keycloak when the spec declares none")
+			Expect(*plan.update[1].ID).To(Equal("auditor-id"))
+			Expect(*plan.update[1].Description).To(Equal("Audit access"))
+			Expect(*plan.update[1].Attributes).To(Equal(map[string][]string{"scope": {"all"}}))
+			Expect(plan.delete).To(BeEmpty())
+			Expect(plan.roleIDs).To(HaveKeyWithValue("legacy", "legacy-id"))
+			Expect(plan.drifted()).To(Equal([]string{"roles"}))
+
+			plan = planClientRoles(desired, live, true)
+			Expect(plan.delete).To(HaveLen(1))
+			Expect(*plan.delete[0].Name).To(Equal("legacy"))
+
+			Expect(planClientRoles(nil, live, true).drifted()).To(BeEmpty())
+		})
+
+		It("Should plan the composites of a role", func() {
+			clientRole := func(id, name, idOfClient string) *gocloak.Role {
+				return &gocloak.Role{ID: &id, Name: &name, ClientRole: gocloak.BoolP(true), ContainerID: &idOfClient}
+			}
+			live := []*gocloak.Role{
+				{ID: strPtr("offline-id"), Name: strPtr("offline_access"), ClientRole: gocloak.BoolP(false), ContainerID: strPtr("realm-id")},
+				clientRole("view-users-id", "view-users", "realm-management-id"),
+				clientRole("reader-id", "reader", "app-id"),
+			}
+			desired := &keycloakv1.RoleComposites{
+				Realm:  []string{"uma_authorization"},
+				Client: map[string][]string{"app": {"reader", "writer"}, "billing": {"invoice"}},
+			}
+			clientIDs := map[string]string{"app": "app-id", "realm-management": "realm-management-id"}
+
+			plan := planComposites(desired, live, clientIDs)
+			Expect(plan.add).To(Equal([]compositeRef{
+				{name: "uma_authorization"}, {clientID: "app", name: "writer"}, {clientID: "billing", name: "invoice"},
+			}))
+			Expect(plan.remove).To(HaveLen(2))
+			Expect(*plan.remove[0].Name).To(Equal("offline_access"))
+			Expect(*plan.remove[1].Name).To(Equal("view-users"))
+
+			Expect(planComposites(nil, live, clientIDs).empty()).To(BeTrue())
+		})
+
+		It("Should apply roles and resolve composites before changing anything", func() {
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/roles/uma_authorization":
+					_, _ = fmt.Fprint(w, `{"id":"uma-id","name":"uma_authorization"}`)
+				case r.Method == http.MethodGet && r.URL.Path == "/admin/realms/test-realm/clients/app-id/roles/writer":
+					_, _ = fmt.Fprint(w, `{"id":"writer-id","name":"writer"}`)
+				case r.Method == http.MethodGet:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find role"}`)
+				default:
+					calls = append(calls, r.Method+" "+r.URL.Path)
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			plan := clientRolePlan{
+				create: []gocloak.Role{{Name: strPtr("writer")}},
+				update: []gocloak.Role{*role("reader-id", "reader", "Read access")},
+				delete: []gocloak.Role{*role("legacy-id", "legacy", "")},
+				composites: map[string]compositePlan{
+					"admin": {
+						add:    []compositeRef{{name: "uma_authorization"}, {clientID: "app", name: "writer"}},
+						remove: []gocloak.Role{*role("reader-id", "reader", "")},
+					},
+				},
+				roleIDs:   map[string]string{"admin": "admin-id", "reader": "reader-id", "legacy": "legacy-id"},
+				clientIDs: map[string]string{"app": "app-id"},
+			}
+			Expect(applyClientRoles(context.Background(), conn.Client, "token", testRealm, "app-id", plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/test-realm/clients/app-id/roles/legacy",
+				"PUT /admin/realms/test-realm/clients/app-id/roles/reader",
+				"POST /admin/realms/test-realm/clients/app-id/roles",
+				"DELETE /admin/realms/test-realm/roles-by-id/admin-id/composites",
+				"POST /admin/realms/test-realm/roles-by-id/admin-id/composites",
+			}))
+
+			calls = nil
+			plan.create = nil
+			plan.composites["admin"] = compositePlan{add: []compositeRef{{clientID: "app", name: "writer"}, {clientID: "billing", name: "invoice"}, {name: "missing"}}}
+			err = applyClientRoles(context.Background(), conn.Client, "token", testRealm, "app-id", plan)
+			Expect(err).To(MatchError("roles not found in realm: billing, missing"))
+			Expect(calls).To(BeEmpty())
+		})
+	})
+
+	Context("When configuring Authorization Services", func() {
+		// Resource server as exported by Keycloak, with references by name
+		const exported = `{
+			"allowRemoteResourceManagement": true,
+			"policyEnforcementMode": "ENFORCING",
+			"decisionStrategy": "UNANIMOUS",
+			"resources": [
+				{"name": "Default Resource", "type": "urn:app:resources:default", "uris": ["/*"]},
+				{"name": "documents", "uris": ["/documents/*"], "scopes": [{"name": "read"}, {"name": "write"}]}
+			],
+			"policies": [
+				{"name": "Default Policy", "type": "js", "logic": "POSITIVE", "decisionStrategy": "AFFIRMATIVE", "config": {"code": "$evaluation.grant();"}},
+				{"name": "admins", "type": "role", "logic": "POSITIVE", "decisionStrategy": "UNANIMOUS",
+					"config": {"fetchRoles": "false", "roles": "[{\"id\":\"offline_access\",\"required\":true},{\"id\":\"my-app/admin\",\"required\":false}]"}},
+				{"name": "Default Permission", "type": "resource", "logic": "POSITIVE", "decisionStrategy": "UNANIMOUS",
+					"config": {"defaultResourceType": "urn:app:resources:default", "applyPolicies": "[\"Default Policy\"]"}},
+				{"name": "documents-read", "type": "scope", "logic": "POSITIVE", "decisionStrategy": "UNANIMOUS",
+					"config": {"resources": "[\"documents\"]", "scopes": "[\"read\"]", "applyPolicies": "[\"admins\"]"}}
+			],
+			"scopes": [{"name": "read"}, {"name": "write"}]
+		}`
+
+		desired := func() *keycloakv1.Authorization {
+			return &keycloakv1.Authorization{
+				PolicyEnforcementMode: "PERMISSIVE",
+				Scopes:                []keycloakv1.AuthorizationScope{{Name: "read", DisplayName: "Read"}, {Name: "write"}},
+				Resources: []keycloakv1.AuthorizationResource{
+					{Name: "documents", URIs: []string{"/documents/*"}, Scopes: []string{"write", "read"}},
+				},
+				Policies: []keycloakv1.AuthorizationPolicy{
+					{Name: "admins", Type: "role", Roles: []keycloakv1.PolicyRole{{Role: "my-app/admin"}, {Role: "offline_access", Required: true}}},
+					{Name: "weekdays", Type: "time", Time: &keycloakv1.TimeCondition{DayMonth: "1", DayMonthEnd: "5"}},
+				},
+				Permissions: []keycloakv1.AuthorizationPermission{
+					{Name: "documents-read", Type: "scope", Resources: []string{"documents"}, Scopes: []string{"read"}, Policies: []string{"admins"}},
+					{Name: "documents-write", Type: "scope", Resources: []string{"documents"}, Scopes: []string{"write"}, Policies: []string{"admins", "weekdays"}},
+				},
+			}
+		}
+
+		It("Should compare the declared authorization with the exported resource server", func() {
+			var live gocloak.ResourceServerRepresentation
+			Expect(json.Unmarshal([]byte(exported), &live)).To(Succeed())
+
+			plan := planAuthorization(desired(), &live)
+			Expect(plan.settings).NotTo(BeNil())
+			Expect(string(*plan.settings.PolicyEnforcementMode)).To(Equal("PERMISSIVE"))
+			Expect(*plan.settings.AllowRemoteResourceManagement).To(BeTrue())
+			Expect(plan.scopes.update).To(HaveLen(1))
+			Expect(plan.scopes.update[0].Name).To(Equal("read"))
+			Expect(plan.resources.empty()).To(BeTrue())
+			Expect(plan.policies.create).To(HaveLen(1))
+			Expect(plan.policies.create[0].Name).To(Equal("weekdays"))
+			Expect(plan.policies.update).To(BeEmpty())
+			Expect(plan.policies.delete).To(BeEmpty())
+			Expect(plan.permissions.create).To(HaveLen(1))
+			Expect(plan.permissions.update).To(BeEmpty())
+			Expect(plan.drifted()).To(Equal([]string{"authorization"}))
+
+			spec := desired()
+			spec.PrunePolicy = keycloakv1.PrunePolicyPrune
+			spec.Policies[0].Roles[0].Required = true
+			plan = planAuthorization(spec, &live)
+			Expect(plan.resources.delete).To(Equal([]string{"Default Resource"}))
+			Expect(plan.policies.update).To(HaveLen(1))
+			Expect(plan.policies.delete).To(Equal([]string{"Default Policy"}))
+			Expect(plan.permissions.delete).To(Equal([]string{"Default Permission"}))
+
+			Expect(planAuthorization(&keycloakv1.Authorization{PrunePolicy: keycloakv1.PrunePolicyPrune}, &live).drifted()).To(BeEmpty())
+		})
+
+		It("Should refuse authorization settings on a client without Authorization Services", func() {
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{Authorization: &keycloakv1.Authorization{}}}
+			Expect(validateAuthorization(kcClient)).To(MatchError("authorization requires client.authorizationServicesEnabled"))
+
+			enabled := true
+			kcClient.Spec.Client.AuthorizationServicesEnabled = &enabled
+			Expect(validateAuthorization(kcClient)).To(Succeed())
+		})
+
+		It("Should apply the changes in dependency order", func() {
+			var calls []string
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				const prefix = "/admin/realms/test-realm/clients/app-id/authz/resource-server"
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == prefix+"/scope":
+					_, _ = fmt.Fprint(w, `[{"id":"read-id","name":"read"}]`)
+				case r.Method == http.MethodGet && r.URL.Path == prefix+"/resource":
+					_, _ = fmt.Fprint(w, `[{"_id":"documents-id","name":"documents"}]`)
+				case r.Method == http.MethodGet && r.URL.Path == prefix+"/policy":
+					_, _ = fmt.Fprint(w, `[{"id":"default-policy-id","name":"Default Policy"}]`)
+				default:
+					data, _ := io.ReadAll(r.Body)
+					calls = append(calls, r.Method+" "+strings.TrimPrefix(r.URL.Path, prefix))
+					bodies = append(bodies, string(data))
+					w.WriteHeader(http.StatusCreated)
+					_, _ = fmt.Fprint(w, `{}`)
+				}
+			}))
+			defer server.Close()
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			spec := desired()
+			plan := authorizationPlan{
+				settings:    &gocloak.ResourceServerRepresentation{PolicyEnforcementMode: gocloak.PERMISSIVE},
+				scopes:      namedChanges[keycloakv1.AuthorizationScope]{create: spec.Scopes[1:]},
+				resources:   namedChanges[keycloakv1.AuthorizationResource]{update: spec.Resources},
+				policies:    namedChanges[keycloakv1.AuthorizationPolicy]{create: spec.Policies[:1], delete: []string{"Default Policy"}},
+				permissions: namedChanges[keycloakv1.AuthorizationPermission]{create: spec.Permissions[1:]},
+			}
+			Expect(applyAuthorization(context.Background(), conn, "token", testRealm, "app-id", plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"PUT ",
+				"POST /scope",
+				"PUT /resource/documents-id",
+				"POST /policy/role",
+				"POST /permission/scope",
+				"DELETE /policy/default-policy-id",
+			}))
+			Expect(bodies[3]).To(MatchJSON(`{"name":"admins","type":"role","roles":[{"id":"my-app/admin","required":false},{"id":"offline_access","required":true}]}`))
+			Expect(bodies[4]).To(MatchJSON(`{"name":"documents-write","type":"scope","resources":["documents"],"scopes":["write"],"policies":["admins","weekdays"]}`))
+		})
+	})
+
+	Context("When choosing the deletion policy", func() {
+		It("Should prefer the annotation, then the spec, then the operator default", func() {
+			kcClient := &keycloakv1.Client{}
+			Expect(deletionPolicy(kcClient, "", "")).To(Equal(keycloakv1.DeletionPolicyDelete))
+			Expect(deletionPolicy(kcClient, "", keycloakv1.DeletionPolicyRetain)).To(Equal(keycloakv1.DeletionPolicyRetain))
+			Expect(deletionPolicy(kcClient, keycloakv1.DeletionPolicyDelete, keycloakv1.DeletionPolicyRetain)).
+				To(Equal(keycloakv1.DeletionPolicyDelete))
+
+			kcClient.Annotations = map[string]string{keycloakv1.DeletionPolicyAnnotation: "Retain"}
+			Expect(deletionPolicy(kcClient, keycloakv1.DeletionPolicyDelete, "")).To(Equal(keycloakv1.DeletionPolicyRetain))
+		})
+
+		It("Should reject an invalid annotation", func() {
+			kcClient := &keycloakv1.Client{}
+			kcClient.Annotations = map[string]string{keycloakv1.DeletionPolicyAnnotation: "Orphan"}
+			_, err := deletionPolicy(kcClient, "", "")
+			Expect(err).To(MatchError(ContainSubstring("invalid keycloak.pewty.fr/deletion-policy annotation")))
+		})
+
+		It("Should release a retained client without contacting Keycloak", func() {
+			ctx := context.Background()
+			realm := testRealm
+			resource := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-retained-client",
+					Namespace:  "default",
+					Finalizers: []string{clientFinalizer},
+				},
+				Spec: keycloakv1.ClientSpec{
+					Realm:          &realm,
+					DeletionPolicy: keycloakv1.DeletionPolicyRetain,
+					SecretRef:      keycloakv1.ClientSecretReference{Name: "retained-secret"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			// No connection is configured, so reaching Keycloak would fail the reconcile
+			reconciler := &ClientReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				Recorder:    events.NewFakeRecorder(10),
+				Connections: &ConnectionResolver{Client: k8sClient},
+			}
+			namespacedName := types.NamespacedName{Name: resource.Name, Namespace: "default"}
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, namespacedName, &keycloakv1.Client{}))
+			}).Should(BeTrue())
+		})
+
+		It("Should release a client whose realm was deleted from Keycloak first", func() {
+			ctx := context.Background()
+			fk := newFakeKeycloak()
+			fk.put("/admin/realms/test-realm", `{"id":"test-realm-id","realm":"test-realm"}`)
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid", Generation: 1},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "app-credentials"},
+					Client:    keycloakv1.ClientRepresentation{ClientID: strPtr("app")},
+				},
+			}
+			c := newFakeCluster(kcClient)
+			reconciler := &ClientReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			// The Realm resource deleted with the namespace takes its clients with it
+			Expect(fk.conn.Client.DeleteRealm(ctx, "token", testRealm)).To(Succeed())
+			fk.reset()
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			Expect(c.Delete(ctx, kcClient)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, kcClient))).To(BeTrue())
+		})
+	})
+
 	Context("When testing SAML-specific conversions", func() {
 		It("Should handle SAML protocol configuration", func() {
 			reconciler := &ClientReconciler{}
@@ -755,7 +2010,8 @@ var _ = Describe("Client Controller", func() {
 			Expect(*goCloak.RedirectURIs).To(BeEmpty())
 			Expect(goCloak.WebOrigins).NotTo(BeNil())
 			Expect(*goCloak.WebOrigins).To(BeEmpty())
-			Expect(goCloak.ProtocolMappers).To(BeNil())
+			Expect(goCloak.ProtocolMappers).NotTo(BeNil())
+			Expect(*goCloak.ProtocolMappers).To(BeEmpty())
 		})
 
 		It("Should handle all string pointers correctly", func() {
@@ -960,6 +2216,196 @@ var _ = Describe("Client Controller", func() {
 		})
 	})
 
+	Context("When detecting drift", func() {
+		var (
+			reconciler *ClientReconciler
+			desired    gocloak.Client
+			live       gocloak.Client
+		)
+
+		BeforeEach(func() {
+			reconciler = &ClientReconciler{ResyncInterval: 10 * time.Minute}
+			desired = reconciler.convertToGoCloak(&keycloakv1.ClientRepresentation{
+				Name:         strPtr("Test Client"),
+				Enabled:      boolPtr(true),
+				RedirectUris: []string{"https://a.example.com/*", "https://b.example.com/*"},
+				Attributes:   map[string]string{"pkce.code.challenge.method": "S256"},
+			}, testClientID, "secret")
+			live = gocloak.Client{
+				ID:           strPtr("internal-id"),
+				ClientID:     strPtr(testClientID),
+				Name:         strPtr("Test Client"),
+				Enabled:      boolPtr(true),
+				PublicClient: boolPtr(false),
+				Secret:       strPtr("**********"),
+				RedirectURIs: &[]string{"https://b.example.com/*", "https://a.example.com/*"},
+				Attributes: &map[string]string{
+					"pkce.code.challenge.method":          "S256",
+					"backchannel.logout.session.required": "true",
+				},
+			}
+		})
+
+		It("Should ignore defaults, list order and unmanaged attributes", func() {
+			Expect(diffClient(desired, live)).To(BeEmpty())
+		})
+
+		It("Should report the fields changed in Keycloak", func() {
+			live.Enabled = boolPtr(false)
+			(*live.Attributes)["pkce.code.challenge.method"] = "plain"
+			*live.RedirectURIs = []string{"https://a.example.com/*"}
+
+			Expect(diffClient(desired, live)).To(Equal([]string{"attributes", "enabled", "redirectUris"}))
+		})
+
+		It("Should report fields missing in Keycloak", func() {
+			live.Name = nil
+			Expect(diffClient(desired, live)).To(Equal([]string{"name"}))
+		})
+
+		It("Should compare the secret unless Keycloak generates or masks it", func() {
+			live.Secret = strPtr("rotated")
+			Expect(diffClient(desired, live)).To(Equal([]string{"secret"}))
+
+			desired.Secret = strPtr("")
+			Expect(diffClient(desired, live)).To(BeEmpty())
+		})
+
+		It("Should only treat differences as drift once the generation was applied", func() {
+			conditions := []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, ObservedGeneration: 1}}
+			Expect(isSynced(conditions, 1)).To(BeTrue())
+			Expect(isSynced(conditions, 2)).To(BeFalse())
+			Expect(isSynced(nil, 1)).To(BeFalse())
+		})
+
+		It("Should prefer the resync interval of the resource", func() {
+			kcClient := &keycloakv1.Client{}
+			Expect(reconciler.resyncInterval(kcClient)).To(Equal(10 * time.Minute))
+			Expect(driftPolicy(kcClient)).To(Equal(keycloakv1.DriftPolicyCorrect))
+
+			kcClient.Spec.SyncPolicy = &keycloakv1.SyncPolicy{
+				ResyncInterval: &metav1.Duration{Duration: time.Minute},
+				DriftPolicy:    keycloakv1.DriftPolicyReport,
+			}
+			Expect(reconciler.resyncInterval(kcClient)).To(Equal(time.Minute))
+			Expect(driftPolicy(kcClient)).To(Equal(keycloakv1.DriftPolicyReport))
+		})
+
+		It("Should record the synced client in the status", func() {
+			conn, err := keycloak.NewConnection(keycloak.Config{URL: "https://sso.example.com", Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+			kcClient := &keycloakv1.Client{Spec: keycloakv1.ClientSpec{Realm: gocloak.StringP("demo")}}
+			kcClient.Generation = 3
+			applied := gocloak.Client{ClientID: gocloak.StringP("app"), Secret: gocloak.StringP("s3cr3t")}
+
+			markSynced(kcClient, conn, applied)
+			Expect(kcClient.Status.Realm).To(Equal("demo"))
+			Expect(kcClient.Status.ClientID).To(Equal("app"))
+			Expect(kcClient.Status.ObservedGeneration).To(Equal(int64(3)))
+			Expect(kcClient.Status.LastSyncedTime).NotTo(BeNil())
+			Expect(kcClient.Status.Issuer).To(Equal("https://sso.example.com/realms/demo"))
+			Expect(kcClient.Status.WellKnownURL).To(Equal("https://sso.example.com/realms/demo/.well-known/openid-configuration"))
+
+			// The secret does not change the hash, the configuration does
+			applied.Secret = gocloak.StringP("rotated")
+			Expect(hashClient(applied)).To(Equal(kcClient.Status.SpecHash))
+			applied.Enabled = gocloak.BoolP(false)
+			Expect(hashClient(applied)).NotTo(Equal(kcClient.Status.SpecHash))
+		})
+	})
+
+	Context("When resyncing a client with Keycloak", func() {
+		It("Should not write the status while the client is up to date", func() {
+			ctx := context.Background()
+			fk := newFakeKeycloak()
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid", Generation: 1},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "app-credentials"},
+					Client:    keycloakv1.ClientRepresentation{ClientID: strPtr("app")},
+				},
+			}
+			c := newFakeCluster(kcClient)
+			reconciler := &ClientReconciler{
+				Client:         c,
+				Scheme:         scheme.Scheme,
+				Recorder:       newFakeRecorder(),
+				Connections:    &ConnectionResolver{Client: c, Default: fk.conn},
+				ResyncInterval: 10 * time.Minute,
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+
+			// The first reconciliations add the finalizer and create the client
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			Expect(meta.FindStatusCondition(kcClient.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+
+			// The next one finds it up to date, then nothing changes anymore
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			Expect(meta.FindStatusCondition(kcClient.Status.Conditions, "Ready").Reason).To(Equal("UpToDate"))
+			synced, writes := kcClient.Status.LastSyncedTime, c.statusWrites
+			fk.reset()
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(c.statusWrites).To(Equal(writes))
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			Expect(kcClient.Status.LastSyncedTime).To(Equal(synced))
+		})
+
+		It("Should delete the last protocol mapper once the list is emptied", func() {
+			ctx := context.Background()
+			fk := newFakeKeycloak()
+			kcClient := &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid", Generation: 1},
+				Spec: keycloakv1.ClientSpec{
+					Realm:     strPtr(testRealm),
+					SecretRef: keycloakv1.ClientSecretReference{Name: "app-credentials"},
+					Client: keycloakv1.ClientRepresentation{
+						ClientID: strPtr("app"),
+						ProtocolMappers: []keycloakv1.ProtocolMapperRepresentation{{
+							Name:           strPtr("team"),
+							ProtocolMapper: strPtr("oidc-hardcoded-claim-mapper"),
+							Config:         map[string]string{"claim.name": "team", "claim.value": "platform"},
+						}},
+					},
+				},
+			}
+			c := newFakeCluster(kcClient)
+			reconciler := &ClientReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: "default"}}
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(fk.get("/admin/realms/test-realm/clients/app-id/protocol-mappers/models/team-id")).NotTo(BeNil())
+
+			Expect(c.Get(ctx, req.NamespacedName, kcClient)).To(Succeed())
+			kcClient.Spec.Client.ProtocolMappers = []keycloakv1.ProtocolMapperRepresentation{}
+			kcClient.Generation = 2
+			Expect(c.Update(ctx, kcClient)).To(Succeed())
+			fk.reset()
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(ContainElement("DELETE /admin/realms/test-realm/clients/app-id/protocol-mappers/models/team-id"))
+			Expect(fk.get("/admin/realms/test-realm/clients/app-id/protocol-mappers/models/team-id")).To(BeNil())
+		})
+	})
+
 	Context("When testing various client configurations", func() {
 		DescribeTable("Should handle different client types",
 			func(clientType string, publicClient bool, standardFlow bool, implicitFlow bool, directAccess bool, serviceAccount bool) {
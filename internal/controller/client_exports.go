@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// errExportNotOwned reports that the export object exists and is not owned by the Client.
+var errExportNotOwned = errors.New("not owned by this Client")
+
+// exportKind returns the kind of object the installation documents are written to.
+func exportKind(exports *keycloakv1.ClientExports) keycloakv1.ExportKind {
+	if exports.Kind == "" {
+		return keycloakv1.ExportKindSecret
+	}
+	return exports.Kind
+}
+
+// exportKey returns the key the document of provider is written to.
+func exportKey(provider keycloakv1.InstallationProvider) string {
+	if provider.Key != "" {
+		return provider.Key
+	}
+	return provider.ID
+}
+
+// splitBinary splits documents into the data and binary data of a ConfigMap, which only holds UTF-8 text in data.
+func splitBinary(documents map[string][]byte) (map[string]string, map[string][]byte) {
+	var data map[string]string
+	var binaryData map[string][]byte
+	for key, document := range documents {
+		if utf8.Valid(document) {
+			if data == nil {
+				data = make(map[string]string)
+			}
+			data[key] = string(document)
+		} else {
+			if binaryData == nil {
+				binaryData = make(map[string][]byte)
+			}
+			binaryData[key] = document
+		}
+	}
+	return data, binaryData
+}
+
+// reconcileExports writes the documents of the installation providers listed in spec.exports to the
+// ConfigMap or Secret owned by kcClient, and reports the outcome in the Exported condition.
+// It returns whether the condition changed.
+func (r *ClientReconciler) reconcileExports(ctx context.Context, conn *keycloak.Connection, token string, kcClient *keycloakv1.Client) (bool, error) {
+	exports := kcClient.Spec.Exports
+	if exports == nil {
+		return meta.RemoveStatusCondition(&kcClient.Status.Conditions, "Exported"), nil
+	}
+
+	kind := exportKind(exports)
+	err := r.writeExports(ctx, conn, token, kcClient)
+	condition := metav1.Condition{
+		Type:               "Exported",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kcClient.Generation,
+		Reason:             "Exported",
+		Message:            fmt.Sprintf("Installation documents written to %s %s", kind, exports.Name),
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ExportFailed"
+		if errors.Is(err, errExportNotOwned) {
+			condition.Reason = "Conflict"
+		}
+		condition.Message = err.Error()
+	}
+	return meta.SetStatusCondition(&kcClient.Status.Conditions, condition), err
+}
+
+// writeExports fetches the installation documents of the client and writes them to the export object.
+func (r *ClientReconciler) writeExports(ctx context.Context, conn *keycloak.Connection, token string, kcClient *keycloakv1.Client) error {
+	logger := logf.FromContext(ctx)
+
+	exports := kcClient.Spec.Exports
+	kind := exportKind(exports)
+	// Replacing the data of the credentials Secret would lose the credentials
+	if kind == keycloakv1.ExportKindSecret && exports.Name == kcClient.Spec.SecretRef.Name {
+		return fmt.Errorf("exports cannot be written to the credentials secret %s", exports.Name)
+	}
+
+	documents := make(map[string][]byte, len(exports.Providers))
+	for _, provider := range exports.Providers {
+		document, err := conn.GetClientInstallation(ctx, token, *kcClient.Spec.Realm, kcClient.Status.ID, provider.ID)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", provider.ID, err)
+		}
+		documents[exportKey(provider)] = document
+	}
+
+	objectMeta := metav1.ObjectMeta{Name: exports.Name, Namespace: kcClient.Namespace}
+	var obj client.Object
+	var setData func()
+	if kind == keycloakv1.ExportKindSecret {
+		secret := &corev1.Secret{ObjectMeta: objectMeta}
+		obj, setData = secret, func() { secret.Data = documents }
+	} else {
+		configMap := &corev1.ConfigMap{ObjectMeta: objectMeta}
+		obj, setData = configMap, func() { configMap.Data, configMap.BinaryData = splitBinary(documents) }
+	}
+
+	// Only the documents currently exported are kept, and nothing is written when they did not change
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		// Never overwrite a ConfigMap or Secret the Client did not create
+		if obj.GetResourceVersion() != "" && !metav1.IsControlledBy(obj, kcClient) {
+			return fmt.Errorf("%s %s exists and is %w", kind, exports.Name, errExportNotOwned)
+		}
+		setData()
+		return controllerutil.SetControllerReference(kcClient, obj, r.Scheme)
+	})
+	if errors.Is(err, errExportNotOwned) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s %s: %w", kind, exports.Name, err)
+	}
+	if result != controllerutil.OperationResultNone {
+		logger.Info("Exported installation documents", "kind", kind, "name", exports.Name, "operation", result)
+	}
+	return nil
+}
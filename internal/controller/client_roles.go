@@ -0,0 +1,406 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// compositeRef references a role included in a composite role, a realm role when clientID is empty.
+type compositeRef struct {
+	clientID string
+	name     string
+}
+
+func (c compositeRef) String() string {
+	if c.clientID == "" {
+		return c.name
+	}
+	return c.clientID + "/" + c.name
+}
+
+// compositePlan lists the roles to include in and exclude from a composite role.
+type compositePlan struct {
+	add    []compositeRef
+	remove []gocloak.Role
+}
+
+// empty reports whether the composites already match.
+func (p compositePlan) empty() bool {
+	return len(p.add) == 0 && len(p.remove) == 0
+}
+
+// clientRolePlan lists the changes that make the roles of a client match the declared ones.
+type clientRolePlan struct {
+	create []gocloak.Role
+	update []gocloak.Role
+	delete []gocloak.Role
+	// composites holds the composite changes by role name
+	composites map[string]compositePlan
+	// roleIDs holds the internal ID of the roles that already exist, by name
+	roleIDs map[string]string
+	// clientIDs holds the internal ID of the clients referenced by composites, by clientId
+	clientIDs map[string]string
+}
+
+// drifted returns the JSON names of the spec fields the plan changes.
+func (p clientRolePlan) drifted() []string {
+	if len(p.create) > 0 || len(p.update) > 0 || len(p.delete) > 0 || len(p.composites) > 0 {
+		return []string{"roles"}
+	}
+	return nil
+}
+
+// planClientRoles matches the declared and live roles of a client by name. Roles missing in Keycloak are
+// created, roles whose description or attributes differ are updated, keeping the attributes and description
+// the spec does not declare, and, when prune is set, roles not declared are deleted. A client declaring no
+// roles leaves those in Keycloak alone.
+func planClientRoles(desired []keycloakv1.ClientRole, live []*gocloak.Role, prune bool) clientRolePlan {
+	plan := clientRolePlan{composites: map[string]compositePlan{}, roleIDs: map[string]string{}, clientIDs: map[string]string{}}
+	if len(desired) == 0 {
+		return plan
+	}
+
+	liveByName := make(map[string]*gocloak.Role, len(live))
+	for _, role := range live {
+		name := gocloak.PString(role.Name)
+		liveByName[name] = role
+		plan.roleIDs[name] = gocloak.PString(role.ID)
+	}
+
+	desiredNames := make(map[string]bool, len(desired))
+	for _, declared := range desired {
+		desiredNames[declared.Name] = true
+		role := gocloak.Role{Name: gocloak.StringP(declared.Name)}
+		if declared.Description != "" {
+			role.Description = gocloak.StringP(declared.Description)
+		}
+		if len(declared.Attributes) > 0 {
+			attributes := maps.Clone(declared.Attributes)
+			role.Attributes = &attributes
+		}
+
+		liveRole, ok := liveByName[declared.Name]
+		switch {
+		case !ok:
+			plan.create = append(plan.create, role)
+		case len(diffFields(role, *liveRole, "id", "name")) > 0:
+			// Keycloak replaces the whole role on update, so what the spec leaves out is sent back as it is
+			updated := *liveRole
+			if role.Description != nil {
+				updated.Description = role.Description
+			}
+			attributes := mergeValues(roleAttributes(liveRole), declared.Attributes)
+			updated.Attributes = &attributes
+			plan.update = append(plan.update, updated)
+		}
+	}
+
+	if prune {
+		for _, role := range live {
+			if !desiredNames[gocloak.PString(role.Name)] {
+				plan.delete = append(plan.delete, *role)
+			}
+		}
+	}
+	return plan
+}
+
+// planComposites compares the declared composites of a role with its live composites. clientIDs maps
+// the clientId of the clients referenced by the declared composites to their internal ID.
+func planComposites(desired *keycloakv1.RoleComposites, live []*gocloak.Role, clientIDs map[string]string) compositePlan {
+	var plan compositePlan
+	if desired == nil {
+		return plan
+	}
+
+	// Live composites only know the internal ID of the client defining them
+	key := func(idOfClient, name string) string { return idOfClient + "/" + name }
+	liveKeys := make(map[string]bool, len(live))
+	for _, role := range live {
+		idOfClient := ""
+		if gocloak.PBool(role.ClientRole) {
+			idOfClient = gocloak.PString(role.ContainerID)
+		}
+		liveKeys[key(idOfClient, gocloak.PString(role.Name))] = true
+	}
+
+	desiredKeys := map[string]bool{}
+	for _, name := range desired.Realm {
+		desiredKeys[key("", name)] = true
+		if !liveKeys[key("", name)] {
+			plan.add = append(plan.add, compositeRef{name: name})
+		}
+	}
+	for _, clientID := range slices.Sorted(maps.Keys(desired.Client)) {
+		for _, name := range desired.Client[clientID] {
+			idOfClient, ok := clientIDs[clientID]
+			if ok {
+				desiredKeys[key(idOfClient, name)] = true
+			}
+			if !ok || !liveKeys[key(idOfClient, name)] {
+				plan.add = append(plan.add, compositeRef{clientID: clientID, name: name})
+			}
+		}
+	}
+
+	for _, role := range live {
+		idOfClient := ""
+		if gocloak.PBool(role.ClientRole) {
+			idOfClient = gocloak.PString(role.ContainerID)
+		}
+		if !desiredKeys[key(idOfClient, gocloak.PString(role.Name))] {
+			plan.remove = append(plan.remove, *role)
+		}
+	}
+	return plan
+}
+
+// fetchClientRoles returns the plan making the roles of the Keycloak client idOfClient, known by
+// clientID, match the spec. The plan is empty when the spec declares no roles.
+func fetchClientRoles(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient, clientID string, spec *keycloakv1.ClientSpec) (clientRolePlan, error) {
+	if len(spec.Roles) == 0 {
+		return planClientRoles(nil, nil, false), nil
+	}
+
+	live, err := gc.GetClientRoles(ctx, token, realm, idOfClient, gocloak.GetRoleParams{BriefRepresentation: gocloak.BoolP(false)})
+	if err != nil {
+		return clientRolePlan{}, fmt.Errorf("failed to list client roles: %w", err)
+	}
+	plan := planClientRoles(spec.Roles, live, spec.RolePrunePolicy == keycloakv1.PrunePolicyPrune)
+
+	plan.clientIDs[clientID] = idOfClient
+	for _, declared := range spec.Roles {
+		if declared.Composites == nil {
+			continue
+		}
+		// Clients missing in the realm are reported when the composites are applied
+		if err := resolveCompositeClients(ctx, gc, token, realm, declared.Composites, plan.clientIDs); err != nil {
+			return clientRolePlan{}, err
+		}
+
+		var liveComposites []*gocloak.Role
+		if roleID := plan.roleIDs[declared.Name]; roleID != "" {
+			if liveComposites, err = gc.GetCompositeRolesByRoleID(ctx, token, realm, roleID); err != nil {
+				return clientRolePlan{}, fmt.Errorf("failed to get composites of role %s: %w", declared.Name, err)
+			}
+		}
+		if composites := planComposites(declared.Composites, liveComposites, plan.clientIDs); !composites.empty() {
+			plan.composites[declared.Name] = composites
+		}
+	}
+	return plan, nil
+}
+
+// resolveCompositeClients adds to clientIDs the internal ID of the clients referenced by composites, by
+// clientId, that it does not hold yet. Clients missing in the realm are left out.
+func resolveCompositeClients(ctx context.Context, gc *gocloak.GoCloak, token, realm string, composites *keycloakv1.RoleComposites, clientIDs map[string]string) error {
+	if composites == nil {
+		return nil
+	}
+	for clientID := range composites.Client {
+		if _, ok := clientIDs[clientID]; ok {
+			continue
+		}
+		clients, err := gc.GetClients(ctx, token, realm, gocloak.GetClientsParams{ClientID: &clientID})
+		if err != nil {
+			return fmt.Errorf("failed to get client %s: %w", clientID, err)
+		}
+		if len(clients) > 0 {
+			clientIDs[clientID] = gocloak.PString(clients[0].ID)
+		}
+	}
+	return nil
+}
+
+// resolveCompositeRoles returns the roles referenced by refs, resolving client roles with the internal
+// client IDs of clientIDs. Nothing is returned but a rolesNotFoundError when a role or client does not exist.
+func resolveCompositeRoles(ctx context.Context, gc *gocloak.GoCloak, token, realm string, refs []compositeRef, clientIDs map[string]string) ([]gocloak.Role, error) {
+	var roles []gocloak.Role
+	var missing []string
+	for _, ref := range refs {
+		var role *gocloak.Role
+		var err error
+		if ref.clientID == "" {
+			role, err = gc.GetRealmRole(ctx, token, realm, ref.name)
+		} else if idOfClient, ok := clientIDs[ref.clientID]; ok {
+			role, err = gc.GetClientRole(ctx, token, realm, idOfClient, ref.name)
+		} else {
+			missing = append(missing, ref.clientID)
+			continue
+		}
+		if keycloak.IsNotFound(err) {
+			missing = append(missing, ref.String())
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get role %s: %w", ref, err)
+		}
+		roles = append(roles, *role)
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, &rolesNotFoundError{names: slices.Compact(missing)}
+	}
+	return roles, nil
+}
+
+// applyClientRoles applies plan to the roles of the Keycloak client idOfClient. The roles included in
+// composites are resolved first, and nothing changes when one of them does not exist, unless it is a role
+// of the client the plan creates.
+func applyClientRoles(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient string, plan clientRolePlan) error {
+	if len(plan.drifted()) == 0 {
+		return nil
+	}
+
+	creating := make(map[string]bool, len(plan.create))
+	for _, role := range plan.create {
+		creating[gocloak.PString(role.Name)] = true
+	}
+	resolve := func(ref compositeRef) (*gocloak.Role, error) {
+		if ref.clientID == "" {
+			return gc.GetRealmRole(ctx, token, realm, ref.name)
+		}
+		return gc.GetClientRole(ctx, token, realm, plan.clientIDs[ref.clientID], ref.name)
+	}
+
+	roleNames := slices.Sorted(maps.Keys(plan.composites))
+	included := make(map[string][]gocloak.Role, len(plan.composites))
+	var deferred []compositeRef
+	var missing []string
+	for _, name := range roleNames {
+		for _, ref := range plan.composites[name].add {
+			idOfReferenced, ok := plan.clientIDs[ref.clientID]
+			switch {
+			case ref.clientID != "" && !ok:
+				missing = append(missing, ref.clientID)
+				continue
+			case ref.clientID != "" && idOfReferenced == idOfClient && creating[ref.name]:
+				// Resolved once created
+				deferred = append(deferred, ref)
+				continue
+			}
+			role, err := resolve(ref)
+			if keycloak.IsNotFound(err) {
+				missing = append(missing, ref.String())
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get role %s: %w", ref, err)
+			}
+			included[name] = append(included[name], *role)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return &rolesNotFoundError{names: slices.Compact(missing)}
+	}
+
+	for _, role := range plan.delete {
+		err := gc.DeleteClientRole(ctx, token, realm, idOfClient, gocloak.PString(role.Name))
+		if err != nil && !keycloak.IsNotFound(err) {
+			return fmt.Errorf("failed to delete role %s: %w", gocloak.PString(role.Name), err)
+		}
+	}
+	for _, role := range plan.update {
+		if err := gc.UpdateRole(ctx, token, realm, idOfClient, role); err != nil {
+			return fmt.Errorf("failed to update role %s: %w", gocloak.PString(role.Name), err)
+		}
+	}
+	roleIDs := maps.Clone(plan.roleIDs)
+	for _, role := range plan.create {
+		name := gocloak.PString(role.Name)
+		if _, err := gc.CreateClientRole(ctx, token, realm, idOfClient, role); err != nil {
+			return fmt.Errorf("failed to create role %s: %w", name, err)
+		}
+		// Keycloak answers with the name of the role rather than its ID
+		created, err := gc.GetClientRole(ctx, token, realm, idOfClient, name)
+		if err != nil {
+			return fmt.Errorf("failed to get created role %s: %w", name, err)
+		}
+		roleIDs[name] = gocloak.PString(created.ID)
+	}
+
+	for _, name := range roleNames {
+		for _, ref := range plan.composites[name].add {
+			if !slices.Contains(deferred, ref) {
+				continue
+			}
+			role, err := resolve(ref)
+			if err != nil {
+				return fmt.Errorf("failed to get role %s: %w", ref, err)
+			}
+			included[name] = append(included[name], *role)
+		}
+
+		if remove := plan.composites[name].remove; len(remove) > 0 {
+			if err := gc.DeleteClientRoleComposite(ctx, token, realm, roleIDs[name], remove); err != nil {
+				return fmt.Errorf("failed to remove composites of role %s: %w", name, err)
+			}
+		}
+		if len(included[name]) > 0 {
+			if err := gc.AddClientRoleComposite(ctx, token, realm, roleIDs[name], included[name]); err != nil {
+				return fmt.Errorf("failed to add composites of role %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// planRoles fetches the roles of the Keycloak client idOfClient and plans their changes, reporting
+// failures in the Ready condition.
+func (r *ClientReconciler) planRoles(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, idOfClient, clientID string) (clientRolePlan, error) {
+	plan, err := fetchClientRoles(ctx, gc, token, *kcClient.Spec.Realm, idOfClient, clientID, &kcClient.Spec)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to get client roles from Keycloak")
+		setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, "ClientRolesFailed", err.Error())
+	}
+	return plan, err
+}
+
+// syncClientRoles applies plan to the roles of the Keycloak client idOfClient and reports failures in the
+// Ready condition, with the CompositeRoleNotFound reason when a role included in a composite does not exist.
+func (r *ClientReconciler) syncClientRoles(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, idOfClient string, plan clientRolePlan) error {
+	logger := logf.FromContext(ctx)
+
+	err := applyClientRoles(ctx, gc, token, *kcClient.Spec.Realm, idOfClient, plan)
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to update client roles in Keycloak")
+	reason := "ClientRolesFailed"
+	var notFound *rolesNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "CompositeRoleNotFound"
+		r.Recorder.Eventf(kcClient, nil, corev1.EventTypeWarning, reason, "UpdateRoles", err.Error())
+	}
+	setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
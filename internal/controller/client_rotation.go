@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// Client attributes holding the rotated secret Keycloak keeps accepting next to the current one.
+const (
+	rotatedSecretAttribute               = "client.secret.rotated"
+	rotatedSecretCreationTimeAttribute   = "client.secret.rotated.creation.time"
+	rotatedSecretExpirationTimeAttribute = "client.secret.rotated.expiration.time"
+)
+
+// previousSecretKey returns the key holding the previous client secret in the referenced Secret.
+func previousSecretKey(kcClient *keycloakv1.Client) string {
+	if kcClient.Spec.SecretRef.PreviousClientSecretKey != "" {
+		return kcClient.Spec.SecretRef.PreviousClientSecretKey
+	}
+	return "previousClientSecret"
+}
+
+// secretGracePeriod returns how long the previous secret stays valid after a rotation.
+func secretGracePeriod(kcClient *keycloakv1.Client) time.Duration {
+	if rotation := kcClient.Spec.SecretRotation; rotation != nil && rotation.GracePeriod != nil {
+		return rotation.GracePeriod.Duration
+	}
+	return 0
+}
+
+// lastSecretRotation returns when the secret was last rotated, or when the resource was created
+// if it never was.
+func lastSecretRotation(kcClient *keycloakv1.Client) time.Time {
+	if kcClient.Status.LastSecretRotationTime != nil {
+		return kcClient.Status.LastSecretRotationTime.Time
+	}
+	return kcClient.CreationTimestamp.Time
+}
+
+// secretRotationDue returns why the client secret must be rotated now, or "" when it need not be.
+func secretRotationDue(kcClient *keycloakv1.Client, now time.Time) string {
+	if request := kcClient.Annotations[keycloakv1.RotateSecretAnnotation]; request != "" && request != kcClient.Status.SecretRotationRequest {
+		return "requested"
+	}
+	rotation := kcClient.Spec.SecretRotation
+	if rotation != nil && rotation.Interval != nil && rotation.Interval.Duration > 0 &&
+		!now.Before(lastSecretRotation(kcClient).Add(rotation.Interval.Duration)) {
+		return "scheduled"
+	}
+	return ""
+}
+
+// previousSecretExpired reports whether the previous secret of the last rotation is no longer accepted by Keycloak.
+func previousSecretExpired(kcClient *keycloakv1.Client, now time.Time) bool {
+	rotatedAt := kcClient.Status.LastSecretRotationTime
+	return rotatedAt != nil && !now.Before(rotatedAt.Add(secretGracePeriod(kcClient)))
+}
+
+// secretRotationRequeue returns how long until the next scheduled rotation or the end of the grace
+// period, whichever comes first, or zero when neither is ahead.
+func secretRotationRequeue(kcClient *keycloakv1.Client, now time.Time) time.Duration {
+	var next time.Duration
+	earliest := func(d time.Duration) {
+		if d > 0 && (next == 0 || d < next) {
+			next = d
+		}
+	}
+	if rotation := kcClient.Spec.SecretRotation; rotation != nil && rotation.Interval != nil && rotation.Interval.Duration > 0 {
+		earliest(lastSecretRotation(kcClient).Add(rotation.Interval.Duration).Sub(now))
+	}
+	if rotatedAt := kcClient.Status.LastSecretRotationTime; rotatedAt != nil {
+		earliest(rotatedAt.Add(secretGracePeriod(kcClient)).Sub(now))
+	}
+	return next
+}
+
+// requeueAfter returns how long to wait before reconciling the client again: the resync interval,
+// brought forward to the next secret rotation event.
+func (r *ClientReconciler) requeueAfter(kcClient *keycloakv1.Client) time.Duration {
+	after := r.resyncInterval(kcClient)
+	if next := secretRotationRequeue(kcClient, time.Now()); next > 0 && (after == 0 || next < after) {
+		after = next
+	}
+	return after
+}
+
+// rotateSecret regenerates the secret of the Keycloak client id, unless Keycloak already did for the rotation
+// started at startedAt, and returns the new secret. previous is the secret in the credentials Secret: once
+// Keycloak holds another one, the secret was regenerated and not written to the Secret yet.
+// During the grace period, Keycloak keeps accepting the previous secret as the rotated secret of the client.
+func (r *ClientReconciler) rotateSecret(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, id string, previous string, startedAt time.Time) (string, error) {
+	realm := *kcClient.Spec.Realm
+
+	credential, err := gc.GetClientSecret(ctx, token, realm, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get client secret: %w", err)
+	}
+	if previous == "" || gocloak.PString(credential.Value) == previous {
+		if credential, err = gc.RegenerateClientSecret(ctx, token, realm, id); err != nil {
+			return "", fmt.Errorf("failed to regenerate client secret: %w", err)
+		}
+	}
+	secret := gocloak.PString(credential.Value)
+	if secret == "" {
+		return "", fmt.Errorf("keycloak returned an empty client secret")
+	}
+
+	grace := secretGracePeriod(kcClient)
+	if grace <= 0 || previous == "" {
+		return secret, nil
+	}
+
+	rotatedClient, err := gc.GetClient(ctx, token, realm, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get rotated client: %w", err)
+	}
+	attributes := maps.Clone(clientAttributes(rotatedClient))
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	attributes[rotatedSecretAttribute] = previous
+	attributes[rotatedSecretCreationTimeAttribute] = strconv.FormatInt(startedAt.Unix(), 10)
+	attributes[rotatedSecretExpirationTimeAttribute] = strconv.FormatInt(startedAt.Add(grace).Unix(), 10)
+	rotatedClient.Attributes = &attributes
+	// Leave the new secret alone
+	rotatedClient.Secret = nil
+	if err := gc.UpdateClient(ctx, token, realm, *rotatedClient); err != nil {
+		return "", fmt.Errorf("failed to keep the previous client secret: %w", err)
+	}
+	return secret, nil
+}
+
+// writeRotatedSecret writes the new client secret to the credentials Secret, along with the previous
+// one while Keycloak still accepts it.
+func (r *ClientReconciler) writeRotatedSecret(ctx context.Context, kcClient *keycloakv1.Client, conn *keycloak.Connection, clientID, secret, previous string) error {
+	content, err := credentialsContent(kcClient, conn, clientID, secret)
+	if err != nil {
+		return err
+	}
+	content.Data[previousSecretKey(kcClient)] = nil
+	if secretGracePeriod(kcClient) > 0 && previous != "" {
+		content.Data[previousSecretKey(kcClient)] = []byte(previous)
+	}
+	return r.writeSecret(ctx, kcClient, content)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// clientScopeNameIndex indexes clients by the names of the default and optional client scopes they reference.
+const clientScopeNameIndex = ".spec.client.clientScopes"
+
+// clientScopePlan lists the client scopes, by name, to assign to and unassign from a client.
+// Keycloak ignores client scopes on client updates, so they are assigned through their own endpoints.
+type clientScopePlan struct {
+	addDefault     []string
+	removeDefault  []string
+	addOptional    []string
+	removeOptional []string
+}
+
+// drifted returns the JSON names of the client fields the plan changes.
+func (p clientScopePlan) drifted() []string {
+	var fields []string
+	if len(p.addDefault) > 0 || len(p.removeDefault) > 0 {
+		fields = append(fields, "defaultClientScopes")
+	}
+	if len(p.addOptional) > 0 || len(p.removeOptional) > 0 {
+		fields = append(fields, "optionalClientScopes")
+	}
+	return fields
+}
+
+// planClientScopes compares the default and optional client scopes of the desired and live clients.
+// A client declaring no default, or no optional, client scopes leaves those in Keycloak alone.
+func planClientScopes(desired, live gocloak.Client) clientScopePlan {
+	var plan clientScopePlan
+	liveDefault := derefStrings(live.DefaultClientScopes)
+	liveOptional := derefStrings(live.OptionalClientScopes)
+	if desiredDefault := derefStrings(desired.DefaultClientScopes); len(desiredDefault) > 0 {
+		plan.addDefault, plan.removeDefault = diffNames(desiredDefault, liveDefault)
+	}
+	if desiredOptional := derefStrings(desired.OptionalClientScopes); len(desiredOptional) > 0 {
+		plan.addOptional, plan.removeOptional = diffNames(desiredOptional, liveOptional)
+	}
+
+	// A scope is either default or optional, it must be unassigned before moving to the other kind
+	for _, name := range plan.addDefault {
+		if slices.Contains(liveOptional, name) && !slices.Contains(plan.removeOptional, name) {
+			plan.removeOptional = append(plan.removeOptional, name)
+		}
+	}
+	for _, name := range plan.addOptional {
+		if slices.Contains(liveDefault, name) && !slices.Contains(plan.removeDefault, name) {
+			plan.removeDefault = append(plan.removeDefault, name)
+		}
+	}
+	return plan
+}
+
+// clientScopesNotFoundError reports client scopes referenced by a client that do not exist in the realm.
+type clientScopesNotFoundError struct {
+	names []string
+}
+
+func (e *clientScopesNotFoundError) Error() string {
+	return fmt.Sprintf("client scopes not found in realm: %s", strings.Join(e.names, ", "))
+}
+
+// applyClientScopes applies plan to the Keycloak client idOfClient. Scope names are resolved in the realm first,
+// and nothing changes when a scope to assign does not exist.
+func applyClientScopes(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient string, plan clientScopePlan) error {
+	if len(plan.drifted()) == 0 {
+		return nil
+	}
+
+	scopes, err := gc.GetClientScopes(ctx, token, realm)
+	if err != nil {
+		return fmt.Errorf("failed to list client scopes: %w", err)
+	}
+	ids := make(map[string]string, len(scopes))
+	for _, scope := range scopes {
+		ids[gocloak.PString(scope.Name)] = gocloak.PString(scope.ID)
+	}
+
+	var missing []string
+	for _, name := range slices.Concat(plan.addDefault, plan.addOptional) {
+		if _, ok := ids[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return &clientScopesNotFoundError{names: missing}
+	}
+
+	steps := []struct {
+		names  []string
+		action string
+		apply  func(ctx context.Context, token, realm, idOfClient, scopeID string) error
+	}{
+		{plan.removeDefault, "unassign default", gc.RemoveDefaultScopeFromClient},
+		{plan.removeOptional, "unassign optional", gc.RemoveOptionalScopeFromClient},
+		{plan.addDefault, "assign default", gc.AddDefaultScopeToClient},
+		{plan.addOptional, "assign optional", gc.AddOptionalScopeToClient},
+	}
+	for _, step := range steps {
+		for _, name := range step.names {
+			id, ok := ids[name]
+			if !ok {
+				// Scopes deleted from the realm are no longer assigned
+				continue
+			}
+			if err := step.apply(ctx, token, realm, idOfClient, id); err != nil && !keycloak.IsNotFound(err) {
+				return fmt.Errorf("failed to %s client scope %s: %w", step.action, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// syncClientScopes applies plan to the Keycloak client idOfClient and reports failures in the Ready
+// condition, with the ClientScopeNotFound reason when a scope does not exist in the realm.
+func (r *ClientReconciler) syncClientScopes(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, idOfClient string, plan clientScopePlan) error {
+	logger := logf.FromContext(ctx)
+
+	err := applyClientScopes(ctx, gc, token, *kcClient.Spec.Realm, idOfClient, plan)
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to assign client scopes in Keycloak")
+	reason := "ClientScopesFailed"
+	var notFound *clientScopesNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "ClientScopeNotFound"
+		r.Recorder.Eventf(kcClient, nil, corev1.EventTypeWarning, reason, "AssignScopes", err.Error())
+	}
+	setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
+
+// referencedClientScopes returns the names of the default and optional client scopes of a client.
+func referencedClientScopes(kcClient *keycloakv1.Client) []string {
+	return slices.Concat(kcClient.Spec.Client.DefaultClientScopes, kcClient.Spec.Client.OptionalClientScopes)
+}
+
+// pendingClientScopes returns the names of the ClientScope resources of the namespace that declare a client
+// scope the client references and are not Ready yet. ClientScope resources whose realm is not known yet
+// are assumed to declare it in the realm of the client.
+func (r *ClientReconciler) pendingClientScopes(ctx context.Context, kcClient *keycloakv1.Client) ([]string, error) {
+	names := referencedClientScopes(kcClient)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var scopes keycloakv1.ClientScopeList
+	if err := r.List(ctx, &scopes, client.InNamespace(kcClient.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ClientScope resources: %w", err)
+	}
+	var pending []string
+	for _, scope := range scopes.Items {
+		realm := scope.Status.Realm
+		if scope.Spec.Realm != nil {
+			realm = *scope.Spec.Realm
+		}
+		if !slices.Contains(names, clientScopeName(&scope)) || (realm != "" && realm != *kcClient.Spec.Realm) {
+			continue
+		}
+		if !meta.IsStatusConditionTrue(scope.Status.Conditions, "Ready") {
+			pending = append(pending, scope.Name)
+		}
+	}
+	return pending, nil
+}
+
+// clientsForClientScope returns the reconcile requests of the clients referencing the client scope of scope.
+func (r *ClientReconciler) clientsForClientScope(ctx context.Context, scope client.Object) []reconcile.Request {
+	name := clientScopeName(scope.(*keycloakv1.ClientScope))
+	var clients keycloakv1.ClientList
+	if err := r.List(ctx, &clients, client.InNamespace(scope.GetNamespace()),
+		client.MatchingFields{clientScopeNameIndex: name}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list clients referencing client scope", "clientScope", name)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clients.Items))
+	for _, kcClient := range clients.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&kcClient)})
+	}
+	return requests
+}
+
+// diffNames returns the names of desired missing in live, and those of live missing in desired.
+func diffNames(desired, live []string) (added, removed []string) {
+	for _, name := range desired {
+		if !slices.Contains(live, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range live {
+		if !slices.Contains(desired, name) {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+// derefStrings returns the strings of a GoCloak string slice pointer.
+func derefStrings(values *[]string) []string {
+	if values == nil {
+		return nil
+	}
+	return *values
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// clientDiffIgnoredFields are the client fields never compared with Keycloak: identifiers, credentials,
+// read-only fields, and fields Keycloak ignores on update and that are reconciled separately.
+var clientDiffIgnoredFields = []string{
+	"id", "secret", "registrationAccessToken", "access", "authorizationSettings",
+	"protocolMappers", "defaultClientScopes", "optionalClientScopes",
+}
+
+// maskedSecret is returned by Keycloak instead of secrets it does not disclose.
+const maskedSecret = "**********"
+
+// diffClient returns the JSON names of the fields of desired that differ in the live Keycloak client.
+// An empty desired secret leaves the secret generated by Keycloak alone.
+func diffClient(desired, live gocloak.Client) []string {
+	drifted := diffFields(desired, live, clientDiffIgnoredFields...)
+
+	desiredSecret := gocloak.PString(desired.Secret)
+	liveSecret := gocloak.PString(live.Secret)
+	if desiredSecret != "" && liveSecret != maskedSecret && desiredSecret != liveSecret {
+		drifted = append(drifted, "secret")
+	}
+	return drifted
+}
+
+// resyncInterval returns how long to wait before comparing the client with Keycloak again.
+// Zero disables periodic resync.
+func (r *ClientReconciler) resyncInterval(kcClient *keycloakv1.Client) time.Duration {
+	return resyncInterval(kcClient.Spec.SyncPolicy, r.ResyncInterval)
+}
+
+// driftPolicy returns how drift detected on the client is handled.
+func driftPolicy(kcClient *keycloakv1.Client) keycloakv1.DriftPolicy {
+	return syncDriftPolicy(kcClient.Spec.SyncPolicy)
+}
+
+// clientAttributes returns the attributes of a Keycloak client, or nil when it has none.
+func clientAttributes(c *gocloak.Client) map[string]string {
+	if c.Attributes == nil {
+		return nil
+	}
+	return *c.Attributes
+}
+
+// markSynced records in the status the Keycloak client the resource is now in sync with.
+func markSynced(kcClient *keycloakv1.Client, conn *keycloak.Connection, applied gocloak.Client) {
+	realm := *kcClient.Spec.Realm
+	now := metav1.Now()
+	kcClient.Status.Realm = realm
+	kcClient.Status.ClientID = gocloak.PString(applied.ClientID)
+	kcClient.Status.ObservedGeneration = kcClient.Generation
+	kcClient.Status.LastSyncedTime = &now
+	kcClient.Status.SpecHash = hashClient(applied)
+	kcClient.Status.Issuer = conn.IssuerURL(realm)
+	kcClient.Status.WellKnownURL = conn.WellKnownURL(realm)
+}
+
+// hashClient returns a hash of the client representation, leaving out its secret.
+func hashClient(c gocloak.Client) string {
+	c.Secret = nil
+	// Marshaling a client cannot fail, and map keys are sorted so the hash is stable
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
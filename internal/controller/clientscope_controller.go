@@ -0,0 +1,413 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const clientScopeFinalizer = "keycloak.pewty.fr/finalizer"
+
+// clientScopeDiffIgnoredFields are the client scope fields never compared as a whole: the internal ID, and the
+// protocol mappers that are matched one by one.
+var clientScopeDiffIgnoredFields = []string{"id", "protocolMappers"}
+
+// ClientScopeReconciler reconciles a ClientScope object
+type ClientScopeReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to client scopes whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often client scopes are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clientscopes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clientscopes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clientscopes/finalizers,verbs=update
+
+// Reconcile makes the Keycloak client scope match the ClientScope resource: it creates the client scope,
+// updates its fields and protocol mappers, assigns it to the realm, and deletes it with the resource
+// unless it must be retained.
+func (r *ClientScopeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var scope keycloakv1.ClientScope
+	if err := r.Get(ctx, req.NamespacedName, &scope); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("ClientScope resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get ClientScope resource")
+		return ctrl.Result{}, err
+	}
+
+	// Take the realm of the referenced Realm resource, only in memory
+	realmReady, err := resolveRealm(ctx, r, &scope, scope.Spec.RealmRef, &scope.Spec.Realm, scope.Status.Realm)
+	if err != nil {
+		logger.Error(err, "Failed to resolve realmRef")
+		setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "InvalidRealm", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if !scope.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &scope)
+	}
+
+	if !controllerutil.ContainsFinalizer(&scope, clientScopeFinalizer) {
+		// Patch the finalizers only, the realm resolved above must not be written to the spec
+		patch := client.MergeFrom(scope.DeepCopy())
+		controllerutil.AddFinalizer(&scope, clientScopeFinalizer)
+		if err := r.Patch(ctx, &scope, patch); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Wait for the referenced Realm, its changes trigger a new reconciliation
+	if !realmReady {
+		message := fmt.Sprintf("Waiting for Realm %s to be Ready", scope.Spec.RealmRef.Name)
+		logger.Info("Realm is not ready, waiting", "realmRef", scope.Spec.RealmRef.Name)
+		setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "RealmNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, &scope, scope.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	realm := *scope.Spec.Realm
+	name := clientScopeName(&scope)
+
+	existing, err := r.findClientScope(ctx, conn, token, &scope)
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak client scopes")
+		setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query client scopes: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	desired := desiredClientScope(&scope)
+	desiredMappers := toProtocolMappers(scope.Spec.ProtocolMappers)
+	if existing == nil {
+		logger.Info("Creating client scope in Keycloak", "realm", realm, "name", name)
+		id, err := conn.CreateClientScope(ctx, token, realm, desired)
+		if err != nil {
+			logger.Error(err, "Failed to create client scope in Keycloak")
+			setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			return ctrl.Result{}, err
+		}
+		// The ID is recorded right away, so that a failure below does not create the client scope again
+		scope.Status.ID = id
+		scope.Status.Realm = realm
+
+		plan := planProtocolMappers(desiredMappers, nil, *desired.Protocol)
+		if err := applyClientScopeMappers(ctx, conn, token, realm, id, plan); err != nil {
+			logger.Error(err, "Failed to create client scope protocol mappers in Keycloak")
+			setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "ProtocolMappersFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		if err := assignRealmClientScope(ctx, conn, token, realm, id, keycloakv1.RealmAssignmentNone, scope.Spec.RealmAssignment); err != nil {
+			logger.Error(err, "Failed to assign client scope to the realm")
+			setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "RealmAssignmentFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully created client scope in Keycloak", "name", name, "id", id)
+
+		markClientScopeSynced(&scope, realm, id, name)
+		setReady(ctx, r.Client, &scope, metav1.ConditionTrue, "Created", "Client scope successfully created in Keycloak")
+		return ctrl.Result{RequeueAfter: resyncInterval(scope.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+	id := gocloak.PString(existing.ID)
+
+	adopting, ok := claimObject(ctx, r.Client, r.Recorder, &scope, scope.Spec.AdoptionPolicy, clientScopeAttributes(existing),
+		"Keycloak client scope "+name)
+	if !ok {
+		return ctrl.Result{RequeueAfter: resyncInterval(scope.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	assignment, err := realmClientScopeAssignment(ctx, conn.Client, token, realm, id)
+	if err != nil {
+		logger.Error(err, "Failed to query the realm client scopes")
+		setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query realm client scopes: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	fieldsDrifted := diffFields(desired, *existing, clientScopeDiffIgnoredFields...)
+	drifted := fieldsDrifted
+	mapperPlan := planProtocolMappers(desiredMappers, derefMappers(existing.ProtocolMappers), *desired.Protocol)
+	if !mapperPlan.empty() {
+		drifted = append(drifted, "protocolMappers")
+	}
+	if scope.Spec.RealmAssignment != "" && scope.Spec.RealmAssignment != assignment {
+		drifted = append(drifted, "realmAssignment")
+	}
+
+	// Differences on a resource already applied at this generation were made in Keycloak directly.
+	// A new name in the spec renames the client scope.
+	renaming := gocloak.PString(existing.Name) != name
+	if len(drifted) > 0 && !adopting && !renaming && isSynced(scope.Status.Conditions, scope.Generation) {
+		if !recordDrift(ctx, r.Recorder, &scope, &scope.Status.Conditions, syncDriftPolicy(scope.Spec.SyncPolicy), drifted) {
+			if err := writeStatus(ctx, r.Client, &scope); err != nil {
+				logger.Error(err, "Failed to update ClientScope status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resyncInterval(scope.Spec.SyncPolicy, r.ResyncInterval)}, nil
+		}
+	} else {
+		clearDrift(&scope.Status.Conditions, scope.Generation)
+	}
+
+	if len(drifted) > 0 {
+		logger.Info("Updating client scope in Keycloak", "name", name, "fields", drifted)
+		if len(fieldsDrifted) > 0 {
+			desired.ID = existing.ID
+			if err := conn.UpdateClientScope(ctx, token, realm, desired); err != nil {
+				logger.Error(err, "Failed to update client scope in Keycloak")
+				setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+		if err := applyClientScopeMappers(ctx, conn, token, realm, id, mapperPlan); err != nil {
+			logger.Error(err, "Failed to update client scope protocol mappers in Keycloak")
+			setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "ProtocolMappersFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		if err := assignRealmClientScope(ctx, conn, token, realm, id, assignment, scope.Spec.RealmAssignment); err != nil {
+			logger.Error(err, "Failed to assign client scope to the realm")
+			setReady(ctx, r.Client, &scope, metav1.ConditionFalse, "RealmAssignmentFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully updated client scope in Keycloak", "name", name)
+	}
+
+	markClientScopeSynced(&scope, realm, id, name)
+	if len(drifted) > 0 {
+		setReady(ctx, r.Client, &scope, metav1.ConditionTrue, "Updated", "Client scope successfully updated in Keycloak")
+	} else {
+		setReady(ctx, r.Client, &scope, metav1.ConditionTrue, "UpToDate", "Client scope is up to date in Keycloak")
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval(scope.Spec.SyncPolicy, r.ResyncInterval)}, nil
+}
+
+// reconcileDelete removes the client scope from Keycloak and releases the finalizer
+func (r *ClientScopeReconciler) reconcileDelete(ctx context.Context, scope *keycloakv1.ClientScope) (ctrl.Result, error) {
+	// Without a realm, the referenced Realm is gone before the client scope was ever synced
+	var cleanup func() error
+	if scope.Spec.Realm != nil {
+		cleanup = func() error { return r.cleanupKeycloak(ctx, scope) }
+	}
+	return finalize(ctx, r.Client, r.Recorder, scope, clientScopeFinalizer, scope.Spec.DeletionPolicy,
+		r.DefaultDeletionPolicy, "Keycloak client scope "+clientScopeName(scope), cleanup)
+}
+
+// cleanupKeycloak deletes the client scope from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *ClientScopeReconciler) cleanupKeycloak(ctx context.Context, scope *keycloakv1.ClientScope) error {
+	conn, token, err := connect(ctx, r.Client, r.Connections, scope, scope.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	return deleteOwned(ctx, r.Client, scope, "Keycloak client scope "+clientScopeName(scope),
+		func() (*keycloak.ClientScope, error) { return r.findClientScope(ctx, conn, token, scope) },
+		clientScopeAttributes,
+		func(existing *keycloak.ClientScope) error {
+			return conn.Client.DeleteClientScope(ctx, token, *scope.Spec.Realm, gocloak.PString(existing.ID))
+		})
+}
+
+// findClientScope looks up the Keycloak client scope of the ClientScope resource, by the ID recorded in the
+// status when it was synced to the same realm, else by name. It returns nil when the client scope or its realm
+// does not exist.
+func (r *ClientScopeReconciler) findClientScope(ctx context.Context, conn *keycloak.Connection, token string, scope *keycloakv1.ClientScope) (*keycloak.ClientScope, error) {
+	realm := *scope.Spec.Realm
+	scopes, err := conn.GetClientScopes(ctx, token, realm)
+	if keycloak.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if scope.Status.ID != "" && scope.Status.Realm == realm {
+		for i := range scopes {
+			if gocloak.PString(scopes[i].ID) == scope.Status.ID {
+				return &scopes[i], nil
+			}
+		}
+	}
+	name := clientScopeName(scope)
+	for i := range scopes {
+		if gocloak.PString(scopes[i].Name) == name {
+			return &scopes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// clientScopeName returns the name of the Keycloak client scope of a ClientScope resource.
+func clientScopeName(scope *keycloakv1.ClientScope) string {
+	if scope.Spec.Name != "" {
+		return scope.Spec.Name
+	}
+	return scope.Name
+}
+
+// clientScopeAttributes returns the attributes of a Keycloak client scope, or nil when it has none.
+func clientScopeAttributes(scope *keycloak.ClientScope) map[string]string {
+	if scope.Attributes == nil {
+		return nil
+	}
+	return *scope.Attributes
+}
+
+// desiredClientScope returns the Keycloak client scope described by the spec, marked as managed by scope.
+// Protocol mappers are applied separately.
+func desiredClientScope(scope *keycloakv1.ClientScope) keycloak.ClientScope {
+	attributes := withOwner(scope.Spec.Attributes, scope)
+	desired := keycloak.ClientScope{
+		Name:        gocloak.StringP(clientScopeName(scope)),
+		Description: scope.Spec.Description,
+		Attributes:  &attributes,
+	}
+	// The protocol is defaulted by the API server, Keycloak defaults it to OpenID Connect too
+	if desired.Protocol = gocloak.StringP(scope.Spec.Protocol); scope.Spec.Protocol == "" {
+		desired.Protocol = gocloak.StringP("openid-connect")
+	}
+	return desired
+}
+
+// applyClientScopeMappers applies plan to the protocol mappers of the Keycloak client scope scopeID.
+// Mappers are deleted first so that a mapper can be replaced by another one of the same name.
+func applyClientScopeMappers(ctx context.Context, conn *keycloak.Connection, token, realm, scopeID string, plan protocolMapperPlan) error {
+	for _, mapper := range plan.delete {
+		err := conn.Client.DeleteClientScopeProtocolMapper(ctx, token, realm, scopeID, gocloak.PString(mapper.ID))
+		if err != nil && !keycloak.IsNotFound(err) {
+			return fmt.Errorf("failed to delete protocol mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	for _, mapper := range plan.update {
+		if err := conn.UpdateClientScopeProtocolMapper(ctx, token, realm, scopeID, mapper); err != nil {
+			return fmt.Errorf("failed to update protocol mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	for _, mapper := range plan.create {
+		if err := conn.CreateClientScopeProtocolMapper(ctx, token, realm, scopeID, mapper); err != nil {
+			return fmt.Errorf("failed to create protocol mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	return nil
+}
+
+// realmClientScopeAssignment returns how realm assigns the client scope scopeID to the clients created in it.
+func realmClientScopeAssignment(ctx context.Context, gc *gocloak.GoCloak, token, realm, scopeID string) (keycloakv1.RealmAssignment, error) {
+	defaults, err := gc.GetDefaultDefaultClientScopes(ctx, token, realm)
+	if err != nil {
+		return "", fmt.Errorf("failed to list default client scopes: %w", err)
+	}
+	for _, scope := range defaults {
+		if gocloak.PString(scope.ID) == scopeID {
+			return keycloakv1.RealmAssignmentDefault, nil
+		}
+	}
+	optionals, err := gc.GetDefaultOptionalClientScopes(ctx, token, realm)
+	if err != nil {
+		return "", fmt.Errorf("failed to list optional client scopes: %w", err)
+	}
+	for _, scope := range optionals {
+		if gocloak.PString(scope.ID) == scopeID {
+			return keycloakv1.RealmAssignmentOptional, nil
+		}
+	}
+	return keycloakv1.RealmAssignmentNone, nil
+}
+
+// assignRealmClientScope moves the client scope scopeID from its current realm assignment to the desired one.
+// An empty desired assignment leaves it alone.
+func assignRealmClientScope(ctx context.Context, conn *keycloak.Connection, token, realm, scopeID string, current, desired keycloakv1.RealmAssignment) error {
+	if desired == "" || desired == current {
+		return nil
+	}
+	kinds := map[keycloakv1.RealmAssignment]string{
+		keycloakv1.RealmAssignmentDefault:  keycloak.RealmDefaultClientScopes,
+		keycloakv1.RealmAssignmentOptional: keycloak.RealmOptionalClientScopes,
+	}
+	if kind, ok := kinds[current]; ok {
+		if err := conn.RemoveRealmClientScope(ctx, token, realm, kind, scopeID); err != nil && !keycloak.IsNotFound(err) {
+			return fmt.Errorf("failed to unassign client scope from the realm: %w", err)
+		}
+	}
+	if kind, ok := kinds[desired]; ok {
+		if err := conn.AddRealmClientScope(ctx, token, realm, kind, scopeID); err != nil {
+			return fmt.Errorf("failed to assign client scope to the realm: %w", err)
+		}
+	}
+	return nil
+}
+
+// derefMappers returns the protocol mappers of a GoCloak protocol mapper slice pointer.
+func derefMappers(mappers *[]gocloak.ProtocolMapperRepresentation) []gocloak.ProtocolMapperRepresentation {
+	if mappers == nil {
+		return nil
+	}
+	return *mappers
+}
+
+// markClientScopeSynced records in the status the Keycloak client scope the resource is now in sync with.
+func markClientScopeSynced(scope *keycloakv1.ClientScope, realm, id, name string) {
+	now := metav1.Now()
+	scope.Status.ID = id
+	scope.Status.Realm = realm
+	scope.Status.Name = name
+	scope.Status.ObservedGeneration = scope.Generation
+	scope.Status.LastSyncedTime = &now
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClientScopeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexRealmRef(mgr, &keycloakv1.ClientScope{}, func(obj client.Object) *keycloakv1.RealmReference {
+		return obj.(*keycloakv1.ClientScope).Spec.RealmRef
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.ClientScope{}, ignoreStatusUpdates).
+		Watches(&keycloakv1.Realm{}, handler.EnqueueRequestsFromMapFunc(requestsForRealm(r.Client, &keycloakv1.ClientScopeList{})),
+			realmReadinessChanged).
+		Named("clientscope").
+		Complete(r)
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("ClientScope Controller", func() {
+	Context("When converting a ClientScope to a Keycloak client scope", func() {
+		It("Should map the spec, mark ownership and default the name and protocol", func() {
+			scope := &keycloakv1.ClientScope{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default", UID: "scope-uid"},
+				Spec: keycloakv1.ClientScopeSpec{
+					Description: strPtr("Tenant of the user"),
+					Attributes:  map[string]string{"include.in.token.scope": "true"},
+				},
+			}
+			desired := desiredClientScope(scope)
+			Expect(*desired.Name).To(Equal("tenant"))
+			Expect(*desired.Protocol).To(Equal("openid-connect"))
+			Expect(*desired.Description).To(Equal("Tenant of the user"))
+			Expect(*desired.Attributes).To(HaveKeyWithValue("include.in.token.scope", "true"))
+			Expect(*desired.Attributes).To(HaveKeyWithValue(ownerUIDAttribute, "scope-uid"))
+
+			scope.Spec.Name = "organization"
+			scope.Spec.Protocol = "saml"
+			desired = desiredClientScope(scope)
+			Expect(*desired.Name).To(Equal("organization"))
+			Expect(*desired.Protocol).To(Equal("saml"))
+		})
+	})
+
+	Context("When talking to Keycloak", func() {
+		var (
+			server   *httptest.Server
+			conn     *keycloak.Connection
+			requests []string
+		)
+
+		BeforeEach(func() {
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /admin/realms/demo/client-scopes":
+					_, _ = fmt.Fprint(w, `[{"id":"old-id","name":"old-name"},{"id":"tenant-id","name":"tenant"}]`)
+				case "GET /admin/realms/demo/default-default-client-scopes":
+					_, _ = fmt.Fprint(w, `[{"id":"profile-id","name":"profile"}]`)
+				case "GET /admin/realms/demo/default-optional-client-scopes":
+					_, _ = fmt.Fprint(w, `[{"id":"tenant-id","name":"tenant"}]`)
+				default:
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newScope := func(name, id, realm string) *keycloakv1.ClientScope {
+			return &keycloakv1.ClientScope{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       keycloakv1.ClientScopeSpec{Realm: strPtr("demo")},
+				Status:     keycloakv1.ClientScopeStatus{ID: id, Realm: realm},
+			}
+		}
+
+		It("Should prefer the ID recorded in the status, to rename the client scope", func() {
+			found, err := (&ClientScopeReconciler{}).findClientScope(context.Background(), conn, "token", newScope("tenant", "old-id", "demo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*found.ID).To(Equal("old-id"))
+		})
+
+		It("Should look up the client scope by name in another realm or when it is gone", func() {
+			found, err := (&ClientScopeReconciler{}).findClientScope(context.Background(), conn, "token", newScope("tenant", "old-id", "other"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*found.ID).To(Equal("tenant-id"))
+
+			found, err = (&ClientScopeReconciler{}).findClientScope(context.Background(), conn, "token", newScope("unknown", "deleted-id", "demo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+
+		It("Should move the client scope between the realm default and optional scopes", func() {
+			assignment, err := realmClientScopeAssignment(context.Background(), conn.Client, "token", "demo", "tenant-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(assignment).To(Equal(keycloakv1.RealmAssignmentOptional))
+
+			requests = nil
+			Expect(assignRealmClientScope(context.Background(), conn, "token", "demo", "tenant-id",
+				assignment, keycloakv1.RealmAssignmentDefault)).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"DELETE /admin/realms/demo/default-optional-client-scopes/tenant-id",
+				"PUT /admin/realms/demo/default-default-client-scopes/tenant-id",
+			}))
+
+			requests = nil
+			Expect(assignRealmClientScope(context.Background(), conn, "token", "demo", "tenant-id", assignment, "")).To(Succeed())
+			Expect(assignRealmClientScope(context.Background(), conn, "token", "demo", "tenant-id",
+				keycloakv1.RealmAssignmentNone, keycloakv1.RealmAssignmentNone)).To(Succeed())
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("Should apply the protocol mapper plan to the client scope", func() {
+			desired := []gocloak.ProtocolMapperRepresentation{{Name: gocloak.StringP("tenant")}}
+			live := []gocloak.ProtocolMapperRepresentation{{ID: gocloak.StringP("legacy-id"), Name: gocloak.StringP("legacy")}}
+			plan := planProtocolMappers(desired, live, "openid-connect")
+			Expect(applyClientScopeMappers(context.Background(), conn, "token", "demo", "tenant-id", plan)).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"DELETE /admin/realms/demo/client-scopes/tenant-id/protocol-mappers/models/legacy-id",
+				"POST /admin/realms/demo/client-scopes/tenant-id/protocol-mappers/models",
+			}))
+		})
+
+		It("Should delete the last protocol mapper once the list is emptied", func() {
+			live := []gocloak.ProtocolMapperRepresentation{{ID: gocloak.StringP("legacy-id"), Name: gocloak.StringP("legacy")}}
+			Expect(planProtocolMappers(toProtocolMappers(nil), live, "openid-connect").empty()).To(BeTrue())
+
+			plan := planProtocolMappers(toProtocolMappers([]keycloakv1.ProtocolMapperRepresentation{}), live, "openid-connect")
+			Expect(applyClientScopeMappers(context.Background(), conn, "token", "demo", "tenant-id", plan)).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"DELETE /admin/realms/demo/client-scopes/tenant-id/protocol-mappers/models/legacy-id",
+			}))
+		})
+	})
+
+	Context("When reconciling a ClientScope resource", func() {
+		It("Should reject a ClientScope without realm", func() {
+			resource := &keycloakv1.ClientScope{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-scope-without-realm", Namespace: "default"},
+			}
+			Expect(k8sClient.Create(context.Background(), resource)).To(MatchError(ContainSubstring("realm or realmRef is required")))
+		})
+	})
+
+	Context("When syncing a ClientScope with Keycloak", func() {
+		const scopesPath = "/admin/realms/test-realm/client-scopes"
+		var (
+			fk         *fakeKeycloak
+			c          *fakeCluster
+			reconciler *ClientScopeReconciler
+			req        ctrl.Request
+		)
+
+		// reconcile runs the reconciler the given number of times, and returns the ClientScope resource
+		reconcile := func(times int) *keycloakv1.ClientScope {
+			for range times {
+				_, err := reconciler.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			scope := &keycloakv1.ClientScope{}
+			Expect(c.Get(context.Background(), req.NamespacedName, scope)).To(Succeed())
+			return scope
+		}
+
+		setup := func(scope *keycloakv1.ClientScope) {
+			fk = newFakeKeycloak()
+			scope.Namespace = "default"
+			scope.UID = "tenant-uid"
+			scope.Generation = 1
+			scope.Spec.Realm = strPtr("test-realm")
+			c = newFakeCluster(scope)
+			reconciler = &ClientScopeReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: scope.Name, Namespace: "default"}}
+		}
+
+		It("Should create the client scope, update it with the spec and delete it with the resource", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.ClientScope{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+				Spec:       keycloakv1.ClientScopeSpec{RealmAssignment: keycloakv1.RealmAssignmentDefault},
+			})
+
+			scope := reconcile(2)
+			Expect(meta.FindStatusCondition(scope.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(scope.Status.ID).To(Equal("tenant-id"))
+			Expect(fk.recorded()).To(Equal([]string{
+				"POST " + scopesPath,
+				"PUT /admin/realms/test-realm/default-default-client-scopes/tenant-id",
+			}))
+			Expect(fk.get(scopesPath + "/tenant-id")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, "tenant-uid"))
+
+			fk.reset()
+			scope.Spec.Description = strPtr("Tenant of the user")
+			scope.Spec.RealmAssignment = keycloakv1.RealmAssignmentOptional
+			scope.Generation = 2
+			Expect(c.Update(ctx, scope)).To(Succeed())
+			scope = reconcile(1)
+			Expect(meta.FindStatusCondition(scope.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{
+				"PUT " + scopesPath + "/tenant-id",
+				"DELETE /admin/realms/test-realm/default-default-client-scopes/tenant-id",
+				"PUT /admin/realms/test-realm/default-optional-client-scopes/tenant-id",
+			}))
+			Expect(fk.get(scopesPath + "/tenant-id")).To(HaveKeyWithValue("description", "Tenant of the user"))
+
+			fk.reset()
+			Expect(c.Delete(ctx, scope)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(Equal([]string{"DELETE " + scopesPath + "/tenant-id"}))
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, scope))).To(BeTrue())
+
+			// The resource is gone, there is nothing left to reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should keep the client scope in Keycloak when the resource retains it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.ClientScope{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+				Spec:       keycloakv1.ClientScopeSpec{DeletionPolicy: keycloakv1.DeletionPolicyRetain},
+			})
+			scope := reconcile(2)
+
+			fk.reset()
+			Expect(c.Delete(ctx, scope)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(fk.get(scopesPath + "/tenant-id")).NotTo(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, scope))).To(BeTrue())
+		})
+
+		It("Should release a client scope whose realm was deleted from Keycloak first", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.ClientScope{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}})
+			fk.put("/admin/realms/test-realm", `{"id":"test-realm-id","realm":"test-realm"}`)
+			scope := reconcile(2)
+
+			// The Realm resource deleted with the namespace takes its client scopes with it
+			Expect(fk.conn.Client.DeleteRealm(ctx, "token", "test-realm")).To(Succeed())
+			fk.reset()
+			Expect(c.Delete(ctx, scope)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, scope))).To(BeTrue())
+		})
+
+		DescribeTable("Should handle the fields changed in Keycloak according to the drift policy",
+			func(policy keycloakv1.DriftPolicy, reason string, description string) {
+				setup(&keycloakv1.ClientScope{
+					ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+					Spec: keycloakv1.ClientScopeSpec{
+						Description: strPtr("Tenant of the user"),
+						SyncPolicy:  &keycloakv1.SyncPolicy{DriftPolicy: policy},
+					},
+				})
+				reconcile(2)
+
+				fk.get(scopesPath + "/tenant-id")["description"] = "Changed in Keycloak"
+				scope := reconcile(1)
+				drifted := meta.FindStatusCondition(scope.Status.Conditions, "Drifted")
+				Expect(drifted.Reason).To(Equal(reason))
+				Expect(drifted.Message).To(ContainSubstring("description"))
+				Expect(fk.get(scopesPath + "/tenant-id")).To(HaveKeyWithValue("description", description))
+			},
+			Entry("Correct by default", keycloakv1.DriftPolicy(""), "DriftCorrected", "Tenant of the user"),
+			Entry("Report", keycloakv1.DriftPolicyReport, "DriftDetected", "Changed in Keycloak"),
+		)
+
+		It("Should refuse a client scope it did not create until asked to adopt it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.ClientScope{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}})
+			fk.put(scopesPath+"/tenant-id", `{"id":"tenant-id","name":"tenant","protocol":"openid-connect"}`)
+
+			scope := reconcile(2)
+			Expect(meta.FindStatusCondition(scope.Status.Conditions, "Ready").Reason).To(Equal("Conflict"))
+			Expect(meta.FindStatusCondition(scope.Status.Conditions, "Conflict").Message).To(Equal(
+				"Keycloak client scope tenant is not created by this resource, set adoptionPolicy to IfUnowned to adopt it"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			scope.Spec.AdoptionPolicy = keycloakv1.AdoptionPolicyIfUnowned
+			scope.Generation = 2
+			Expect(c.Update(ctx, scope)).To(Succeed())
+			scope = reconcile(1)
+			Expect(meta.FindStatusCondition(scope.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(meta.IsStatusConditionFalse(scope.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(fk.get(scopesPath + "/tenant-id")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, "tenant-uid"))
+		})
+	})
+
+	Context("When a Client references a ClientScope", func() {
+		ctx := context.Background()
+		const scopeResourceName = "test-referenced-scope"
+
+		AfterEach(func() {
+			scope := &keycloakv1.ClientScope{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: scopeResourceName, Namespace: "default"}, scope); err == nil {
+				Expect(k8sClient.Delete(ctx, scope)).To(Succeed())
+			}
+		})
+
+		newClient := func() *keycloakv1.Client {
+			return &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-scope-client", Namespace: "default"},
+				Spec: keycloakv1.ClientSpec{
+					Realm:  strPtr("test-realm"),
+					Client: keycloakv1.ClientRepresentation{OptionalClientScopes: []string{"tenant", "email"}},
+				},
+			}
+		}
+
+		It("Should wait for the ClientScope declaring the scope to be Ready", func() {
+			reconciler := &ClientReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			pending, err := reconciler.pendingClientScopes(ctx, newClient())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+
+			scope := &keycloakv1.ClientScope{
+				ObjectMeta: metav1.ObjectMeta{Name: scopeResourceName, Namespace: "default"},
+				Spec:       keycloakv1.ClientScopeSpec{Realm: strPtr("test-realm"), Name: "tenant"},
+			}
+			Expect(k8sClient.Create(ctx, scope)).To(Succeed())
+			pending, err = reconciler.pendingClientScopes(ctx, newClient())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(Equal([]string{scopeResourceName}))
+
+			kcClient := newClient()
+			kcClient.Spec.Realm = strPtr("other-realm")
+			pending, err = reconciler.pendingClientScopes(ctx, kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+
+			meta.SetStatusCondition(&scope.Status.Conditions, metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "Created", Message: "Client scope created",
+			})
+			Expect(k8sClient.Status().Update(ctx, scope)).To(Succeed())
+			pending, err = reconciler.pendingClientScopes(ctx, newClient())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+	})
+})
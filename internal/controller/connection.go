@@ -0,0 +1,239 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const (
+	kindKeycloakConnection        = "KeycloakConnection"
+	kindClusterKeycloakConnection = "ClusterKeycloakConnection"
+)
+
+// connectionSecretIndex indexes connection resources by the Secrets they read.
+const connectionSecretIndex = ".spec.secretRefs"
+
+// ConnectionResolver resolves the Keycloak connection a resource points at.
+// Connections built from KeycloakConnection and ClusterKeycloakConnection resources are cached
+// until the resource or its credentials Secret changes.
+type ConnectionResolver struct {
+	client.Client
+	// Default is used by resources without a connectionRef. It is nil when no
+	// operator-wide connection is configured.
+	Default *keycloak.Connection
+	Cache   *keycloak.ConnectionCache
+}
+
+// Resolve returns the connection referenced by ref, or the default connection when ref is nil.
+// namespace is the namespace of the resource holding the reference.
+func (r *ConnectionResolver) Resolve(ctx context.Context, namespace string, ref *keycloakv1.ConnectionReference) (*keycloak.Connection, error) {
+	if ref == nil {
+		if r.Default == nil {
+			return nil, fmt.Errorf("connectionRef is required: no default Keycloak connection is configured")
+		}
+		return r.Default, nil
+	}
+
+	switch ref.Kind {
+	case "", kindKeycloakConnection:
+		var conn keycloakv1.KeycloakConnection
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, &conn); err != nil {
+			return nil, fmt.Errorf("failed to get KeycloakConnection %s: %w", ref.Name, err)
+		}
+		return r.fromSpec(ctx, connectionKey(kindKeycloakConnection, namespace, ref.Name), conn.Generation, &conn.Spec, namespace)
+	case kindClusterKeycloakConnection:
+		var conn keycloakv1.ClusterKeycloakConnection
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name}, &conn); err != nil {
+			return nil, fmt.Errorf("failed to get ClusterKeycloakConnection %s: %w", ref.Name, err)
+		}
+		if conn.Spec.CredentialsSecretRef.Namespace == "" {
+			return nil, fmt.Errorf("credentialsSecretRef.namespace is required for ClusterKeycloakConnection %s", ref.Name)
+		}
+		return r.fromSpec(ctx, connectionKey(kindClusterKeycloakConnection, "", ref.Name), conn.Generation, &conn.Spec,
+			conn.Spec.CredentialsSecretRef.Namespace)
+	default:
+		return nil, fmt.Errorf("unsupported connection kind %q", ref.Kind)
+	}
+}
+
+// Forget drops the cached connection built for the given connection resource.
+func (r *ConnectionResolver) Forget(kind, namespace, name string) {
+	r.Cache.Delete(connectionKey(kind, namespace, name))
+}
+
+// fromSpec reads the credentials Secret and returns the cached connection for spec,
+// rebuilding it when the spec generation or the Secret changed.
+func (r *ConnectionResolver) fromSpec(ctx context.Context, key string, generation int64, spec *keycloakv1.KeycloakConnectionSpec, secretNamespace string) (*keycloak.Connection, error) {
+	// Default keys
+	usernameKey := "username"
+	passwordKey := "password"
+	clientIDKey := "clientId"
+	clientSecretKey := "clientSecret"
+	privateKeyKey := "privateKey"
+
+	if spec.CredentialsSecretRef.UsernameKey != "" {
+		usernameKey = spec.CredentialsSecretRef.UsernameKey
+	}
+	if spec.CredentialsSecretRef.PasswordKey != "" {
+		passwordKey = spec.CredentialsSecretRef.PasswordKey
+	}
+	if spec.CredentialsSecretRef.ClientIDKey != "" {
+		clientIDKey = spec.CredentialsSecretRef.ClientIDKey
+	}
+	if spec.CredentialsSecretRef.ClientSecretKey != "" {
+		clientSecretKey = spec.CredentialsSecretRef.ClientSecretKey
+	}
+	if spec.CredentialsSecretRef.PrivateKeyKey != "" {
+		privateKeyKey = spec.CredentialsSecretRef.PrivateKeyKey
+	}
+
+	secret := &corev1.Secret{}
+	secretName := types.NamespacedName{
+		Name:      spec.CredentialsSecretRef.Name,
+		Namespace: secretNamespace,
+	}
+	if err := r.Get(ctx, secretName, secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret %s: %w", secretName, err)
+	}
+
+	cfg := keycloak.Config{
+		Name:         key,
+		URL:          spec.URL,
+		Realm:        spec.Realm,
+		AuthMethod:   keycloak.AuthMethod(spec.AuthMethod),
+		Username:     string(secret.Data[usernameKey]),
+		Password:     string(secret.Data[passwordKey]),
+		ClientID:     string(secret.Data[clientIDKey]),
+		ClientSecret: string(secret.Data[clientSecretKey]),
+		PrivateKey:   secret.Data[privateKeyKey],
+		CABundle:     []byte(spec.CABundle),
+		ProxyURL:     spec.ProxyURL,
+	}
+	if spec.Timeout != nil {
+		cfg.Timeout = spec.Timeout.Duration
+	}
+
+	// The cached connection is rebuilt whenever the spec or one of the Secrets it reads changes
+	version := fmt.Sprintf("%d/%s", generation, secret.ResourceVersion)
+	if spec.TLS != nil {
+		tlsVersion, err := r.readTLS(ctx, spec.TLS, secretNamespace, &cfg)
+		if err != nil {
+			return nil, err
+		}
+		version += tlsVersion
+	}
+
+	return r.Cache.Get(key, version, func() (*keycloak.Connection, error) {
+		return keycloak.NewConnection(cfg)
+	})
+}
+
+// readTLS reads the CA bundle and client certificate Secrets referenced by spec into cfg.
+// It returns the resource versions of the Secrets read, to detect changes.
+func (r *ConnectionResolver) readTLS(ctx context.Context, spec *keycloakv1.ConnectionTLS, namespace string, cfg *keycloak.Config) (string, error) {
+	var version string
+
+	if ref := spec.CASecretRef; ref != nil {
+		key := ref.Key
+		if key == "" {
+			key = "ca.crt"
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+			return "", fmt.Errorf("failed to get CA secret %s/%s: %w", namespace, ref.Name, err)
+		}
+		caBundle, ok := secret.Data[key]
+		if !ok {
+			return "", fmt.Errorf("key %s not found in CA secret %s/%s", key, namespace, ref.Name)
+		}
+		cfg.CABundle = append(append(cfg.CABundle, '\n'), caBundle...)
+		version += "/" + secret.ResourceVersion
+	}
+
+	if ref := spec.ClientCertificateSecretRef; ref != nil {
+		certKey := corev1.TLSCertKey
+		if ref.CertificateKey != "" {
+			certKey = ref.CertificateKey
+		}
+		privateKeyKey := corev1.TLSPrivateKeyKey
+		if ref.PrivateKeyKey != "" {
+			privateKeyKey = ref.PrivateKeyKey
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+			return "", fmt.Errorf("failed to get client certificate secret %s/%s: %w", namespace, ref.Name, err)
+		}
+		cfg.ClientCertificate = secret.Data[certKey]
+		cfg.ClientKey = secret.Data[privateKeyKey]
+		version += "/" + secret.ResourceVersion
+	}
+
+	return version, nil
+}
+
+// connect resolves the Keycloak connection ref of obj and returns it with a valid admin access token. Failures
+// are recorded in the Ready condition of obj. Tokens are cached by the connection, so this only reaches Keycloak
+// when the token must be renewed.
+func connect(ctx context.Context, c client.Client, connections *ConnectionResolver, obj keycloakResource,
+	ref *keycloakv1.ConnectionReference) (*keycloak.Connection, string, error) {
+	logger := logf.FromContext(ctx)
+
+	conn, err := connections.Resolve(ctx, obj.GetNamespace(), ref)
+	if err != nil {
+		logger.Error(err, "Failed to resolve Keycloak connection")
+		setReady(ctx, c, obj, metav1.ConditionFalse, "ConnectionFailed", fmt.Sprintf("Failed to resolve connection: %v", err))
+		return nil, "", err
+	}
+
+	token, err := conn.Token(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to authenticate with Keycloak")
+		setReady(ctx, c, obj, metav1.ConditionFalse, "AuthenticationFailed", fmt.Sprintf("Failed to authenticate: %v", err))
+		return nil, "", err
+	}
+
+	return conn, token, nil
+}
+
+// connectionSecrets returns the names of the Secrets read to build a connection from spec: the credentials,
+// CA bundle and client certificate Secrets.
+func connectionSecrets(spec *keycloakv1.KeycloakConnectionSpec) []string {
+	names := []string{spec.CredentialsSecretRef.Name}
+	if spec.TLS != nil && spec.TLS.CASecretRef != nil {
+		names = append(names, spec.TLS.CASecretRef.Name)
+	}
+	if spec.TLS != nil && spec.TLS.ClientCertificateSecretRef != nil {
+		names = append(names, spec.TLS.ClientCertificateSecretRef.Name)
+	}
+	return names
+}
+
+// connectionKey identifies a connection resource in the connection cache.
+func connectionKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
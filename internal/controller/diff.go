@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// diffFields compares the pointer fields of two structs of the same type and returns the JSON names
+// of the fields set in desired whose value differs in live, in declaration order.
+//
+// The comparison is semantic rather than strict, because Keycloak returns defaults for everything
+// the operator does not manage: unset and empty fields of desired are ignored, string lists are
+// compared regardless of order, and maps only compare the keys set in desired.
+func diffFields(desired, live any, ignored ...string) []string {
+	desiredValue := reflect.Indirect(reflect.ValueOf(desired))
+	liveValue := reflect.Indirect(reflect.ValueOf(live))
+
+	var drifted []string
+	for i := range desiredValue.NumField() {
+		field := desiredValue.Type().Field(i)
+		name := jsonName(field)
+		if name == "" || slices.Contains(ignored, name) {
+			continue
+		}
+		if !fieldEqual(desiredValue.Field(i), liveValue.Field(i)) {
+			drifted = append(drifted, name)
+		}
+	}
+	return drifted
+}
+
+// fieldEqual reports whether live matches the desired value of a field.
+func fieldEqual(desired, live reflect.Value) bool {
+	desired = reflect.Indirect(desired)
+	live = reflect.Indirect(live)
+	if !desired.IsValid() {
+		return true
+	}
+
+	switch desired.Kind() {
+	case reflect.Slice:
+		if desired.Len() == 0 {
+			return true
+		}
+		if !live.IsValid() || desired.Len() != live.Len() {
+			return false
+		}
+		if desired.Type().Elem().Kind() == reflect.String {
+			return slices.Equal(sortedStrings(desired), sortedStrings(live))
+		}
+		return reflect.DeepEqual(desired.Interface(), live.Interface())
+	case reflect.Map:
+		if desired.Len() == 0 {
+			return true
+		}
+		if !live.IsValid() {
+			return false
+		}
+		iter := desired.MapRange()
+		for iter.Next() {
+			liveEntry := live.MapIndex(iter.Key())
+			if !liveEntry.IsValid() || !reflect.DeepEqual(iter.Value().Interface(), liveEntry.Interface()) {
+				return false
+			}
+		}
+		return true
+	default:
+		return live.IsValid() && reflect.DeepEqual(desired.Interface(), live.Interface())
+	}
+}
+
+// sortedStrings returns a sorted copy of a string slice value.
+func sortedStrings(v reflect.Value) []string {
+	values := make([]string, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).String()
+	}
+	slices.Sort(values)
+	return values
+}
+
+// jsonName returns the JSON name of a struct field, or an empty string when it is not serialized.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,432 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// fakeCollections are the last path segments of the Keycloak admin API listing representations.
+var fakeCollections = []string{
+	"realms", "clients", "client-scopes", "roles", "composites", "realm", "groups", "children", "users",
+	"instances", "mappers", "models", "default-default-client-scopes", "default-optional-client-scopes",
+}
+
+// fakeGroupParent matches the paths groups are POSTed to: the groups of a realm, or the children of a group.
+var fakeGroupParent = regexp.MustCompile(`^(/admin/realms/[^/]+/groups)(?:/([^/]+)/children)?$`)
+
+// fakeRealm matches the path of the realm a path of the admin API is in.
+var fakeRealm = regexp.MustCompile(`^/admin/realms/[^/]+`)
+
+// fakeKeys are the fields naming the representations POSTed to a collection in their path, the others
+// are stored under a generated ID.
+var fakeKeys = map[string]string{"realms": "realm", "roles": "name", "instances": "alias"}
+
+// fakeKeycloak is an in-memory Keycloak admin API for reconcile tests. Representations are stored under the
+// path they are read from: POST stores the body in a collection, under a generated ID unless fakeKeys names
+// it, GET returns a representation or lists a collection, filtered by the query parameters naming fields,
+// PUT merges the body into a representation, adding one with the ID ending its path when missing, such as a
+// default client scope of a realm, and DELETE removes it with everything under it. Arrays POSTed or DELETEd,
+// such as role mappings, add their items to a collection or remove them. The protocol mappers of clients and
+// client scopes are embedded in them, client secrets are regenerated, and groups are stored by ID with their
+// path whatever their parent, posting a group under a parent creating it or moving it there, and everything in a
+// deleted realm answers 404, like Keycloak does.
+type fakeKeycloak struct {
+	*httptest.Server
+	conn *keycloak.Connection
+
+	mu      sync.Mutex
+	objects map[string]map[string]any
+	order   []string
+	secrets int
+	// calls lists the requests changing Keycloak, bodies their bodies
+	calls  []string
+	bodies []string
+	// onCreate completes a representation POSTed under path, such as the path of a group
+	onCreate func(path string, obj map[string]any)
+	// onGet changes a representation before it is returned, such as to mask a secret
+	onGet func(path string, obj map[string]any)
+	// handlers override the requests they are keyed by, as "METHOD path"
+	handlers map[string]http.HandlerFunc
+	// deleted holds the paths of the realms deleted, until they are created again
+	deleted map[string]bool
+}
+
+// newFakeKeycloak starts a fake Keycloak, closed at the end of the spec, and a connection to it.
+func newFakeKeycloak() *fakeKeycloak {
+	fk := &fakeKeycloak{
+		objects:  map[string]map[string]any{},
+		handlers: map[string]http.HandlerFunc{},
+		deleted:  map[string]bool{},
+	}
+	fk.Server = httptest.NewServer(http.HandlerFunc(fk.serve))
+	DeferCleanup(fk.Close)
+
+	var err error
+	fk.conn, err = keycloak.NewConnection(keycloak.Config{URL: fk.URL, Username: "admin", Password: "admin"})
+	Expect(err).NotTo(HaveOccurred())
+	return fk
+}
+
+// put stores representation, given as JSON, under path.
+func (fk *fakeKeycloak) put(path, representation string) {
+	var obj map[string]any
+	Expect(json.Unmarshal([]byte(representation), &obj)).To(Succeed())
+	fk.mu.Lock()
+	defer fk.mu.Unlock()
+	fk.store(path, obj)
+}
+
+// get returns the representation stored under path, or nil.
+func (fk *fakeKeycloak) get(path string) map[string]any {
+	fk.mu.Lock()
+	defer fk.mu.Unlock()
+	return fk.objects[fk.resolve(path)]
+}
+
+// reset forgets the requests recorded so far.
+func (fk *fakeKeycloak) reset() {
+	fk.mu.Lock()
+	defer fk.mu.Unlock()
+	fk.calls, fk.bodies = nil, nil
+}
+
+// recorded returns the requests changing Keycloak recorded so far.
+func (fk *fakeKeycloak) recorded() []string {
+	fk.mu.Lock()
+	defer fk.mu.Unlock()
+	return slices.Clone(fk.calls)
+}
+
+// body returns the body of the last recorded request call.
+func (fk *fakeKeycloak) body(call string) string {
+	fk.mu.Lock()
+	defer fk.mu.Unlock()
+	for i := len(fk.calls) - 1; i >= 0; i-- {
+		if fk.calls[i] == call {
+			return fk.bodies[i]
+		}
+	}
+	return ""
+}
+
+func (fk *fakeKeycloak) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if strings.HasSuffix(r.URL.Path, "/protocol/openid-connect/token") {
+		_, _ = fmt.Fprint(w, `{"access_token":"token","expires_in":300,"refresh_token":"refresh","refresh_expires_in":1800}`)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	call := r.Method + " " + path
+
+	fk.mu.Lock()
+	if r.Method != http.MethodGet {
+		fk.calls = append(fk.calls, call)
+		fk.bodies = append(fk.bodies, string(body))
+	}
+	handler, overridden := fk.handlers[call]
+	fk.mu.Unlock()
+	if overridden {
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		handler(w, r)
+		return
+	}
+
+	fk.mu.Lock()
+	defer fk.mu.Unlock()
+	path = fk.resolve(path)
+	if fk.deleted[fakeRealm.FindString(path)] {
+		fk.notFound(w)
+		return
+	}
+	if client, ok := strings.CutSuffix(path, "/client-secret"); ok && fk.objects[client] != nil {
+		// Regenerating the secret of a client replaces it with a new one
+		if r.Method == http.MethodPost {
+			fk.secrets++
+			fk.objects[client]["secret"] = fmt.Sprintf("generated-secret-%d", fk.secrets)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"type": "secret", "value": fk.objects[client]["secret"]})
+		return
+	}
+	if match := fakeGroupParent.FindStringSubmatch(path); match != nil && r.Method == http.MethodPost {
+		fk.postGroup(w, match[1], match[2], body)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if obj, ok := fk.objects[path]; ok {
+			_ = json.NewEncoder(w).Encode(fk.render(path, obj))
+			return
+		}
+		if !slices.Contains(fakeCollections, path[strings.LastIndex(path, "/")+1:]) {
+			fk.notFound(w)
+			return
+		}
+		items := []map[string]any{}
+		for _, child := range fk.children(path) {
+			if fk.matches(fk.objects[child], r) {
+				items = append(items, fk.render(child, fk.objects[child]))
+			}
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	case http.MethodPost:
+		var items []map[string]any
+		if json.Unmarshal(body, &items) == nil {
+			for _, item := range items {
+				fk.store(path+"/"+fmt.Sprint(item["id"]), item)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var obj map[string]any
+		_ = json.Unmarshal(body, &obj)
+		id := fk.create(path, obj)
+		w.Header().Set("Location", r.URL.Path+"/"+id)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		var obj map[string]any
+		_ = json.Unmarshal(body, &obj)
+		stored := fk.objects[path]
+		if stored == nil {
+			stored = map[string]any{"id": path[strings.LastIndex(path, "/")+1:]}
+		}
+		maps.Copy(stored, obj)
+		fk.store(path, stored)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		var items []map[string]any
+		if json.Unmarshal(body, &items) == nil {
+			for _, item := range items {
+				fk.remove(path + "/" + fmt.Sprint(item["id"]))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if _, ok := fk.objects[path]; !ok {
+			fk.notFound(w)
+			return
+		}
+		fk.remove(path)
+		if fakeRealm.FindString(path) == path {
+			fk.deleted[path] = true
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// postGroup creates the group in body under the group parentID of the groups collection, at the top level
+// without parent, or moves it there when it exists.
+func (fk *fakeKeycloak) postGroup(w http.ResponseWriter, groups, parentID string, body []byte) {
+	var obj map[string]any
+	_ = json.Unmarshal(body, &obj)
+	parentPath := ""
+	if parentID != "" {
+		parentPath, _ = fk.objects[groups+"/"+parentID]["path"].(string)
+	}
+	obj["path"] = parentPath + "/" + fmt.Sprint(obj["name"])
+	if id, _ := obj["id"].(string); fk.objects[groups+"/"+id] != nil {
+		maps.Copy(fk.objects[groups+"/"+id], obj)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	id := fk.create(groups, obj)
+	w.Header().Set("Location", groups+"/"+id)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// create stores obj in the collection at path and returns its ID.
+func (fk *fakeKeycloak) create(path string, obj map[string]any) string {
+	collection := path[strings.LastIndex(path, "/")+1:]
+	id, _ := obj["id"].(string)
+	if id == "" {
+		for _, field := range []string{"name", "alias", "clientId", "username", "realm"} {
+			if name, ok := obj[field].(string); ok && name != "" {
+				id = name + "-id"
+				break
+			}
+		}
+		for base, i := id, 2; id == "" || fk.objects[path+"/"+id] != nil; i++ {
+			id = fmt.Sprintf("%s%d", base, i)
+		}
+		obj["id"] = id
+	}
+	key := id
+	if field, ok := fakeKeys[collection]; ok {
+		key = fmt.Sprint(obj[field])
+	}
+	fk.store(path+"/"+key, obj)
+	if fk.onCreate != nil {
+		fk.onCreate(path, obj)
+	}
+	return id
+}
+
+// store saves obj under path, moving embedded protocol mappers to their own collection.
+func (fk *fakeKeycloak) store(path string, obj map[string]any) {
+	if mappers, ok := obj["protocolMappers"].([]any); ok {
+		delete(obj, "protocolMappers")
+		for _, mapper := range mappers {
+			if mapper, ok := mapper.(map[string]any); ok {
+				fk.create(path+"/protocol-mappers/models", mapper)
+			}
+		}
+	}
+	if _, ok := fk.objects[path]; !ok {
+		fk.order = append(fk.order, path)
+	}
+	fk.objects[path] = obj
+	delete(fk.deleted, path)
+}
+
+// remove deletes the representation under path and everything under it.
+func (fk *fakeKeycloak) remove(path string) {
+	fk.order = slices.DeleteFunc(fk.order, func(stored string) bool {
+		if stored == path || strings.HasPrefix(stored, path+"/") {
+			delete(fk.objects, stored)
+			return true
+		}
+		return false
+	})
+}
+
+// children returns the paths of the representations directly under path, in creation order.
+func (fk *fakeKeycloak) children(path string) []string {
+	var children []string
+	for _, stored := range fk.order {
+		if rest, ok := strings.CutPrefix(stored, path+"/"); ok && !strings.Contains(rest, "/") {
+			children = append(children, stored)
+		}
+	}
+	return children
+}
+
+// resolve maps the paths addressing realm roles by ID and groups by path to the path they are stored under.
+func (fk *fakeKeycloak) resolve(path string) string {
+	if prefix, groupPath, ok := strings.Cut(path, "/group-by-path"); ok {
+		for _, group := range fk.children(prefix + "/groups") {
+			if fk.objects[group]["path"] == groupPath {
+				return group
+			}
+		}
+		return path
+	}
+	prefix, rest, ok := strings.Cut(path, "/roles-by-id/")
+	if !ok {
+		return path
+	}
+	id, sub, _ := strings.Cut(rest, "/")
+	for _, role := range fk.children(prefix + "/roles") {
+		if fk.objects[role]["id"] == id {
+			return strings.TrimSuffix(role+"/"+sub, "/")
+		}
+	}
+	return path
+}
+
+// matches reports whether obj has the values of the query parameters naming its fields.
+func (fk *fakeKeycloak) matches(obj map[string]any, r *http.Request) bool {
+	for key, values := range r.URL.Query() {
+		if value, ok := obj[key].(string); ok && value != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// render returns a copy of obj to return, with its protocol mappers embedded.
+func (fk *fakeKeycloak) render(path string, obj map[string]any) map[string]any {
+	rendered := maps.Clone(obj)
+	if mappers := fk.children(path + "/protocol-mappers/models"); len(mappers) > 0 {
+		embedded := make([]map[string]any, 0, len(mappers))
+		for _, mapper := range mappers {
+			embedded = append(embedded, fk.objects[mapper])
+		}
+		rendered["protocolMappers"] = embedded
+	}
+	if fk.onGet != nil {
+		fk.onGet(path, rendered)
+	}
+	return rendered
+}
+
+func (fk *fakeKeycloak) notFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = fmt.Fprint(w, `{"error":"Not found"}`)
+}
+
+// fakeCluster is a fake Kubernetes API for reconcile tests. It counts the status writes, and fails the
+// updates fail returns an error for.
+type fakeCluster struct {
+	client.Client
+	statusWrites int
+	fail         func(obj client.Object) error
+}
+
+// newFakeCluster returns a fake Kubernetes API holding objs.
+func newFakeCluster(objs ...client.Object) *fakeCluster {
+	fc := &fakeCluster{}
+	fc.Client = fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&keycloakv1.Client{}, &keycloakv1.Realm{}, &keycloakv1.ClientScope{},
+			&keycloakv1.RealmRole{}, &keycloakv1.Group{}, &keycloakv1.User{}, &keycloakv1.IdentityProvider{}).
+		WithIndex(&keycloakv1.Realm{}, realmSMTPSecretIndex, realmSMTPSecret).
+		WithIndex(&keycloakv1.IdentityProvider{}, identityProviderSecretIndex, identityProviderSecret).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if fc.fail != nil {
+					if err := fc.fail(obj); err != nil {
+						return err
+					}
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				fc.statusWrites++
+				return c.SubResource(subResource).Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	return fc
+}
+
+// newFakeRecorder returns an event recorder large enough for the events of a spec.
+func newFakeRecorder() *events.FakeRecorder {
+	return events.NewFakeRecorder(100)
+}
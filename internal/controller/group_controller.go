@@ -0,0 +1,452 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const groupFinalizer = "keycloak.pewty.fr/finalizer"
+
+// groupParentRefIndex indexes groups by the name of their parent Group.
+const groupParentRefIndex = ".spec.parentRef.name"
+
+// GroupReconciler reconciles a Group object
+type GroupReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to groups whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often groups are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
+}
+
+// parentGroup is the Keycloak group a group is a subgroup of.
+type parentGroup struct {
+	id   string
+	path string
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=groups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=groups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=groups/finalizers,verbs=update
+
+// Reconcile makes the Keycloak group match the Group resource: it creates the group under its parent,
+// updates its name, attributes and role mappings, moves it when its parent changes, and deletes it with
+// the resource unless it must be retained.
+func (r *GroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var group keycloakv1.Group
+	if err := r.Get(ctx, req.NamespacedName, &group); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Group resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get Group resource")
+		return ctrl.Result{}, err
+	}
+
+	// Take the realm of the referenced Realm resource, only in memory
+	realmReady, err := resolveRealm(ctx, r, &group, group.Spec.RealmRef, &group.Spec.Realm, group.Status.Realm)
+	if err != nil {
+		logger.Error(err, "Failed to resolve realmRef")
+		setReady(ctx, r.Client, &group, metav1.ConditionFalse, "InvalidRealm", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if !group.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &group)
+	}
+
+	if !controllerutil.ContainsFinalizer(&group, groupFinalizer) {
+		// Patch the finalizers only, the realm resolved above must not be written to the spec
+		patch := client.MergeFrom(group.DeepCopy())
+		controllerutil.AddFinalizer(&group, groupFinalizer)
+		if err := r.Patch(ctx, &group, patch); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Wait for the referenced Realm, its changes trigger a new reconciliation
+	if !realmReady {
+		message := fmt.Sprintf("Waiting for Realm %s to be Ready", group.Spec.RealmRef.Name)
+		logger.Info("Realm is not ready, waiting", "realmRef", group.Spec.RealmRef.Name)
+		setReady(ctx, r.Client, &group, metav1.ConditionFalse, "RealmNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	// Wait for the parent Group as well, a subgroup is created under the group it manages
+	parent, parentReady, err := r.resolveParent(ctx, &group)
+	if err != nil {
+		logger.Error(err, "Failed to resolve parentRef")
+		setReady(ctx, r.Client, &group, metav1.ConditionFalse, "InvalidParent", err.Error())
+		return ctrl.Result{}, err
+	}
+	if !parentReady {
+		message := fmt.Sprintf("Waiting for parent Group %s to be Ready", group.Spec.ParentRef.Name)
+		logger.Info("Parent group is not ready, waiting", "parentRef", group.Spec.ParentRef.Name)
+		setReady(ctx, r.Client, &group, metav1.ConditionFalse, "ParentGroupNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, &group, group.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gc := conn.Client
+	realm := *group.Spec.Realm
+	name := groupName(&group)
+	path := parent.path + "/" + name
+
+	existing, err := r.findGroup(ctx, gc, token, &group, path)
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak groups")
+		setReady(ctx, r.Client, &group, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query group: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	desired := desiredGroup(&group)
+	if existing == nil {
+		logger.Info("Creating group in Keycloak", "realm", realm, "path", path)
+		var id string
+		if parent.id != "" {
+			id, err = gc.CreateChildGroup(ctx, token, realm, parent.id, desired)
+		} else {
+			id, err = gc.CreateGroup(ctx, token, realm, desired)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to create group in Keycloak")
+			setReady(ctx, r.Client, &group, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			return ctrl.Result{}, err
+		}
+		// The ID is recorded right away, so that a failure below does not look the group up by path again
+		group.Status.ID = id
+		group.Status.Realm = realm
+
+		plan := planGroupRoleMappings(&group, nil)
+		if err := r.syncRoleMappings(ctx, gc, token, &group, id, plan); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully created group in Keycloak", "path", path, "id", id)
+
+		markGroupSynced(&group, realm, id, path)
+		setReady(ctx, r.Client, &group, metav1.ConditionTrue, "Created", "Group successfully created in Keycloak")
+		return ctrl.Result{RequeueAfter: resyncInterval(group.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+	id := gocloak.PString(existing.ID)
+
+	adopting, ok := claimObject(ctx, r.Client, r.Recorder, &group, group.Spec.AdoptionPolicy, firstValues(groupAttributes(existing)),
+		"Keycloak group "+path)
+	if !ok {
+		return ctrl.Result{RequeueAfter: resyncInterval(group.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	mappings, err := gc.GetRoleMappingByGroupID(ctx, token, realm, id)
+	if err != nil {
+		logger.Error(err, "Failed to get group role mappings from Keycloak")
+		setReady(ctx, r.Client, &group, metav1.ConditionFalse, "RoleMappingsFailed",
+			fmt.Sprintf("Failed to get group role mappings: %v", err))
+		return ctrl.Result{}, err
+	}
+	rolePlan := planGroupRoleMappings(&group, mappings)
+
+	fieldsDrifted := diffFields(desired, *existing, "id", "path", "subGroups")
+	drifted := fieldsDrifted
+	// The parent is not part of the group representation, Keycloak only exposes it through the path
+	moving := groupParentPath(existing) != parent.path
+	if moving {
+		drifted = append(drifted, "parentRef")
+	}
+	drifted = append(drifted, rolePlan.drifted()...)
+
+	// Differences on a resource already applied at this generation were made in Keycloak directly.
+	// A new name in the spec renames the group.
+	renaming := gocloak.PString(existing.Name) != name
+	if len(drifted) > 0 && !adopting && !renaming && isSynced(group.Status.Conditions, group.Generation) {
+		if !recordDrift(ctx, r.Recorder, &group, &group.Status.Conditions, syncDriftPolicy(group.Spec.SyncPolicy), drifted) {
+			if err := writeStatus(ctx, r.Client, &group); err != nil {
+				logger.Error(err, "Failed to update Group status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resyncInterval(group.Spec.SyncPolicy, r.ResyncInterval)}, nil
+		}
+	} else {
+		clearDrift(&group.Status.Conditions, group.Generation)
+	}
+
+	if len(drifted) > 0 {
+		logger.Info("Updating group in Keycloak", "path", path, "fields", drifted)
+		desired.ID = &id
+		attributes := mergeValues(groupAttributes(existing), *desired.Attributes)
+		desired.Attributes = &attributes
+		if moving {
+			// Posting an existing group under a parent moves it there, and to the top level without parent
+			if parent.id != "" {
+				_, err = gc.CreateChildGroup(ctx, token, realm, parent.id, desired)
+			} else {
+				_, err = gc.CreateGroup(ctx, token, realm, desired)
+			}
+			if err != nil {
+				logger.Error(err, "Failed to move group in Keycloak")
+				setReady(ctx, r.Client, &group, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to move: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+		if len(fieldsDrifted) > 0 {
+			if err := gc.UpdateGroup(ctx, token, realm, desired); err != nil {
+				logger.Error(err, "Failed to update group in Keycloak")
+				setReady(ctx, r.Client, &group, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.syncRoleMappings(ctx, gc, token, &group, id, rolePlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully updated group in Keycloak", "path", path)
+	}
+
+	markGroupSynced(&group, realm, id, path)
+	if len(drifted) > 0 {
+		setReady(ctx, r.Client, &group, metav1.ConditionTrue, "Updated", "Group successfully updated in Keycloak")
+	} else {
+		setReady(ctx, r.Client, &group, metav1.ConditionTrue, "UpToDate", "Group is up to date in Keycloak")
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval(group.Spec.SyncPolicy, r.ResyncInterval)}, nil
+}
+
+// reconcileDelete removes the group from Keycloak and releases the finalizer
+func (r *GroupReconciler) reconcileDelete(ctx context.Context, group *keycloakv1.Group) (ctrl.Result, error) {
+	// Without a realm, the referenced Realm is gone before the group was ever synced
+	var cleanup func() error
+	if group.Spec.Realm != nil {
+		cleanup = func() error { return r.cleanupKeycloak(ctx, group) }
+	}
+	return finalize(ctx, r.Client, r.Recorder, group, groupFinalizer, group.Spec.DeletionPolicy,
+		r.DefaultDeletionPolicy, "Keycloak group "+groupName(group), cleanup)
+}
+
+// cleanupKeycloak deletes the group from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *GroupReconciler) cleanupKeycloak(ctx context.Context, group *keycloakv1.Group) error {
+	conn, token, err := connect(ctx, r.Client, r.Connections, group, group.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	gc := conn.Client
+
+	// The parent may be gone already, so the group is looked up by the path it was last synced with
+	path := group.Status.Path
+	if path == "" && group.Spec.ParentRef == nil {
+		path = "/" + groupName(group)
+	}
+	return deleteOwned(ctx, r.Client, group, "Keycloak group "+cmp.Or(path, groupName(group)),
+		func() (*gocloak.Group, error) { return r.findGroup(ctx, gc, token, group, path) },
+		func(existing *gocloak.Group) map[string]string { return firstValues(groupAttributes(existing)) },
+		func(existing *gocloak.Group) error {
+			return gc.DeleteGroup(ctx, token, *group.Spec.Realm, gocloak.PString(existing.ID))
+		})
+}
+
+// resolveParent returns the Keycloak group of the parent Group resource and reports whether it is Ready.
+// Groups without a parentRef are top-level groups, always ready.
+func (r *GroupReconciler) resolveParent(ctx context.Context, group *keycloakv1.Group) (parentGroup, bool, error) {
+	ref := group.Spec.ParentRef
+	if ref == nil {
+		return parentGroup{}, true, nil
+	}
+	if ref.Name == group.Name {
+		return parentGroup{}, false, fmt.Errorf("group cannot be its own parent")
+	}
+
+	var parent keycloakv1.Group
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: group.Namespace}, &parent); err != nil {
+		if apierrors.IsNotFound(err) {
+			return parentGroup{}, false, nil
+		}
+		return parentGroup{}, false, fmt.Errorf("failed to get parent Group %s: %w", ref.Name, err)
+	}
+	if !meta.IsStatusConditionTrue(parent.Status.Conditions, "Ready") || parent.Status.ID == "" {
+		return parentGroup{}, false, nil
+	}
+	if parent.Status.Realm != *group.Spec.Realm {
+		return parentGroup{}, false, fmt.Errorf("realm %s does not match realm %s of parent Group %s",
+			*group.Spec.Realm, parent.Status.Realm, ref.Name)
+	}
+	return parentGroup{id: parent.Status.ID, path: parent.Status.Path}, true, nil
+}
+
+// findGroup looks up the Keycloak group of the Group resource, by the ID recorded in the status when it
+// was synced to the same realm, else by path. It returns nil when the group does not exist.
+func (r *GroupReconciler) findGroup(ctx context.Context, gc *gocloak.GoCloak, token string, group *keycloakv1.Group, path string) (*gocloak.Group, error) {
+	realm := *group.Spec.Realm
+	if group.Status.ID != "" && group.Status.Realm == realm {
+		existing, err := gc.GetGroup(ctx, token, realm, group.Status.ID)
+		if err == nil {
+			return existing, nil
+		}
+		if !keycloak.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if path == "" {
+		return nil, nil
+	}
+	existing, err := gc.GetGroupByPath(ctx, token, realm, strings.TrimPrefix(path, "/"))
+	if keycloak.IsNotFound(err) {
+		return nil, nil
+	}
+	return existing, err
+}
+
+// syncRoleMappings applies plan to the role mappings of the Keycloak group groupID and reports failures in
+// the Ready condition, with the RoleNotFound reason when a role does not exist in the realm.
+func (r *GroupReconciler) syncRoleMappings(ctx context.Context, gc *gocloak.GoCloak, token string, group *keycloakv1.Group, groupID string, plan roleMappingPlan) error {
+	logger := logf.FromContext(ctx)
+
+	err := applyRoleMappings(ctx, gc, token, *group.Spec.Realm, plan, groupRoleMapper(gc, groupID))
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to update group role mappings in Keycloak")
+	reason := "RoleMappingsFailed"
+	var notFound *rolesNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "RoleNotFound"
+		r.Recorder.Eventf(group, nil, corev1.EventTypeWarning, reason, "GrantRoles", err.Error())
+	}
+	setReady(ctx, r.Client, group, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
+
+// groupName returns the name of the Keycloak group of a Group resource.
+func groupName(group *keycloakv1.Group) string {
+	if group.Spec.Name != "" {
+		return group.Spec.Name
+	}
+	return group.Name
+}
+
+// groupAttributes returns the attributes of a Keycloak group, or nil when it has none.
+func groupAttributes(group *gocloak.Group) map[string][]string {
+	if group.Attributes == nil {
+		return nil
+	}
+	return *group.Attributes
+}
+
+// groupParentPath returns the path of the parent of a Keycloak group, empty for a top-level group.
+func groupParentPath(group *gocloak.Group) string {
+	return strings.TrimSuffix(gocloak.PString(group.Path), "/"+gocloak.PString(group.Name))
+}
+
+// desiredGroup returns the Keycloak group described by the spec, marked as managed by group.
+// Role mappings are applied separately.
+func desiredGroup(group *keycloakv1.Group) gocloak.Group {
+	attributes := withOwnerValues(group.Spec.Attributes, group)
+	return gocloak.Group{
+		Name:       gocloak.StringP(groupName(group)),
+		Attributes: &attributes,
+	}
+}
+
+// planGroupRoleMappings compares the roles declared on a group with its live role mappings.
+func planGroupRoleMappings(group *keycloakv1.Group, live *gocloak.MappingsRepresentation) roleMappingPlan {
+	exact := group.Spec.RoleMappingPolicy == keycloakv1.RoleMappingPolicyExact
+	return planRoleMappings(group.Spec.RealmRoles, group.Spec.ClientRoles, exact, live, "")
+}
+
+// markGroupSynced records in the status the Keycloak group the resource is now in sync with.
+func markGroupSynced(group *keycloakv1.Group, realm, id, path string) {
+	now := metav1.Now()
+	group.Status.ID = id
+	group.Status.Realm = realm
+	group.Status.Path = path
+	group.Status.ObservedGeneration = group.Generation
+	group.Status.LastSyncedTime = &now
+}
+
+// groupsForParent returns the reconcile requests of the subgroups of parent.
+func (r *GroupReconciler) groupsForParent(ctx context.Context, parent client.Object) []reconcile.Request {
+	var groups keycloakv1.GroupList
+	if err := r.List(ctx, &groups, client.InNamespace(parent.GetNamespace()),
+		client.MatchingFields{groupParentRefIndex: parent.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list subgroups of Group", "parent", parent.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(groups.Items))
+	for _, group := range groups.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&group)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexRealmRef(mgr, &keycloakv1.Group{}, func(obj client.Object) *keycloakv1.RealmReference {
+		return obj.(*keycloakv1.Group).Spec.RealmRef
+	}); err != nil {
+		return err
+	}
+	// Index groups by their parent, to reconcile subgroups when it becomes Ready or moves
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &keycloakv1.Group{}, groupParentRefIndex,
+		func(obj client.Object) []string {
+			if ref := obj.(*keycloakv1.Group).Spec.ParentRef; ref != nil {
+				return []string{ref.Name}
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.Group{}, ignoreStatusUpdates).
+		Watches(&keycloakv1.Realm{}, handler.EnqueueRequestsFromMapFunc(requestsForRealm(r.Client, &keycloakv1.GroupList{})),
+			realmReadinessChanged).
+		Watches(&keycloakv1.Group{}, handler.EnqueueRequestsFromMapFunc(r.groupsForParent)).
+		Named("group").
+		Complete(r)
+}
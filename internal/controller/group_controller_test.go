@@ -0,0 +1,394 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("Group Controller", func() {
+	Context("When converting a Group to a Keycloak group", func() {
+		It("Should map the spec, mark ownership and default the name", func() {
+			group := &keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default", UID: "group-uid"},
+				Spec: keycloakv1.GroupSpec{
+					Attributes: map[string][]string{"cost-center": {"42"}},
+				},
+			}
+			desired := desiredGroup(group)
+			Expect(*desired.Name).To(Equal("backend"))
+			Expect(*desired.Attributes).To(HaveKeyWithValue("cost-center", []string{"42"}))
+			Expect(firstValues(*desired.Attributes)).To(HaveKeyWithValue(ownerUIDAttribute, "group-uid"))
+
+			group.Spec.Name = "platform"
+			Expect(*desiredGroup(group).Name).To(Equal("platform"))
+		})
+
+		It("Should derive the parent path from the path of the group", func() {
+			Expect(groupParentPath(&gocloak.Group{Name: strPtr("backend"), Path: strPtr("/engineering/backend")})).
+				To(Equal("/engineering"))
+			Expect(groupParentPath(&gocloak.Group{Name: strPtr("engineering"), Path: strPtr("/engineering")})).To(BeEmpty())
+		})
+
+		It("Should plan the role mappings of the group", func() {
+			group := &keycloakv1.Group{Spec: keycloakv1.GroupSpec{
+				RealmRoles:  []string{"developer"},
+				ClientRoles: map[string][]string{"my-app": {"reader"}},
+			}}
+			live := &gocloak.MappingsRepresentation{
+				RealmMappings: &[]gocloak.Role{{ID: strPtr("admin-id"), Name: strPtr("admin")}},
+			}
+			plan := planGroupRoleMappings(group, live)
+			Expect(plan.realm.grant).To(Equal([]string{"developer"}))
+			Expect(plan.realm.revoke).To(BeEmpty())
+			Expect(plan.clients["my-app"].grant).To(Equal([]string{"reader"}))
+			Expect(plan.drifted()).To(Equal([]string{"realmRoles", "clientRoles"}))
+
+			group.Spec.ClientRoles = nil
+			group.Spec.RoleMappingPolicy = keycloakv1.RoleMappingPolicyExact
+			plan = planGroupRoleMappings(group, live)
+			Expect(plan.realm.revoke).To(HaveLen(1))
+			Expect(plan.drifted()).To(Equal([]string{"realmRoles"}))
+		})
+	})
+
+	Context("When talking to Keycloak", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+			calls  []string
+		)
+
+		BeforeEach(func() {
+			calls = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /admin/realms/demo/groups/old-id":
+					_, _ = fmt.Fprint(w, `{"id":"old-id","name":"old-name","path":"/old-name"}`)
+				case "GET /admin/realms/demo/group-by-path/engineering/backend":
+					_, _ = fmt.Fprint(w, `{"id":"backend-id","name":"backend","path":"/engineering/backend"}`)
+				case "GET /admin/realms/demo/roles/developer":
+					_, _ = fmt.Fprint(w, `{"id":"developer-id","name":"developer"}`)
+				default:
+					if r.Method != http.MethodGet {
+						calls = append(calls, r.Method+" "+r.URL.Path)
+						w.WriteHeader(http.StatusNoContent)
+						return
+					}
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find group"}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newGroup := func(id, realm string) *keycloakv1.Group {
+			return &keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+				Spec:       keycloakv1.GroupSpec{Realm: strPtr("demo")},
+				Status:     keycloakv1.GroupStatus{ID: id, Realm: realm},
+			}
+		}
+
+		It("Should prefer the ID recorded in the status, to rename or move the group", func() {
+			found, err := (&GroupReconciler{}).findGroup(context.Background(), conn.Client, "token", newGroup("old-id", "demo"), "/engineering/backend")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("old-id"))
+		})
+
+		It("Should look up the group by path in another realm or when it is gone", func() {
+			found, err := (&GroupReconciler{}).findGroup(context.Background(), conn.Client, "token", newGroup("old-id", "other"), "/engineering/backend")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("backend-id"))
+
+			found, err = (&GroupReconciler{}).findGroup(context.Background(), conn.Client, "token", newGroup("deleted-id", "demo"), "/backend")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+
+		It("Should grant and revoke the role mappings of the group", func() {
+			plan := roleMappingPlan{
+				realm: roleGrants{grant: []string{"developer"}, revoke: []gocloak.Role{{ID: strPtr("admin-id"), Name: strPtr("admin")}}},
+			}
+			Expect(applyRoleMappings(context.Background(), conn.Client, "token", "demo", plan,
+				groupRoleMapper(conn.Client, "backend-id"))).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/demo/groups/backend-id/role-mappings/realm",
+				"POST /admin/realms/demo/groups/backend-id/role-mappings/realm",
+			}))
+		})
+	})
+
+	Context("When reconciling a Group resource", func() {
+		ctx := context.Background()
+
+		It("Should reject a Group without realm or with a slash in its name", func() {
+			resource := &keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-group-without-realm", Namespace: "default"},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("realm or realmRef is required")))
+
+			resource.Spec = keycloakv1.GroupSpec{Realm: strPtr("test-realm"), Name: "engineering/backend"}
+			Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("spec.name")))
+		})
+
+		It("Should wait for the parent Group to be Ready in the same realm", func() {
+			reconciler := &GroupReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			child := &keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-child-group", Namespace: "default"},
+				Spec: keycloakv1.GroupSpec{
+					Realm:     strPtr("test-realm"),
+					ParentRef: &keycloakv1.GroupReference{Name: "test-parent-group"},
+				},
+			}
+			_, ready, err := reconciler.resolveParent(ctx, child)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeFalse())
+
+			parent := &keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-parent-group", Namespace: "default"},
+				Spec:       keycloakv1.GroupSpec{Realm: strPtr("test-realm")},
+			}
+			Expect(k8sClient.Create(ctx, parent)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, parent)).To(Succeed()) })
+			_, ready, err = reconciler.resolveParent(ctx, child)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeFalse())
+
+			parent.Status = keycloakv1.GroupStatus{ID: "parent-id", Realm: "test-realm", Path: "/test-parent-group"}
+			meta.SetStatusCondition(&parent.Status.Conditions, metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "Created", Message: "Group created",
+			})
+			Expect(k8sClient.Status().Update(ctx, parent)).To(Succeed())
+			resolved, ready, err := reconciler.resolveParent(ctx, child)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeTrue())
+			Expect(resolved).To(Equal(parentGroup{id: "parent-id", path: "/test-parent-group"}))
+
+			child.Spec.Realm = strPtr("other-realm")
+			_, _, err = reconciler.resolveParent(ctx, child)
+			Expect(err).To(MatchError(ContainSubstring("does not match realm test-realm")))
+		})
+	})
+
+	Context("When syncing a Group with Keycloak", func() {
+		const groupsPath = "/admin/realms/test-realm/groups"
+		var (
+			fk         *fakeKeycloak
+			c          *fakeCluster
+			reconciler *GroupReconciler
+			req        ctrl.Request
+		)
+
+		// reconcile runs the reconciler the given number of times, and returns the Group resource
+		reconcile := func(times int) *keycloakv1.Group {
+			for range times {
+				_, err := reconciler.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			group := &keycloakv1.Group{}
+			Expect(c.Get(context.Background(), req.NamespacedName, group)).To(Succeed())
+			return group
+		}
+
+		// setup creates the Group resource group, and for each of the parents a Ready Group resource synced to
+		// a top-level Keycloak group
+		setup := func(group *keycloakv1.Group, parents ...string) {
+			fk = newFakeKeycloak()
+			// Keycloak lists the role mappings of every group
+			fk.onCreate = func(path string, obj map[string]any) {
+				if path == groupsPath {
+					fk.store(path+"/"+obj["id"].(string)+"/role-mappings", map[string]any{})
+				}
+			}
+			group.Namespace = "default"
+			group.UID = "backend-uid"
+			group.Generation = 1
+			group.Spec.Realm = strPtr("test-realm")
+			objs := []client.Object{group}
+			for _, name := range parents {
+				fk.put(groupsPath+"/"+name+"-id", fmt.Sprintf(`{"id":"%s-id","name":"%s","path":"/%s"}`, name, name, name))
+				parent := &keycloakv1.Group{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec:       keycloakv1.GroupSpec{Realm: strPtr("test-realm")},
+					Status:     keycloakv1.GroupStatus{ID: name + "-id", Realm: "test-realm", Path: "/" + name},
+				}
+				meta.SetStatusCondition(&parent.Status.Conditions, metav1.Condition{
+					Type: "Ready", Status: metav1.ConditionTrue, Reason: "Created", Message: "Group created",
+				})
+				objs = append(objs, parent)
+			}
+			c = newFakeCluster(objs...)
+			reconciler = &GroupReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: group.Name, Namespace: "default"}}
+		}
+
+		It("Should create the group, update it with the spec and delete it with the resource", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "backend"}})
+
+			group := reconcile(2)
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(group.Status.ID).To(Equal("backend-id"))
+			Expect(group.Status.Path).To(Equal("/backend"))
+			Expect(fk.recorded()).To(Equal([]string{"POST " + groupsPath}))
+			Expect(fk.get(groupsPath + "/backend-id")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, ConsistOf("backend-uid")))
+
+			fk.reset()
+			group.Spec.Attributes = map[string][]string{"team": {"backend"}}
+			group.Generation = 2
+			Expect(c.Update(ctx, group)).To(Succeed())
+			group = reconcile(1)
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT " + groupsPath + "/backend-id"}))
+			Expect(fk.get(groupsPath + "/backend-id")["attributes"]).To(HaveKeyWithValue("team", ConsistOf("backend")))
+
+			fk.reset()
+			Expect(c.Delete(ctx, group)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(Equal([]string{"DELETE " + groupsPath + "/backend-id"}))
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, group))).To(BeTrue())
+
+			// The resource is gone, there is nothing left to reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should create the subgroup under its parent and move it when the parent changes", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+				Spec:       keycloakv1.GroupSpec{ParentRef: &keycloakv1.GroupReference{Name: "engineering"}},
+			}, "engineering", "platform")
+
+			group := reconcile(2)
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(group.Status.Path).To(Equal("/engineering/backend"))
+			Expect(fk.recorded()).To(Equal([]string{"POST " + groupsPath + "/engineering-id/children"}))
+
+			// Posting the existing group under the new parent moves it, keeping its ID and members
+			fk.reset()
+			group.Spec.ParentRef.Name = "platform"
+			group.Generation = 2
+			Expect(c.Update(ctx, group)).To(Succeed())
+			group = reconcile(1)
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(group.Status.ID).To(Equal("backend-id"))
+			Expect(group.Status.Path).To(Equal("/platform/backend"))
+			Expect(fk.recorded()).To(Equal([]string{"POST " + groupsPath + "/platform-id/children"}))
+			var moved gocloak.Group
+			Expect(json.Unmarshal([]byte(fk.body("POST "+groupsPath+"/platform-id/children")), &moved)).To(Succeed())
+			Expect(gocloak.PString(moved.ID)).To(Equal("backend-id"))
+			Expect(fk.get(groupsPath + "/backend-id")).To(HaveKeyWithValue("path", "/platform/backend"))
+
+			fk.reset()
+			Expect(meta.FindStatusCondition(reconcile(1).Status.Conditions, "Ready").Reason).To(Equal("UpToDate"))
+			Expect(fk.recorded()).To(BeEmpty())
+		})
+
+		It("Should keep the group in Keycloak when the resource retains it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+				Spec:       keycloakv1.GroupSpec{DeletionPolicy: keycloakv1.DeletionPolicyRetain},
+			})
+			group := reconcile(2)
+
+			fk.reset()
+			Expect(c.Delete(ctx, group)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(fk.get(groupsPath + "/backend-id")).NotTo(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, group))).To(BeTrue())
+		})
+
+		DescribeTable("Should handle the fields changed in Keycloak according to the drift policy",
+			func(policy keycloakv1.DriftPolicy, reason string, team string) {
+				setup(&keycloakv1.Group{
+					ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+					Spec: keycloakv1.GroupSpec{
+						Attributes: map[string][]string{"team": {"backend"}},
+						SyncPolicy: &keycloakv1.SyncPolicy{DriftPolicy: policy},
+					},
+				})
+				reconcile(2)
+
+				fk.get(groupsPath + "/backend-id")["attributes"].(map[string]any)["team"] = []any{"frontend"}
+				group := reconcile(1)
+				drifted := meta.FindStatusCondition(group.Status.Conditions, "Drifted")
+				Expect(drifted.Reason).To(Equal(reason))
+				Expect(drifted.Message).To(ContainSubstring("attributes"))
+				Expect(fk.get(groupsPath + "/backend-id")["attributes"]).To(HaveKeyWithValue("team", ConsistOf(team)))
+			},
+			Entry("Correct by default", keycloakv1.DriftPolicy(""), "DriftCorrected", "backend"),
+			Entry("Report", keycloakv1.DriftPolicyReport, "DriftDetected", "frontend"),
+		)
+
+		It("Should refuse a group it did not create until asked to adopt it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "backend"}})
+			fk.put(groupsPath+"/backend-id", `{"id":"backend-id","name":"backend","path":"/backend"}`)
+			fk.put(groupsPath+"/backend-id/role-mappings", `{}`)
+
+			group := reconcile(2)
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Ready").Reason).To(Equal("Conflict"))
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Conflict").Message).To(Equal(
+				"Keycloak group /backend is not created by this resource, set adoptionPolicy to IfUnowned to adopt it"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			group.Spec.AdoptionPolicy = keycloakv1.AdoptionPolicyIfUnowned
+			group.Generation = 2
+			Expect(c.Update(ctx, group)).To(Succeed())
+			group = reconcile(1)
+			Expect(meta.FindStatusCondition(group.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(meta.IsStatusConditionFalse(group.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(fk.get(groupsPath + "/backend-id")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, ConsistOf("backend-uid")))
+		})
+	})
+})
@@ -0,0 +1,540 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const identityProviderFinalizer = "keycloak.pewty.fr/finalizer"
+
+// identityProviderSecretIndex indexes identity providers by the name of the Secret holding their client secret.
+const identityProviderSecretIndex = ".spec.clientSecretRef.name"
+
+// identityProviderClientSecretKey is the config key of the client secret, which Keycloak does not disclose.
+const identityProviderClientSecretKey = "clientSecret"
+
+// identityProviderDiffIgnoredFields are the identity provider fields never compared as a whole: the internal
+// ID, and the config whose client secret Keycloak does not disclose.
+var identityProviderDiffIgnoredFields = []string{"internalId", "config"}
+
+// IdentityProviderReconciler reconciles a IdentityProvider object
+type IdentityProviderReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to identity providers whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often identity providers are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=identityproviders,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=identityproviders/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=identityproviders/finalizers,verbs=update
+
+// Reconcile makes the Keycloak identity provider match the IdentityProvider resource: it creates the identity
+// provider, updates its settings, config and mappers, and deletes it with the resource unless it must be retained.
+func (r *IdentityProviderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var idp keycloakv1.IdentityProvider
+	if err := r.Get(ctx, req.NamespacedName, &idp); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("IdentityProvider resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get IdentityProvider resource")
+		return ctrl.Result{}, err
+	}
+
+	// Take the realm of the referenced Realm resource, only in memory
+	realmReady, err := resolveRealm(ctx, r, &idp, idp.Spec.RealmRef, &idp.Spec.Realm, idp.Status.Realm)
+	if err != nil {
+		logger.Error(err, "Failed to resolve realmRef")
+		setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "InvalidRealm", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if !idp.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &idp)
+	}
+
+	if !controllerutil.ContainsFinalizer(&idp, identityProviderFinalizer) {
+		// Patch the finalizers only, the realm resolved above must not be written to the spec
+		patch := client.MergeFrom(idp.DeepCopy())
+		controllerutil.AddFinalizer(&idp, identityProviderFinalizer)
+		if err := r.Patch(ctx, &idp, patch); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Wait for the referenced Realm, its changes trigger a new reconciliation
+	if !realmReady {
+		message := fmt.Sprintf("Waiting for Realm %s to be Ready", idp.Spec.RealmRef.Name)
+		logger.Info("Realm is not ready, waiting", "realmRef", idp.Spec.RealmRef.Name)
+		setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "RealmNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	secret, secretVersion, err := r.getClientSecret(ctx, &idp)
+	if err != nil {
+		logger.Error(err, "Failed to get client secret from secret")
+		setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "SecretReadFailed", fmt.Sprintf("Failed to read secret: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, &idp, idp.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gc := conn.Client
+	realm := *idp.Spec.Realm
+	alias := identityProviderAlias(&idp)
+
+	existing, err := findIdentityProvider(ctx, gc, token, realm, alias)
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak identity providers")
+		setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query identity provider: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	desired := desiredIdentityProvider(&idp, secret)
+	desiredMappers := toIdentityProviderMappers(alias, idp.Spec.Mappers)
+	if existing == nil {
+		logger.Info("Creating identity provider in Keycloak", "realm", realm, "alias", alias)
+		if _, err := gc.CreateIdentityProvider(ctx, token, realm, desired); err != nil {
+			logger.Error(err, "Failed to create identity provider in Keycloak")
+			setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			return ctrl.Result{}, err
+		}
+		created, err := gc.GetIdentityProvider(ctx, token, realm, alias)
+		if err != nil {
+			logger.Error(err, "Failed to get created identity provider details")
+			setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Identity provider created but failed to retrieve: %v", err))
+			return ctrl.Result{}, err
+		}
+		id := gocloak.PString(created.InternalID)
+		// The realm is recorded right away, so that the identity provider is still deleted with the resource
+		// when a failure below leaves it unsynced and its Realm goes away
+		idp.Status.ID = id
+		idp.Status.Realm = realm
+
+		plan := planIdentityProviderMappers(desiredMappers, nil)
+		if err := applyIdentityProviderMappers(ctx, gc, token, realm, alias, plan); err != nil {
+			logger.Error(err, "Failed to create identity provider mappers in Keycloak")
+			setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "MappersFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully created identity provider in Keycloak", "alias", alias, "id", id)
+
+		markIdentityProviderSynced(&idp, realm, id, alias, secretVersion)
+		setReady(ctx, r.Client, &idp, metav1.ConditionTrue, "Created", "Identity provider successfully created in Keycloak")
+		return ctrl.Result{RequeueAfter: resyncInterval(idp.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+	id := gocloak.PString(existing.InternalID)
+
+	adopting, ok := claimObject(ctx, r.Client, r.Recorder, &idp, idp.Spec.AdoptionPolicy, identityProviderConfig(existing),
+		"Keycloak identity provider "+alias)
+	if !ok {
+		return ctrl.Result{RequeueAfter: resyncInterval(idp.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	liveMappers, err := gc.GetIdentityProviderMappers(ctx, token, realm, alias)
+	if err != nil {
+		logger.Error(err, "Failed to query the identity provider mappers")
+		setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query identity provider mappers: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	fieldsDrifted := diffIdentityProvider(desired, *existing)
+	drifted := fieldsDrifted
+	mapperPlan := planIdentityProviderMappers(desiredMappers, liveMappers)
+	if !mapperPlan.empty() {
+		drifted = append(drifted, "mappers")
+	}
+	// The client secret is not disclosed by Keycloak, it is sent again when its Secret changes
+	secretChanged := secretVersion != idp.Status.ClientSecretVersion
+
+	// Differences on a resource already applied at this generation were made in Keycloak directly
+	if len(drifted) > 0 && !adopting && isSynced(idp.Status.Conditions, idp.Generation) {
+		if !recordDrift(ctx, r.Recorder, &idp, &idp.Status.Conditions, syncDriftPolicy(idp.Spec.SyncPolicy), drifted) {
+			if err := writeStatus(ctx, r.Client, &idp); err != nil {
+				logger.Error(err, "Failed to update IdentityProvider status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resyncInterval(idp.Spec.SyncPolicy, r.ResyncInterval)}, nil
+		}
+	} else {
+		clearDrift(&idp.Status.Conditions, idp.Generation)
+	}
+
+	updating := len(drifted) > 0 || secretChanged
+	if updating {
+		logger.Info("Updating identity provider in Keycloak", "alias", alias, "fields", drifted)
+		if len(fieldsDrifted) > 0 || secretChanged {
+			if err := gc.UpdateIdentityProvider(ctx, token, realm, alias, updatedIdentityProvider(existing, desired)); err != nil {
+				logger.Error(err, "Failed to update identity provider in Keycloak")
+				setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+		if err := applyIdentityProviderMappers(ctx, gc, token, realm, alias, mapperPlan); err != nil {
+			logger.Error(err, "Failed to update identity provider mappers in Keycloak")
+			setReady(ctx, r.Client, &idp, metav1.ConditionFalse, "MappersFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully updated identity provider in Keycloak", "alias", alias)
+	}
+
+	markIdentityProviderSynced(&idp, realm, id, alias, secretVersion)
+	if updating {
+		setReady(ctx, r.Client, &idp, metav1.ConditionTrue, "Updated", "Identity provider successfully updated in Keycloak")
+	} else {
+		setReady(ctx, r.Client, &idp, metav1.ConditionTrue, "UpToDate", "Identity provider is up to date in Keycloak")
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval(idp.Spec.SyncPolicy, r.ResyncInterval)}, nil
+}
+
+// reconcileDelete removes the identity provider from Keycloak and releases the finalizer
+func (r *IdentityProviderReconciler) reconcileDelete(ctx context.Context, idp *keycloakv1.IdentityProvider) (ctrl.Result, error) {
+	// Without a realm, the referenced Realm is gone before the identity provider was ever synced
+	var cleanup func() error
+	if idp.Spec.Realm != nil {
+		cleanup = func() error { return r.cleanupKeycloak(ctx, idp) }
+	}
+	return finalize(ctx, r.Client, r.Recorder, idp, identityProviderFinalizer, idp.Spec.DeletionPolicy,
+		r.DefaultDeletionPolicy, "Keycloak identity provider "+identityProviderAlias(idp), cleanup)
+}
+
+// cleanupKeycloak deletes the identity provider from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *IdentityProviderReconciler) cleanupKeycloak(ctx context.Context, idp *keycloakv1.IdentityProvider) error {
+	conn, token, err := connect(ctx, r.Client, r.Connections, idp, idp.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	gc := conn.Client
+	return deleteOwned(ctx, r.Client, idp, "Keycloak identity provider "+identityProviderAlias(idp),
+		func() (*gocloak.IdentityProviderRepresentation, error) {
+			return findIdentityProvider(ctx, gc, token, *idp.Spec.Realm, identityProviderAlias(idp))
+		},
+		identityProviderConfig,
+		func(existing *gocloak.IdentityProviderRepresentation) error {
+			return gc.DeleteIdentityProvider(ctx, token, *idp.Spec.Realm, gocloak.PString(existing.Alias))
+		})
+}
+
+// getClientSecret reads the client secret from the referenced Secret. It returns the client secret with the
+// resource version of the Secret, both empty when the spec references none.
+func (r *IdentityProviderReconciler) getClientSecret(ctx context.Context, idp *keycloakv1.IdentityProvider) (string, string, error) {
+	ref := idp.Spec.ClientSecretRef
+	if ref == nil {
+		return "", "", nil
+	}
+	key := ref.Key
+	if key == "" {
+		key = identityProviderClientSecretKey
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: idp.Namespace}, secret); err != nil {
+		return "", "", fmt.Errorf("failed to get secret %s: %w", ref.Name, err)
+	}
+	clientSecret, ok := secret.Data[key]
+	if !ok {
+		return "", "", fmt.Errorf("key %s not found in secret %s", key, ref.Name)
+	}
+	return string(clientSecret), secret.ResourceVersion, nil
+}
+
+// findIdentityProvider looks up the Keycloak identity provider alias of realm. It returns nil when the
+// identity provider does not exist.
+func findIdentityProvider(ctx context.Context, gc *gocloak.GoCloak, token, realm, alias string) (*gocloak.IdentityProviderRepresentation, error) {
+	existing, err := gc.GetIdentityProvider(ctx, token, realm, alias)
+	if keycloak.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// identityProviderAlias returns the alias of the Keycloak identity provider of an IdentityProvider resource.
+func identityProviderAlias(idp *keycloakv1.IdentityProvider) string {
+	if idp.Spec.Alias != "" {
+		return idp.Spec.Alias
+	}
+	return idp.Name
+}
+
+// identityProviderConfig returns the config of a Keycloak identity provider, or nil when it has none.
+// Identity providers have no attributes, the ownership markers are kept in their config.
+func identityProviderConfig(idp *gocloak.IdentityProviderRepresentation) map[string]string {
+	if idp.Config == nil {
+		return nil
+	}
+	return *idp.Config
+}
+
+// desiredIdentityProvider returns the Keycloak identity provider described by the spec, marked as managed by
+// idp. The client secret is only set when the spec references one. Mappers are applied separately.
+func desiredIdentityProvider(idp *keycloakv1.IdentityProvider, clientSecret string) gocloak.IdentityProviderRepresentation {
+	spec := &idp.Spec
+	config := withOwner(spec.Config, idp)
+	if clientSecret != "" {
+		config[identityProviderClientSecretKey] = clientSecret
+	}
+	return gocloak.IdentityProviderRepresentation{
+		Alias:                     gocloak.StringP(identityProviderAlias(idp)),
+		ProviderID:                gocloak.StringP(spec.ProviderID),
+		DisplayName:               spec.DisplayName,
+		Enabled:                   spec.Enabled,
+		TrustEmail:                spec.TrustEmail,
+		StoreToken:                spec.StoreToken,
+		LinkOnly:                  spec.LinkOnly,
+		FirstBrokerLoginFlowAlias: spec.FirstBrokerLoginFlowAlias,
+		PostBrokerLoginFlowAlias:  spec.PostBrokerLoginFlowAlias,
+		Config:                    &config,
+	}
+}
+
+// diffIdentityProvider returns the JSON names of the fields of desired that differ in the live Keycloak
+// identity provider. The config only compares the keys desired, except for the client secret.
+func diffIdentityProvider(desired, live gocloak.IdentityProviderRepresentation) []string {
+	drifted := diffFields(desired, live, identityProviderDiffIgnoredFields...)
+	liveConfig := identityProviderConfig(&live)
+	for key, value := range identityProviderConfig(&desired) {
+		if key == identityProviderClientSecretKey {
+			continue
+		}
+		if liveValue, ok := liveConfig[key]; !ok || liveValue != value {
+			drifted = append(drifted, "config")
+			break
+		}
+	}
+	return drifted
+}
+
+// updatedIdentityProvider returns the live identity provider with the fields set in desired. Keycloak replaces
+// the whole identity provider on update, so the settings and config keys the spec does not manage are sent back
+// as they are. The masked client secret sent back tells Keycloak to keep the current one.
+func updatedIdentityProvider(live *gocloak.IdentityProviderRepresentation, desired gocloak.IdentityProviderRepresentation) gocloak.IdentityProviderRepresentation {
+	updated := *live
+	if desired.DisplayName != nil {
+		updated.DisplayName = desired.DisplayName
+	}
+	if desired.Enabled != nil {
+		updated.Enabled = desired.Enabled
+	}
+	if desired.TrustEmail != nil {
+		updated.TrustEmail = desired.TrustEmail
+	}
+	if desired.StoreToken != nil {
+		updated.StoreToken = desired.StoreToken
+	}
+	if desired.LinkOnly != nil {
+		updated.LinkOnly = desired.LinkOnly
+	}
+	if desired.FirstBrokerLoginFlowAlias != nil {
+		updated.FirstBrokerLoginFlowAlias = desired.FirstBrokerLoginFlowAlias
+	}
+	if desired.PostBrokerLoginFlowAlias != nil {
+		updated.PostBrokerLoginFlowAlias = desired.PostBrokerLoginFlowAlias
+	}
+	config := maps.Clone(identityProviderConfig(live))
+	if config == nil {
+		config = make(map[string]string)
+	}
+	maps.Copy(config, *desired.Config)
+	updated.Config = &config
+	return updated
+}
+
+// identityProviderMapperPlan lists the changes that make the mappers of an identity provider match the desired ones.
+type identityProviderMapperPlan struct {
+	create []gocloak.IdentityProviderMapper
+	update []gocloak.IdentityProviderMapper
+	delete []*gocloak.IdentityProviderMapper
+}
+
+// empty reports whether the identity provider mappers already match.
+func (p identityProviderMapperPlan) empty() bool {
+	return len(p.create) == 0 && len(p.update) == 0 && len(p.delete) == 0
+}
+
+// planIdentityProviderMappers matches the desired and live identity provider mappers by name. Mappers missing
+// in Keycloak are created, mappers that differ are updated and mappers not desired are deleted, all of them when
+// desired is empty. A nil desired leaves the mappers in Keycloak alone.
+func planIdentityProviderMappers(desired []gocloak.IdentityProviderMapper, live []*gocloak.IdentityProviderMapper) identityProviderMapperPlan {
+	var plan identityProviderMapperPlan
+	if desired == nil {
+		return plan
+	}
+
+	liveByName := make(map[string]*gocloak.IdentityProviderMapper, len(live))
+	for _, mapper := range live {
+		liveByName[gocloak.PString(mapper.Name)] = mapper
+	}
+
+	desiredNames := make(map[string]bool, len(desired))
+	for _, mapper := range desired {
+		name := gocloak.PString(mapper.Name)
+		desiredNames[name] = true
+
+		liveMapper, ok := liveByName[name]
+		switch {
+		case !ok:
+			plan.create = append(plan.create, mapper)
+		case len(diffFields(mapper, *liveMapper, "id")) > 0:
+			mapper.ID = liveMapper.ID
+			plan.update = append(plan.update, mapper)
+		}
+	}
+
+	for _, mapper := range live {
+		if !desiredNames[gocloak.PString(mapper.Name)] {
+			plan.delete = append(plan.delete, mapper)
+		}
+	}
+	return plan
+}
+
+// applyIdentityProviderMappers applies plan to the mappers of the Keycloak identity provider alias.
+// Mappers are deleted first so that a mapper can be replaced by another one of the same name.
+func applyIdentityProviderMappers(ctx context.Context, gc *gocloak.GoCloak, token, realm, alias string, plan identityProviderMapperPlan) error {
+	for _, mapper := range plan.delete {
+		err := gc.DeleteIdentityProviderMapper(ctx, token, realm, alias, gocloak.PString(mapper.ID))
+		if err != nil && !keycloak.IsNotFound(err) {
+			return fmt.Errorf("failed to delete identity provider mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	for _, mapper := range plan.update {
+		if err := gc.UpdateIdentityProviderMapper(ctx, token, realm, alias, mapper); err != nil {
+			return fmt.Errorf("failed to update identity provider mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	for _, mapper := range plan.create {
+		if _, err := gc.CreateIdentityProviderMapper(ctx, token, realm, alias, mapper); err != nil {
+			return fmt.Errorf("failed to create identity provider mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	return nil
+}
+
+// toIdentityProviderMappers converts the mappers of a spec to GoCloak mappers of the identity provider alias.
+func toIdentityProviderMappers(alias string, mappers []keycloakv1.IdentityProviderMapper) []gocloak.IdentityProviderMapper {
+	if mappers == nil {
+		return nil
+	}
+	converted := make([]gocloak.IdentityProviderMapper, len(mappers))
+	for i, mapper := range mappers {
+		config := maps.Clone(mapper.Config)
+		if config == nil {
+			config = make(map[string]string)
+		}
+		converted[i] = gocloak.IdentityProviderMapper{
+			Name:                   gocloak.StringP(mapper.Name),
+			IdentityProviderMapper: gocloak.StringP(mapper.IdentityProviderMapper),
+			IdentityProviderAlias:  gocloak.StringP(alias),
+			Config:                 &config,
+		}
+	}
+	return converted
+}
+
+// markIdentityProviderSynced records in the status the Keycloak identity provider the resource is now in sync with.
+func markIdentityProviderSynced(idp *keycloakv1.IdentityProvider, realm, id, alias, secretVersion string) {
+	now := metav1.Now()
+	idp.Status.ID = id
+	idp.Status.Realm = realm
+	idp.Status.Alias = alias
+	idp.Status.ObservedGeneration = idp.Generation
+	idp.Status.LastSyncedTime = &now
+	idp.Status.ClientSecretVersion = secretVersion
+}
+
+// identityProviderSecret returns the name of the Secret holding the client secret of an IdentityProvider, if any.
+func identityProviderSecret(obj client.Object) []string {
+	if ref := obj.(*keycloakv1.IdentityProvider).Spec.ClientSecretRef; ref != nil {
+		return []string{ref.Name}
+	}
+	return nil
+}
+
+// identityProvidersForSecret returns the reconcile requests of the identity providers reading their client
+// secret from secret.
+func (r *IdentityProviderReconciler) identityProvidersForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var idps keycloakv1.IdentityProviderList
+	if err := r.List(ctx, &idps, client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{identityProviderSecretIndex: secret.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list IdentityProviders reading Secret", "secret", secret.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(idps.Items))
+	for _, idp := range idps.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&idp)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IdentityProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index identity providers by the Secret of their client secret, to send it again when it changes
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &keycloakv1.IdentityProvider{}, identityProviderSecretIndex,
+		identityProviderSecret); err != nil {
+		return err
+	}
+	if err := indexRealmRef(mgr, &keycloakv1.IdentityProvider{}, func(obj client.Object) *keycloakv1.RealmReference {
+		return obj.(*keycloakv1.IdentityProvider).Spec.RealmRef
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.IdentityProvider{}, ignoreStatusUpdates).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.identityProvidersForSecret)).
+		Watches(&keycloakv1.Realm{}, handler.EnqueueRequestsFromMapFunc(requestsForRealm(r.Client, &keycloakv1.IdentityProviderList{})),
+			realmReadinessChanged).
+		Named("identityprovider").
+		Complete(r)
+}
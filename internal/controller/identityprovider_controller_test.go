@@ -0,0 +1,461 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("IdentityProvider Controller", func() {
+	Context("When converting an IdentityProvider to a Keycloak identity provider", func() {
+		newIdentityProvider := func() *keycloakv1.IdentityProvider {
+			return &keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad", Namespace: "default", UID: "idp-uid"},
+				Spec: keycloakv1.IdentityProviderSpec{
+					ProviderID:                "oidc",
+					DisplayName:               strPtr("Azure AD"),
+					FirstBrokerLoginFlowAlias: strPtr("first broker login"),
+					Config:                    map[string]string{"clientId": "my-client"},
+				},
+			}
+		}
+
+		It("Should map the spec, mark ownership in the config and default the alias", func() {
+			desired := desiredIdentityProvider(newIdentityProvider(), "s3cret")
+			Expect(*desired.Alias).To(Equal("azure-ad"))
+			Expect(*desired.ProviderID).To(Equal("oidc"))
+			Expect(*desired.FirstBrokerLoginFlowAlias).To(Equal("first broker login"))
+			Expect(*desired.Config).To(HaveKeyWithValue("clientId", "my-client"))
+			Expect(*desired.Config).To(HaveKeyWithValue("clientSecret", "s3cret"))
+			Expect(*desired.Config).To(HaveKeyWithValue(ownerUIDAttribute, "idp-uid"))
+
+			idp := newIdentityProvider()
+			idp.Spec.Alias = "azure"
+			desired = desiredIdentityProvider(idp, "")
+			Expect(*desired.Alias).To(Equal("azure"))
+			Expect(*desired.Config).NotTo(HaveKey("clientSecret"))
+		})
+
+		It("Should not report the undisclosed client secret as drift", func() {
+			desired := desiredIdentityProvider(newIdentityProvider(), "s3cret")
+			live := gocloak.IdentityProviderRepresentation{
+				InternalID:                strPtr("idp-id"),
+				Alias:                     strPtr("azure-ad"),
+				ProviderID:                strPtr("oidc"),
+				DisplayName:               strPtr("Azure AD"),
+				Enabled:                   gocloak.BoolP(true),
+				FirstBrokerLoginFlowAlias: strPtr("first broker login"),
+				Config: &map[string]string{
+					"clientId":        "my-client",
+					"clientSecret":    "**********",
+					"syncMode":        "IMPORT",
+					ownerUIDAttribute: "idp-uid",
+					ownerAttribute:    "default/azure-ad",
+				},
+			}
+			Expect(diffIdentityProvider(desired, live)).To(BeEmpty())
+
+			(*live.Config)["clientId"] = "other-client"
+			live.DisplayName = strPtr("Other")
+			Expect(diffIdentityProvider(desired, live)).To(Equal([]string{"displayName", "config"}))
+		})
+
+		It("Should send back the settings and config the spec does not manage", func() {
+			live := &gocloak.IdentityProviderRepresentation{
+				InternalID: strPtr("idp-id"),
+				Alias:      strPtr("azure-ad"),
+				Enabled:    gocloak.BoolP(true),
+				TrustEmail: gocloak.BoolP(true),
+				Config:     &map[string]string{"clientId": "old-client", "clientSecret": "**********", "syncMode": "IMPORT"},
+			}
+			updated := updatedIdentityProvider(live, desiredIdentityProvider(newIdentityProvider(), ""))
+			Expect(*updated.InternalID).To(Equal("idp-id"))
+			Expect(*updated.DisplayName).To(Equal("Azure AD"))
+			Expect(*updated.Enabled).To(BeTrue())
+			Expect(*updated.TrustEmail).To(BeTrue())
+			Expect(*updated.Config).To(HaveKeyWithValue("clientId", "my-client"))
+			Expect(*updated.Config).To(HaveKeyWithValue("clientSecret", "**********"))
+			Expect(*updated.Config).To(HaveKeyWithValue("syncMode", "IMPORT"))
+			Expect(*live.Config).To(HaveKeyWithValue("clientId", "old-client"))
+		})
+
+		It("Should plan the mappers by name", func() {
+			desired := toIdentityProviderMappers("azure-ad", []keycloakv1.IdentityProviderMapper{
+				{Name: "department", IdentityProviderMapper: "oidc-user-attribute-idp-mapper", Config: map[string]string{"claim": "department"}},
+				{Name: "admins", IdentityProviderMapper: "hardcoded-role-idp-mapper", Config: map[string]string{"role": "admin"}},
+			})
+			live := []*gocloak.IdentityProviderMapper{
+				{ID: strPtr("department-id"), Name: strPtr("department"), IdentityProviderMapper: strPtr("oidc-user-attribute-idp-mapper"),
+					IdentityProviderAlias: strPtr("azure-ad"), Config: &map[string]string{"claim": "dept"}},
+				{ID: strPtr("extra-id"), Name: strPtr("extra"), IdentityProviderMapper: strPtr("hardcoded-role-idp-mapper"),
+					IdentityProviderAlias: strPtr("azure-ad"), Config: &map[string]string{}},
+			}
+			plan := planIdentityProviderMappers(desired, live)
+			Expect(plan.create).To(HaveLen(1))
+			Expect(*plan.create[0].Name).To(Equal("admins"))
+			Expect(*plan.create[0].IdentityProviderAlias).To(Equal("azure-ad"))
+			Expect(plan.update).To(HaveLen(1))
+			Expect(*plan.update[0].ID).To(Equal("department-id"))
+			Expect(plan.delete).To(Equal([]*gocloak.IdentityProviderMapper{live[1]}))
+
+			Expect(planIdentityProviderMappers(toIdentityProviderMappers("azure-ad", nil), live).empty()).To(BeTrue())
+
+			plan = planIdentityProviderMappers(toIdentityProviderMappers("azure-ad", []keycloakv1.IdentityProviderMapper{}), live[1:])
+			Expect(plan.create).To(BeEmpty())
+			Expect(plan.update).To(BeEmpty())
+			Expect(plan.delete).To(Equal([]*gocloak.IdentityProviderMapper{live[1]}))
+		})
+	})
+
+	Context("When talking to Keycloak", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+			calls  []string
+		)
+
+		BeforeEach(func() {
+			calls = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /admin/realms/demo/identity-provider/instances/azure-ad":
+					_, _ = fmt.Fprint(w, `{"internalId":"idp-id","alias":"azure-ad","providerId":"oidc"}`)
+				default:
+					if r.Method != http.MethodGet {
+						body, _ := io.ReadAll(r.Body)
+						calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+						w.WriteHeader(http.StatusNoContent)
+						return
+					}
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find identity provider"}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should look up the identity provider by alias", func() {
+			found, err := findIdentityProvider(context.Background(), conn.Client, "token", "demo", "azure-ad")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.InternalID)).To(Equal("idp-id"))
+
+			found, err = findIdentityProvider(context.Background(), conn.Client, "token", "demo", "github")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+
+		It("Should delete, update and create the mappers of the identity provider", func() {
+			plan := identityProviderMapperPlan{
+				create: []gocloak.IdentityProviderMapper{{Name: strPtr("admins"), Config: &map[string]string{}}},
+				update: []gocloak.IdentityProviderMapper{{ID: strPtr("department-id"), Name: strPtr("department"), Config: &map[string]string{}}},
+				delete: []*gocloak.IdentityProviderMapper{{ID: strPtr("extra-id"), Name: strPtr("extra")}},
+			}
+			Expect(applyIdentityProviderMappers(context.Background(), conn.Client, "token", "demo", "azure-ad", plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/demo/identity-provider/instances/azure-ad/mappers/extra-id ",
+				`PUT /admin/realms/demo/identity-provider/instances/azure-ad/mappers/department-id {"id":"department-id","name":"department","config":{}}`,
+				`POST /admin/realms/demo/identity-provider/instances/azure-ad/mappers {"name":"admins","config":{}}`,
+			}))
+		})
+	})
+
+	Context("When reconciling an IdentityProvider resource", func() {
+		ctx := context.Background()
+
+		It("Should reject an IdentityProvider without realm or with a client secret in its config", func() {
+			resource := &keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-invalid-identity-provider", Namespace: "default"},
+				Spec:       keycloakv1.IdentityProviderSpec{ProviderID: "github"},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("realm or realmRef is required")))
+
+			resource.Spec.Realm = strPtr("test-realm")
+			resource.Spec.Config = map[string]string{"clientSecret": "s3cret"}
+			Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("clientSecretRef")))
+		})
+
+		It("Should refuse to change the alias or the provider", func() {
+			resource := &keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-immutable-identity-provider", Namespace: "default"},
+				Spec:       keycloakv1.IdentityProviderSpec{Realm: strPtr("test-realm"), Alias: "github", ProviderID: "github"},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, resource)).To(Succeed()) })
+
+			resource.Spec.Alias = "gh"
+			Expect(k8sClient.Update(ctx, resource)).To(MatchError(ContainSubstring("alias is immutable")))
+			resource.Spec.Alias = "github"
+			resource.Spec.ProviderID = "oidc"
+			Expect(k8sClient.Update(ctx, resource)).To(MatchError(ContainSubstring("providerId is immutable")))
+		})
+
+		It("Should read the client secret from the referenced Secret", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-identity-provider-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"clientSecret": []byte("s3cret"), "other": []byte("0ther")},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) })
+
+			reconciler := &IdentityProviderReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			idp := &keycloakv1.IdentityProvider{ObjectMeta: metav1.ObjectMeta{Name: "azure-ad", Namespace: "default"}}
+			clientSecret, version, err := reconciler.getClientSecret(ctx, idp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientSecret).To(BeEmpty())
+			Expect(version).To(BeEmpty())
+
+			idp.Spec.ClientSecretRef = &keycloakv1.IdentityProviderSecretReference{Name: secret.Name}
+			clientSecret, version, err = reconciler.getClientSecret(ctx, idp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientSecret).To(Equal("s3cret"))
+			Expect(version).To(Equal(secret.ResourceVersion))
+
+			idp.Spec.ClientSecretRef.Key = "other"
+			clientSecret, _, err = reconciler.getClientSecret(ctx, idp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientSecret).To(Equal("0ther"))
+		})
+	})
+
+	Context("When syncing an IdentityProvider with Keycloak", func() {
+		const instancesPath = "/admin/realms/test-realm/identity-provider/instances"
+		var (
+			fk         *fakeKeycloak
+			c          *fakeCluster
+			reconciler *IdentityProviderReconciler
+			req        ctrl.Request
+		)
+
+		// reconcile runs the reconciler until the identity provider is synced, and returns the IdentityProvider
+		reconcile := func(times int) *keycloakv1.IdentityProvider {
+			for range times {
+				_, err := reconciler.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			idp := &keycloakv1.IdentityProvider{}
+			Expect(c.Get(context.Background(), req.NamespacedName, idp)).To(Succeed())
+			return idp
+		}
+
+		setup := func(idp *keycloakv1.IdentityProvider, objs ...client.Object) {
+			fk = newFakeKeycloak()
+			// Keycloak masks the client secret of the identity providers it returns
+			fk.onGet = func(path string, obj map[string]any) {
+				if config, ok := obj["config"].(map[string]any); ok && config["clientSecret"] != nil {
+					config = maps.Clone(config)
+					config["clientSecret"] = "**********"
+					obj["config"] = config
+				}
+			}
+			idp.Namespace = "default"
+			idp.UID = "azure-ad-uid"
+			idp.Generation = 1
+			idp.Spec.Realm = strPtr("test-realm")
+			c = newFakeCluster(append(objs, idp)...)
+			reconciler = &IdentityProviderReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: idp.Name, Namespace: "default"}}
+		}
+
+		It("Should send the client secret again as soon as its Secret changes", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"clientSecret": []byte("first")},
+			}
+			setup(&keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad"},
+				Spec: keycloakv1.IdentityProviderSpec{
+					ProviderID:      "oidc",
+					ClientSecretRef: &keycloakv1.IdentityProviderSecretReference{Name: secret.Name},
+				},
+			}, secret, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}})
+			idp := reconcile(2)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(fk.get(instancesPath + "/azure-ad")["config"]).To(HaveKeyWithValue("clientSecret", "first"))
+
+			Expect(reconciler.identityProvidersForSecret(context.Background(), secret)).To(Equal([]ctrl.Request{req}))
+			unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}}
+			Expect(reconciler.identityProvidersForSecret(context.Background(), unrelated)).To(BeEmpty())
+
+			Expect(c.Get(context.Background(), types.NamespacedName{Name: secret.Name, Namespace: "default"}, secret)).To(Succeed())
+			secret.Data["clientSecret"] = []byte("second")
+			Expect(c.Update(context.Background(), secret)).To(Succeed())
+			fk.reset()
+			idp = reconcile(1)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT " + instancesPath + "/azure-ad"}))
+			Expect(fk.get(instancesPath + "/azure-ad")["config"]).To(HaveKeyWithValue("clientSecret", "second"))
+		})
+
+		It("Should update the identity provider with the spec and delete it with the resource", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad"},
+				Spec:       keycloakv1.IdentityProviderSpec{ProviderID: "oidc", DisplayName: strPtr("Azure AD")},
+			})
+
+			idp := reconcile(2)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(fk.recorded()).To(Equal([]string{"POST " + instancesPath}))
+			Expect(fk.get(instancesPath + "/azure-ad")["config"]).To(HaveKeyWithValue(ownerUIDAttribute, "azure-ad-uid"))
+
+			fk.reset()
+			idp.Spec.DisplayName = strPtr("Microsoft Entra ID")
+			idp.Generation = 2
+			Expect(c.Update(ctx, idp)).To(Succeed())
+			idp = reconcile(1)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT " + instancesPath + "/azure-ad"}))
+			Expect(fk.get(instancesPath + "/azure-ad")).To(HaveKeyWithValue("displayName", "Microsoft Entra ID"))
+
+			fk.reset()
+			Expect(c.Delete(ctx, idp)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(Equal([]string{"DELETE " + instancesPath + "/azure-ad"}))
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, idp))).To(BeTrue())
+
+			// The resource is gone, there is nothing left to reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should neither report the masked client secret as drift nor send it back", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad"},
+				Spec: keycloakv1.IdentityProviderSpec{
+					ProviderID:      "oidc",
+					ClientSecretRef: &keycloakv1.IdentityProviderSecretReference{Name: "azure-ad-credentials"},
+				},
+			}, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"clientSecret": []byte("s3cret")},
+			})
+			reconcile(2)
+
+			fk.reset()
+			idp := reconcile(1)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("UpToDate"))
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Drifted").Reason).To(Equal("InSync"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			idp.Spec.DisplayName = strPtr("Azure AD")
+			idp.Generation = 2
+			Expect(c.Update(ctx, idp)).To(Succeed())
+			idp = reconcile(1)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.body("PUT " + instancesPath + "/azure-ad")).NotTo(ContainSubstring("**********"))
+			Expect(fk.get(instancesPath + "/azure-ad")["config"]).To(HaveKeyWithValue("clientSecret", "s3cret"))
+		})
+
+		It("Should keep the identity provider in Keycloak when the resource retains it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad"},
+				Spec:       keycloakv1.IdentityProviderSpec{ProviderID: "oidc", DeletionPolicy: keycloakv1.DeletionPolicyRetain},
+			})
+			idp := reconcile(2)
+
+			fk.reset()
+			Expect(c.Delete(ctx, idp)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(fk.get(instancesPath + "/azure-ad")).NotTo(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, idp))).To(BeTrue())
+		})
+
+		DescribeTable("Should handle the config changed in Keycloak according to the drift policy",
+			func(policy keycloakv1.DriftPolicy, reason string, issuer string) {
+				setup(&keycloakv1.IdentityProvider{
+					ObjectMeta: metav1.ObjectMeta{Name: "azure-ad"},
+					Spec: keycloakv1.IdentityProviderSpec{
+						ProviderID: "oidc",
+						Config:     map[string]string{"issuer": "https://login.example.com"},
+						SyncPolicy: &keycloakv1.SyncPolicy{DriftPolicy: policy},
+					},
+				})
+				reconcile(2)
+
+				fk.get(instancesPath + "/azure-ad")["config"].(map[string]any)["issuer"] = "https://changed.example.com"
+				idp := reconcile(1)
+				drifted := meta.FindStatusCondition(idp.Status.Conditions, "Drifted")
+				Expect(drifted.Reason).To(Equal(reason))
+				Expect(drifted.Message).To(ContainSubstring("config"))
+				Expect(fk.get(instancesPath + "/azure-ad")["config"]).To(HaveKeyWithValue("issuer", issuer))
+			},
+			Entry("Correct by default", keycloakv1.DriftPolicy(""), "DriftCorrected", "https://login.example.com"),
+			Entry("Report", keycloakv1.DriftPolicyReport, "DriftDetected", "https://changed.example.com"),
+		)
+
+		It("Should refuse an identity provider managed by another resource until asked to take it over", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.IdentityProvider{
+				ObjectMeta: metav1.ObjectMeta{Name: "azure-ad"},
+				Spec:       keycloakv1.IdentityProviderSpec{ProviderID: "oidc"},
+			})
+			fk.put(instancesPath+"/azure-ad", `{"alias":"azure-ad","providerId":"oidc","internalId":"azure-ad-id","config":{`+
+				`"keycloak.pewty.fr/owner-uid":"other-uid","keycloak.pewty.fr/owner":"default/other"}}`)
+
+			idp := reconcile(2)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Conflict"))
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Conflict").Message).To(Equal(
+				"Keycloak identity provider azure-ad is already managed by default/other, set adoptionPolicy to Always to take it over"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			idp.Spec.AdoptionPolicy = keycloakv1.AdoptionPolicyAlways
+			idp.Generation = 2
+			Expect(c.Update(ctx, idp)).To(Succeed())
+			idp = reconcile(1)
+			Expect(meta.FindStatusCondition(idp.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(meta.IsStatusConditionFalse(idp.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(fk.get(instancesPath + "/azure-ad")["config"]).To(HaveKeyWithValue(ownerUIDAttribute, "azure-ad-uid"))
+		})
+	})
+})
@@ -0,0 +1,217 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+)
+
+// KeycloakConnectionReconciler checks that a KeycloakConnection can authenticate against Keycloak
+type KeycloakConnectionReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Connections *ConnectionResolver
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=keycloakconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=keycloakconnections/status,verbs=get;update;patch
+
+// Reconcile validates the connection and reports the result in the Ready condition.
+func (r *KeycloakConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var conn keycloakv1.KeycloakConnection
+	if err := r.Get(ctx, req.NamespacedName, &conn); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Connections.Forget(kindKeycloakConnection, req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get KeycloakConnection resource")
+		return ctrl.Result{}, err
+	}
+
+	ref := &keycloakv1.ConnectionReference{Kind: kindKeycloakConnection, Name: conn.Name}
+	condition, checkErr := checkConnection(ctx, r.Connections, conn.Namespace, ref)
+	condition.ObservedGeneration = conn.Generation
+	meta.SetStatusCondition(&conn.Status.Conditions, condition)
+	if err := r.Status().Update(ctx, &conn); err != nil {
+		logger.Error(err, "Failed to update KeycloakConnection status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, checkErr
+}
+
+// connectionsForSecret returns the reconcile requests of the connections reading secret.
+func (r *KeycloakConnectionReconciler) connectionsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var conns keycloakv1.KeycloakConnectionList
+	if err := r.List(ctx, &conns, client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{connectionSecretIndex: secret.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list KeycloakConnections reading Secret", "secret", secret.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(conns.Items))
+	for _, conn := range conns.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&conn)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KeycloakConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index connections by the Secrets they read, to check them again when the credentials change
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &keycloakv1.KeycloakConnection{}, connectionSecretIndex,
+		keycloakConnectionSecrets); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.KeycloakConnection{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.connectionsForSecret)).
+		Named("keycloakconnection").
+		Complete(r)
+}
+
+// ClusterKeycloakConnectionReconciler checks that a ClusterKeycloakConnection can authenticate against Keycloak
+type ClusterKeycloakConnectionReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Connections *ConnectionResolver
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clusterkeycloakconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=clusterkeycloakconnections/status,verbs=get;update;patch
+
+// Reconcile validates the connection and reports the result in the Ready condition.
+func (r *ClusterKeycloakConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var conn keycloakv1.ClusterKeycloakConnection
+	if err := r.Get(ctx, req.NamespacedName, &conn); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Connections.Forget(kindClusterKeycloakConnection, "", req.Name)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get ClusterKeycloakConnection resource")
+		return ctrl.Result{}, err
+	}
+
+	ref := &keycloakv1.ConnectionReference{Kind: kindClusterKeycloakConnection, Name: conn.Name}
+	condition, checkErr := checkConnection(ctx, r.Connections, "", ref)
+	condition.ObservedGeneration = conn.Generation
+	meta.SetStatusCondition(&conn.Status.Conditions, condition)
+	if err := r.Status().Update(ctx, &conn); err != nil {
+		logger.Error(err, "Failed to update ClusterKeycloakConnection status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, checkErr
+}
+
+// connectionsForSecret returns the reconcile requests of the cluster connections reading secret.
+func (r *ClusterKeycloakConnectionReconciler) connectionsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var conns keycloakv1.ClusterKeycloakConnectionList
+	if err := r.List(ctx, &conns,
+		client.MatchingFields{connectionSecretIndex: client.ObjectKeyFromObject(secret).String()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list ClusterKeycloakConnections reading Secret", "secret", secret.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(conns.Items))
+	for _, conn := range conns.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&conn)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterKeycloakConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index cluster connections by the Secrets they read, to check them again when the credentials change
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &keycloakv1.ClusterKeycloakConnection{}, connectionSecretIndex,
+		clusterKeycloakConnectionSecrets); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.ClusterKeycloakConnection{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.connectionsForSecret)).
+		Named("clusterkeycloakconnection").
+		Complete(r)
+}
+
+// keycloakConnectionSecrets returns the names of the Secrets a KeycloakConnection reads.
+func keycloakConnectionSecrets(obj client.Object) []string {
+	return connectionSecrets(&obj.(*keycloakv1.KeycloakConnection).Spec)
+}
+
+// clusterKeycloakConnectionSecrets returns the namespaced names of the Secrets a ClusterKeycloakConnection
+// reads, which all live in the namespace of its credentials Secret.
+func clusterKeycloakConnectionSecrets(obj client.Object) []string {
+	spec := &obj.(*keycloakv1.ClusterKeycloakConnection).Spec
+	names := connectionSecrets(spec)
+	for i, name := range names {
+		names[i] = spec.CredentialsSecretRef.Namespace + "/" + name
+	}
+	return names
+}
+
+// checkConnection resolves the referenced connection and authenticates with it.
+// It returns the resulting Ready condition and the error that prevented authentication, if any.
+func checkConnection(ctx context.Context, connections *ConnectionResolver, namespace string, ref *keycloakv1.ConnectionReference) (metav1.Condition, error) {
+	logger := logf.FromContext(ctx)
+
+	conn, err := connections.Resolve(ctx, namespace, ref)
+	if err != nil {
+		logger.Error(err, "Invalid Keycloak connection")
+		return metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidConfiguration",
+			Message: fmt.Sprintf("Invalid connection: %v", err),
+		}, err
+	}
+
+	if _, err := conn.Token(ctx); err != nil {
+		logger.Error(err, "Failed to authenticate with Keycloak", "url", conn.URL())
+		return metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "AuthenticationFailed",
+			Message: fmt.Sprintf("Failed to authenticate: %v", err),
+		}, err
+	}
+
+	return metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionTrue,
+		Reason:  "Connected",
+		Message: "Successfully authenticated with Keycloak",
+	}, nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("KeycloakConnection Controller", func() {
+	const (
+		connectionName = "test-connection"
+		secretName     = "test-connection-credentials"
+	)
+
+	ctx := context.Background()
+
+	var resolver *ConnectionResolver
+
+	BeforeEach(func() {
+		resolver = &ConnectionResolver{
+			Client: k8sClient,
+			Cache:  keycloak.NewConnectionCache(),
+		}
+	})
+
+	Context("When resolving connections", func() {
+		BeforeEach(func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"},
+				StringData: map[string]string{"username": "admin", "password": "admin"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			conn := &keycloakv1.KeycloakConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: connectionName, Namespace: "default"},
+				Spec: keycloakv1.KeycloakConnectionSpec{
+					URL:                  "http://localhost:8080",
+					CredentialsSecretRef: keycloakv1.ConnectionCredentialsReference{Name: secretName},
+				},
+			}
+			Expect(k8sClient.Create(ctx, conn)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &keycloakv1.KeycloakConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: connectionName, Namespace: "default"},
+			})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"},
+			})).To(Succeed())
+		})
+
+		It("Should fail without a reference nor a default connection", func() {
+			_, err := resolver.Resolve(ctx, "default", nil)
+			Expect(err).To(MatchError(ContainSubstring("connectionRef is required")))
+		})
+
+		It("Should fall back to the default connection", func() {
+			defaultConn, err := keycloak.NewConnection(keycloak.Config{
+				URL: "http://default:8080", Username: "admin", Password: "admin",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			resolver.Default = defaultConn
+
+			conn, err := resolver.Resolve(ctx, "default", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn).To(BeIdenticalTo(defaultConn))
+		})
+
+		It("Should build and cache a connection from a KeycloakConnection", func() {
+			ref := &keycloakv1.ConnectionReference{Name: connectionName}
+
+			conn, err := resolver.Resolve(ctx, "default", ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.URL()).To(Equal("http://localhost:8080"))
+			Expect(conn.Realm()).To(Equal(keycloak.DefaultRealm))
+
+			again, err := resolver.Resolve(ctx, "default", ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(BeIdenticalTo(conn))
+		})
+
+		It("Should rebuild the connection when a TLS secret is missing then created", func() {
+			conn := &keycloakv1.KeycloakConnection{}
+			key := types.NamespacedName{Name: connectionName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, key, conn)).To(Succeed())
+			conn.Spec.TLS = &keycloakv1.ConnectionTLS{CASecretRef: &keycloakv1.SecretKeyReference{Name: "test-connection-ca"}}
+			Expect(k8sClient.Update(ctx, conn)).To(Succeed())
+
+			ref := &keycloakv1.ConnectionReference{Name: connectionName}
+			_, err := resolver.Resolve(ctx, "default", ref)
+			Expect(err).To(MatchError(ContainSubstring("failed to get CA secret")))
+
+			caSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-connection-ca", Namespace: "default"},
+				StringData: map[string]string{"ca.crt": "not a certificate"},
+			}
+			Expect(k8sClient.Create(ctx, caSecret)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, caSecret)).To(Succeed())
+			}()
+
+			_, err = resolver.Resolve(ctx, "default", ref)
+			Expect(err).To(MatchError(ContainSubstring("no valid PEM certificate")))
+		})
+
+		It("Should not resolve a KeycloakConnection from another namespace", func() {
+			_, err := resolver.Resolve(ctx, "kube-system", &keycloakv1.ConnectionReference{Name: connectionName})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should require the secret namespace for a ClusterKeycloakConnection", func() {
+			conn := &keycloakv1.ClusterKeycloakConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: connectionName},
+				Spec: keycloakv1.KeycloakConnectionSpec{
+					URL:                  "http://localhost:8080",
+					CredentialsSecretRef: keycloakv1.ConnectionCredentialsReference{Name: secretName},
+				},
+			}
+			Expect(k8sClient.Create(ctx, conn)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, conn)).To(Succeed())
+			}()
+
+			_, err := resolver.Resolve(ctx, "default", &keycloakv1.ConnectionReference{
+				Kind: kindClusterKeycloakConnection,
+				Name: connectionName,
+			})
+			Expect(err).To(MatchError(ContainSubstring("credentialsSecretRef.namespace is required")))
+		})
+	})
+
+	Context("When reconciling a KeycloakConnection", func() {
+		It("Should report an invalid configuration in the Ready condition", func() {
+			conn := &keycloakv1.KeycloakConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: connectionName + "-invalid", Namespace: "default"},
+				Spec: keycloakv1.KeycloakConnectionSpec{
+					URL:                  "http://localhost:8080",
+					CredentialsSecretRef: keycloakv1.ConnectionCredentialsReference{Name: "missing-secret"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, conn)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, conn)).To(Succeed())
+			}()
+
+			reconciler := &KeycloakConnectionReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				Connections: resolver,
+			}
+			namespacedName := types.NamespacedName{Name: conn.Name, Namespace: "default"}
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).To(HaveOccurred())
+
+			updated := &keycloakv1.KeycloakConnection{}
+			Expect(k8sClient.Get(ctx, namespacedName, updated)).To(Succeed())
+			condition := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("InvalidConfiguration"))
+		})
+
+		It("Should ignore a deleted KeycloakConnection", func() {
+			reconciler := &KeycloakConnectionReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				Connections: resolver,
+			}
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "non-existent-connection", Namespace: "default"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should check the connections again when a Secret they read changes", func() {
+			spec := keycloakv1.KeycloakConnectionSpec{
+				URL:                  "http://localhost:8080",
+				CredentialsSecretRef: keycloakv1.ConnectionCredentialsReference{Name: "credentials", Namespace: "keycloak"},
+				TLS: &keycloakv1.ConnectionTLS{
+					CASecretRef:                &keycloakv1.SecretKeyReference{Name: "ca"},
+					ClientCertificateSecretRef: &keycloakv1.TLSSecretReference{Name: "client-tls"},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithObjects(
+					&keycloakv1.KeycloakConnection{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "keycloak"}, Spec: spec},
+					&keycloakv1.KeycloakConnection{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"}, Spec: spec},
+					&keycloakv1.ClusterKeycloakConnection{ObjectMeta: metav1.ObjectMeta{Name: "shared"}, Spec: spec},
+				).
+				WithIndex(&keycloakv1.KeycloakConnection{}, connectionSecretIndex, keycloakConnectionSecrets).
+				WithIndex(&keycloakv1.ClusterKeycloakConnection{}, connectionSecretIndex, clusterKeycloakConnectionSecrets).
+				Build()
+			namespaced := &KeycloakConnectionReconciler{Client: c}
+			cluster := &ClusterKeycloakConnectionReconciler{Client: c}
+			secret := func(namespace, name string) *corev1.Secret {
+				return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+			}
+
+			Expect(namespaced.connectionsForSecret(ctx, secret("keycloak", "ca"))).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "tls", Namespace: "keycloak"}},
+			}))
+			Expect(cluster.connectionsForSecret(ctx, secret("keycloak", "client-tls"))).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "shared"}},
+			}))
+			Expect(cluster.connectionsForSecret(ctx, secret("default", "credentials"))).To(BeEmpty())
+			Expect(namespaced.connectionsForSecret(ctx, secret("default", "other"))).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,247 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const (
+	// ownerUIDAttribute marks a Keycloak object with the UID of the resource managing it
+	ownerUIDAttribute = "keycloak.pewty.fr/owner-uid"
+	// ownerAttribute names the resource managing a Keycloak object, for humans browsing the admin console
+	ownerAttribute = "keycloak.pewty.fr/owner"
+)
+
+// withOwner returns a copy of attributes marking the Keycloak object as managed by obj.
+func withOwner(attributes map[string]string, obj client.Object) map[string]string {
+	owned := maps.Clone(attributes)
+	if owned == nil {
+		owned = make(map[string]string)
+	}
+	owned[ownerUIDAttribute] = string(obj.GetUID())
+	owned[ownerAttribute] = client.ObjectKeyFromObject(obj).String()
+	return owned
+}
+
+// withOwnerValues returns a copy of multivalued attributes, such as those of roles, groups and users,
+// marking the Keycloak object as managed by obj.
+func withOwnerValues(attributes map[string][]string, obj client.Object) map[string][]string {
+	owned := maps.Clone(attributes)
+	if owned == nil {
+		owned = make(map[string][]string)
+	}
+	for key, value := range withOwner(nil, obj) {
+		owned[key] = []string{value}
+	}
+	return owned
+}
+
+// mergeValues returns a copy of the live multivalued attributes overridden by the desired ones. Keycloak
+// replaces all the attributes of roles, groups and users on update, so the attributes the spec does not
+// list are sent back as they are.
+func mergeValues(live, desired map[string][]string) map[string][]string {
+	merged := maps.Clone(live)
+	if merged == nil {
+		merged = make(map[string][]string, len(desired))
+	}
+	maps.Copy(merged, desired)
+	return merged
+}
+
+// firstValues returns the first value of each multivalued attribute, enough to read the ownership markers.
+func firstValues(attributes map[string][]string) map[string]string {
+	values := make(map[string]string, len(attributes))
+	for key, list := range attributes {
+		if len(list) > 0 {
+			values[key] = list[0]
+		}
+	}
+	return values
+}
+
+// checkAdoption decides whether obj may manage an existing Keycloak object with the given attributes.
+// managedBefore tells whether obj already managed the object before ownership markers were written.
+// It returns whether the object is being adopted, or a non-empty conflict message when it must be left alone.
+func checkAdoption(obj client.Object, policy keycloakv1.AdoptionPolicy, attributes map[string]string, managedBefore bool) (bool, string) {
+	ownerUID, owned := attributes[ownerUIDAttribute]
+	switch {
+	case owned && ownerUID == string(obj.GetUID()):
+		return false, ""
+	case owned && policy == keycloakv1.AdoptionPolicyAlways:
+		return true, ""
+	case owned:
+		return false, fmt.Sprintf("already managed by %s, set adoptionPolicy to Always to take it over", attributes[ownerAttribute])
+	case managedBefore:
+		return false, ""
+	case policy == keycloakv1.AdoptionPolicyIfUnowned || policy == keycloakv1.AdoptionPolicyAlways:
+		return true, ""
+	default:
+		return false, "not created by this resource, set adoptionPolicy to IfUnowned to adopt it"
+	}
+}
+
+// claimObject decides whether obj may manage an existing Keycloak object with the given attributes, and records
+// the outcome in its Conflict condition and Events. object names the Keycloak object for humans, such as
+// "Keycloak realm demo". It returns whether the object is being adopted, and false when it must be left alone,
+// in which case obj is no longer Ready.
+func claimObject(ctx context.Context, c client.Client, recorder events.EventRecorder, obj keycloakResource,
+	policy keycloakv1.AdoptionPolicy, attributes map[string]string, object string) (bool, bool) {
+	logger := logf.FromContext(ctx)
+	conditions := obj.GetConditions()
+
+	// Never take over an object another resource or someone else manages unless asked to
+	adopting, conflict := checkAdoption(obj, policy, attributes, managedBefore(conditions))
+	if conflict != "" {
+		message := fmt.Sprintf("%s is %s", object, conflict)
+		logger.Info("Refusing to manage Keycloak object", "object", object, "reason", conflict)
+		recorder.Eventf(obj, nil, corev1.EventTypeWarning, "Conflict", "Adopt", "%s", message)
+		setConflict(&conditions, obj.GetGeneration(), message)
+		obj.SetConditions(conditions)
+		setReady(ctx, c, obj, metav1.ConditionFalse, "Conflict", message)
+		return false, false
+	}
+	clearConflict(&conditions, obj.GetGeneration())
+	obj.SetConditions(conditions)
+	if adopting {
+		logger.Info("Adopting existing Keycloak object", "object", object, "adoptionPolicy", policy)
+		recorder.Eventf(obj, nil, corev1.EventTypeNormal, "Adopted", "Adopt", "Adopted existing %s", object)
+	}
+	return adopting, true
+}
+
+// finalize releases the finalizer of obj, which is being deleted, once cleanup deleted its Keycloak object or
+// the deletion policy retains it. object names the Keycloak object for humans. A nil cleanup means the
+// Keycloak object is unknown, such as when the referenced Realm is gone before it was ever synced.
+func finalize(ctx context.Context, c client.Client, recorder events.EventRecorder, obj keycloakResource, finalizer string,
+	specPolicy, defaultPolicy keycloakv1.DeletionPolicy, object string, cleanup func() error) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(obj, finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy, err := deletionPolicy(obj, specPolicy, defaultPolicy)
+	if err != nil {
+		// Refuse to guess: deleting an object that should have been retained cannot be undone
+		logger.Error(err, "Invalid deletion policy")
+		setReady(ctx, c, obj, metav1.ConditionFalse, "InvalidDeletionPolicy", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case policy == keycloakv1.DeletionPolicyRetain:
+		logger.Info("Retaining Keycloak object", "object", object, "deletionPolicy", policy)
+		recorder.Eventf(obj, nil, corev1.EventTypeNormal, "Retained", "Delete", "Retained %s", object)
+	case cleanup == nil:
+		logger.Info("Realm of the Keycloak object is unknown, nothing to delete", "object", object)
+	default:
+		if err := cleanup(); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Patch the finalizers only, a realm resolved from a realmRef must not be written to the spec
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	controllerutil.RemoveFinalizer(obj, finalizer)
+	if err := c.Patch(ctx, obj, patch); err != nil {
+		logger.Error(err, "Failed to remove finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// deleteOwned deletes the Keycloak object returned by find with remove, unless it does not exist or obj does
+// not manage it. attributes returns the attributes holding the ownership markers of the object. Failures are
+// recorded in the Ready condition of obj.
+func deleteOwned[T any](ctx context.Context, c client.Client, obj keycloakResource, object string,
+	find func() (*T, error), attributes func(*T) map[string]string, remove func(*T) error) error {
+	logger := logf.FromContext(ctx)
+
+	existing, err := find()
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak object", "object", object)
+		setReady(ctx, c, obj, metav1.ConditionFalse, "DeletionFailed", fmt.Sprintf("Failed to query %s: %v", object, err))
+		return err
+	}
+
+	switch {
+	case existing == nil:
+		logger.Info("Keycloak object not found, nothing to delete", "object", object)
+	case !isOwner(obj, attributes(existing), managedBefore(obj.GetConditions())):
+		logger.Info("Keycloak object is not managed by this resource, leaving it", "object", object)
+	default:
+		if err := remove(existing); err != nil && !keycloak.IsNotFound(err) {
+			logger.Error(err, "Failed to delete Keycloak object", "object", object)
+			setReady(ctx, c, obj, metav1.ConditionFalse, "DeletionFailed", fmt.Sprintf("Failed to delete: %v", err))
+			return err
+		}
+		logger.Info("Successfully deleted Keycloak object", "object", object)
+	}
+	return nil
+}
+
+// isOwner reports whether a Keycloak object with the given attributes is managed by obj.
+func isOwner(obj client.Object, attributes map[string]string, managedBefore bool) bool {
+	ownerUID, owned := attributes[ownerUIDAttribute]
+	if !owned {
+		return managedBefore
+	}
+	return ownerUID == string(obj.GetUID())
+}
+
+// managedBefore reports whether a resource with the given conditions was successfully reconciled before,
+// which means it manages its Keycloak object even if that object predates ownership markers.
+func managedBefore(conditions []metav1.Condition) bool {
+	return meta.IsStatusConditionTrue(conditions, "Ready")
+}
+
+// setConflict records in the Conflict condition why the Keycloak object cannot be managed.
+func setConflict(conditions *[]metav1.Condition, generation int64, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               "Conflict",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "NotOwned",
+		Message:            message,
+	})
+}
+
+// clearConflict records in the Conflict condition that the Keycloak object is managed by this resource.
+func clearConflict(conditions *[]metav1.Condition, generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               "Conflict",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "Owned",
+		Message:            "Keycloak object is managed by this resource",
+	})
+}
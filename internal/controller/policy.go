@@ -0,0 +1,46 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+)
+
+// deletionPolicy returns the deletion policy of obj: its deletion policy annotation if set,
+// else the policy of its spec, else the operator-wide default.
+func deletionPolicy(obj client.Object, spec, defaultPolicy keycloakv1.DeletionPolicy) (keycloakv1.DeletionPolicy, error) {
+	if value, ok := obj.GetAnnotations()[keycloakv1.DeletionPolicyAnnotation]; ok {
+		switch policy := keycloakv1.DeletionPolicy(value); policy {
+		case keycloakv1.DeletionPolicyDelete, keycloakv1.DeletionPolicyRetain:
+			return policy, nil
+		default:
+			return "", fmt.Errorf("invalid %s annotation %q: must be %s or %s", keycloakv1.DeletionPolicyAnnotation,
+				value, keycloakv1.DeletionPolicyDelete, keycloakv1.DeletionPolicyRetain)
+		}
+	}
+	if spec != "" {
+		return spec, nil
+	}
+	if defaultPolicy != "" {
+		return defaultPolicy, nil
+	}
+	return keycloakv1.DeletionPolicyDelete, nil
+}
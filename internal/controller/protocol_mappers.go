@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// protocolMapperPlan lists the changes that make the protocol mappers of a client match the desired ones.
+// Keycloak ignores protocol mappers on client updates, so they are changed through their own endpoints.
+type protocolMapperPlan struct {
+	create []gocloak.ProtocolMapperRepresentation
+	update []gocloak.ProtocolMapperRepresentation
+	delete []gocloak.ProtocolMapperRepresentation
+}
+
+// empty reports whether the protocol mappers already match.
+func (p protocolMapperPlan) empty() bool {
+	return len(p.create) == 0 && len(p.update) == 0 && len(p.delete) == 0
+}
+
+// planProtocolMappers matches the desired and live protocol mappers by name. Mappers missing in Keycloak are
+// created, mappers that differ are updated and mappers not desired are deleted, all of them when desired is empty.
+// A nil desired leaves the mappers in Keycloak alone. defaultProtocol applies to desired mappers without a protocol.
+func planProtocolMappers(desired, live []gocloak.ProtocolMapperRepresentation, defaultProtocol string) protocolMapperPlan {
+	var plan protocolMapperPlan
+	if desired == nil {
+		return plan
+	}
+
+	liveByName := make(map[string]gocloak.ProtocolMapperRepresentation, len(live))
+	for _, mapper := range live {
+		liveByName[gocloak.PString(mapper.Name)] = mapper
+	}
+
+	desiredNames := make(map[string]bool, len(desired))
+	for _, mapper := range desired {
+		name := gocloak.PString(mapper.Name)
+		desiredNames[name] = true
+		if mapper.Protocol == nil {
+			mapper.Protocol = &defaultProtocol
+		}
+
+		liveMapper, ok := liveByName[name]
+		switch {
+		case !ok:
+			plan.create = append(plan.create, mapper)
+		case len(diffFields(mapper, liveMapper, "id")) > 0:
+			mapper.ID = liveMapper.ID
+			plan.update = append(plan.update, mapper)
+		}
+	}
+
+	for _, mapper := range live {
+		if !desiredNames[gocloak.PString(mapper.Name)] {
+			plan.delete = append(plan.delete, mapper)
+		}
+	}
+	return plan
+}
+
+// applyProtocolMappers applies plan to the protocol mappers of the Keycloak client idOfClient.
+// Mappers are deleted first so that a mapper can be replaced by another one of the same name.
+func applyProtocolMappers(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient string, plan protocolMapperPlan) error {
+	for _, mapper := range plan.delete {
+		err := gc.DeleteClientProtocolMapper(ctx, token, realm, idOfClient, gocloak.PString(mapper.ID))
+		if err != nil && !keycloak.IsNotFound(err) {
+			return fmt.Errorf("failed to delete protocol mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	for _, mapper := range plan.update {
+		if err := gc.UpdateClientProtocolMapper(ctx, token, realm, idOfClient, gocloak.PString(mapper.ID), mapper); err != nil {
+			return fmt.Errorf("failed to update protocol mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	for _, mapper := range plan.create {
+		if _, err := gc.CreateClientProtocolMapper(ctx, token, realm, idOfClient, mapper); err != nil {
+			return fmt.Errorf("failed to create protocol mapper %s: %w", gocloak.PString(mapper.Name), err)
+		}
+	}
+	return nil
+}
+
+// toProtocolMappers converts the protocol mappers of a spec to GoCloak protocol mappers.
+func toProtocolMappers(mappers []keycloakv1.ProtocolMapperRepresentation) []gocloak.ProtocolMapperRepresentation {
+	if mappers == nil {
+		return nil
+	}
+	converted := make([]gocloak.ProtocolMapperRepresentation, len(mappers))
+	for i, pm := range mappers {
+		converted[i] = gocloak.ProtocolMapperRepresentation{
+			ID:             pm.ID,
+			Name:           pm.Name,
+			Protocol:       pm.Protocol,
+			ProtocolMapper: pm.ProtocolMapper,
+			Config:         &pm.Config,
+		}
+	}
+	return converted
+}
+
+// protocolMappers returns the protocol mappers of a client.
+func protocolMappers(c *gocloak.Client) []gocloak.ProtocolMapperRepresentation {
+	if c == nil || c.ProtocolMappers == nil {
+		return nil
+	}
+	return *c.ProtocolMappers
+}
+
+// clientProtocol returns the protocol of a client, Keycloak defaults it to OpenID Connect.
+func clientProtocol(c *gocloak.Client) string {
+	if protocol := gocloak.PString(c.Protocol); protocol != "" {
+		return protocol
+	}
+	return "openid-connect"
+}
@@ -0,0 +1,508 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const realmFinalizer = "keycloak.pewty.fr/finalizer"
+
+// realmRefIndex indexes the resources of a realm by the name of the Realm they reference.
+const realmRefIndex = ".spec.realmRef.name"
+
+// realmSMTPSecretIndex indexes realms by the name of the Secret holding their SMTP password.
+const realmSMTPSecretIndex = ".spec.smtpServer.passwordSecretRef.name"
+
+// realmDiffIgnoredFields are the realm fields never compared with Keycloak: the internal ID, and the SMTP
+// server whose password Keycloak does not disclose.
+var realmDiffIgnoredFields = []string{"id", "smtpServer"}
+
+// RealmReconciler reconciles a Realm object
+type RealmReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to realms whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often realms are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=realms,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=realms/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=realms/finalizers,verbs=update
+
+// Reconcile makes the Keycloak realm match the Realm resource: it creates the realm, updates the fields
+// the spec sets, and deletes the realm with the resource unless it must be retained.
+func (r *RealmReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var realm keycloakv1.Realm
+	if err := r.Get(ctx, req.NamespacedName, &realm); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Realm resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get Realm resource")
+		return ctrl.Result{}, err
+	}
+
+	if !realm.DeletionTimestamp.IsZero() {
+		return finalize(ctx, r.Client, r.Recorder, &realm, realmFinalizer, realm.Spec.DeletionPolicy, r.DefaultDeletionPolicy,
+			"Keycloak realm "+realmName(&realm), func() error { return r.cleanupKeycloak(ctx, &realm) })
+	}
+
+	if !controllerutil.ContainsFinalizer(&realm, realmFinalizer) {
+		controllerutil.AddFinalizer(&realm, realmFinalizer)
+		if err := r.Update(ctx, &realm); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	password, passwordVersion, err := r.getSMTPPassword(ctx, &realm)
+	if err != nil {
+		logger.Error(err, "Failed to get SMTP password from secret")
+		setReady(ctx, r.Client, &realm, metav1.ConditionFalse, "SecretReadFailed", fmt.Sprintf("Failed to read secret: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, &realm, realm.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gc := conn.Client
+	name := realmName(&realm)
+
+	existing, err := r.findRealm(ctx, gc, token, &realm)
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak realms")
+		setReady(ctx, r.Client, &realm, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query realm: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	desired := desiredRealm(&realm, password)
+	if existing == nil {
+		logger.Info("Creating realm in Keycloak", "realm", name)
+		if _, err := gc.CreateRealm(ctx, token, desired); err != nil {
+			logger.Error(err, "Failed to create realm in Keycloak")
+			setReady(ctx, r.Client, &realm, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			return ctrl.Result{}, err
+		}
+		created, err := gc.GetRealm(ctx, token, name)
+		if err != nil {
+			logger.Error(err, "Failed to get created realm details")
+			setReady(ctx, r.Client, &realm, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Realm created but failed to retrieve: %v", err))
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully created realm in Keycloak", "realm", name)
+
+		markRealmSynced(&realm, created, passwordVersion)
+		setReady(ctx, r.Client, &realm, metav1.ConditionTrue, "Created", "Realm successfully created in Keycloak")
+		return ctrl.Result{RequeueAfter: resyncInterval(realm.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	adopting, ok := claimObject(ctx, r.Client, r.Recorder, &realm, realm.Spec.AdoptionPolicy, realmAttributes(existing),
+		"Keycloak realm "+name)
+	if !ok {
+		return ctrl.Result{RequeueAfter: resyncInterval(realm.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	drifted := diffRealm(desired, *existing)
+	// A new name in the spec renames the realm, while a realm renamed in Keycloak is not found
+	renaming := gocloak.PString(existing.Realm) != name
+	if renaming {
+		logger.Info("Renaming realm in Keycloak", "from", gocloak.PString(existing.Realm), "to", name)
+	}
+	// The SMTP password is not disclosed by Keycloak, it is sent again when its Secret changes
+	passwordChanged := passwordVersion != realm.Status.SMTPPasswordVersion
+
+	// Differences on a resource already applied at this generation were made in Keycloak directly
+	if len(drifted) > 0 && !adopting && !renaming && isSynced(realm.Status.Conditions, realm.Generation) {
+		if !recordDrift(ctx, r.Recorder, &realm, &realm.Status.Conditions, syncDriftPolicy(realm.Spec.SyncPolicy), drifted) {
+			if err := writeStatus(ctx, r.Client, &realm); err != nil {
+				logger.Error(err, "Failed to update Realm status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resyncInterval(realm.Spec.SyncPolicy, r.ResyncInterval)}, nil
+		}
+	} else {
+		clearDrift(&realm.Status.Conditions, realm.Generation)
+	}
+
+	if len(drifted) > 0 || passwordChanged {
+		logger.Info("Updating realm in Keycloak", "realm", name, "fields", drifted)
+		desired.ID = existing.ID
+		if err := conn.UpdateRealm(ctx, token, gocloak.PString(existing.Realm), desired); err != nil {
+			logger.Error(err, "Failed to update realm in Keycloak")
+			setReady(ctx, r.Client, &realm, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully updated realm in Keycloak", "realm", name)
+	}
+
+	existing.Realm = &name
+	markRealmSynced(&realm, existing, passwordVersion)
+	if len(drifted) > 0 || passwordChanged {
+		setReady(ctx, r.Client, &realm, metav1.ConditionTrue, "Updated", "Realm successfully updated in Keycloak")
+	} else {
+		setReady(ctx, r.Client, &realm, metav1.ConditionTrue, "UpToDate", "Realm is up to date in Keycloak")
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval(realm.Spec.SyncPolicy, r.ResyncInterval)}, nil
+}
+
+// cleanupKeycloak deletes the realm from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *RealmReconciler) cleanupKeycloak(ctx context.Context, realm *keycloakv1.Realm) error {
+	conn, token, err := connect(ctx, r.Client, r.Connections, realm, realm.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	return deleteOwned(ctx, r.Client, realm, "Keycloak realm "+realmName(realm),
+		func() (*gocloak.RealmRepresentation, error) { return r.findRealm(ctx, conn.Client, token, realm) },
+		realmAttributes,
+		func(existing *gocloak.RealmRepresentation) error {
+			return conn.Client.DeleteRealm(ctx, token, gocloak.PString(existing.Realm))
+		})
+}
+
+// findRealm looks up the Keycloak realm of the Realm resource. The name recorded in the status is
+// preferred, so that changing spec.realm renames the realm instead of creating a new one.
+// It returns nil when the realm does not exist.
+func (r *RealmReconciler) findRealm(ctx context.Context, gc *gocloak.GoCloak, token string, realm *keycloakv1.Realm) (*gocloak.RealmRepresentation, error) {
+	names := []string{realmName(realm)}
+	if realm.Status.Realm != "" && realm.Status.Realm != names[0] {
+		names = []string{realm.Status.Realm, names[0]}
+	}
+	for _, name := range names {
+		existing, err := gc.GetRealm(ctx, token, name)
+		if err == nil {
+			return existing, nil
+		}
+		if !keycloak.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// getSMTPPassword reads the SMTP password from the referenced Secret. It returns the password with the
+// resource version of the Secret, both empty when the spec references none.
+func (r *RealmReconciler) getSMTPPassword(ctx context.Context, realm *keycloakv1.Realm) (string, string, error) {
+	if realm.Spec.SMTPServer == nil || realm.Spec.SMTPServer.PasswordSecretRef == nil {
+		return "", "", nil
+	}
+	ref := realm.Spec.SMTPServer.PasswordSecretRef
+	key := ref.Key
+	if key == "" {
+		key = "password"
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: realm.Namespace}, secret); err != nil {
+		return "", "", fmt.Errorf("failed to get secret %s: %w", ref.Name, err)
+	}
+	password, ok := secret.Data[key]
+	if !ok {
+		return "", "", fmt.Errorf("key %s not found in secret %s", key, ref.Name)
+	}
+	return string(password), secret.ResourceVersion, nil
+}
+
+// realmName returns the name of the Keycloak realm of a Realm resource.
+func realmName(realm *keycloakv1.Realm) string {
+	if realm.Spec.Realm != "" {
+		return realm.Spec.Realm
+	}
+	return realm.Name
+}
+
+// resolveRealm sets *realm to the realm of the Realm resource ref, in the namespace of obj, and reports
+// whether that Realm is Ready. An object being deleted falls back to synced, the realm it was last synced
+// to, once the Realm is gone. Objects without a realmRef are always ready.
+func resolveRealm(ctx context.Context, reader client.Reader, obj client.Object, ref *keycloakv1.RealmReference, realm **string, synced string) (bool, error) {
+	if ref == nil {
+		return true, nil
+	}
+
+	var referenced keycloakv1.Realm
+	if err := reader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: obj.GetNamespace()}, &referenced); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get Realm %s: %w", ref.Name, err)
+		}
+		if obj.GetDeletionTimestamp() != nil && *realm == nil && synced != "" {
+			*realm = &synced
+		}
+		return false, nil
+	}
+
+	name := realmName(&referenced)
+	if *realm != nil && **realm != name {
+		return false, fmt.Errorf("realm %s does not match realm %s of Realm %s", **realm, name, ref.Name)
+	}
+	*realm = &name
+	return meta.IsStatusConditionTrue(referenced.Status.Conditions, "Ready"), nil
+}
+
+// indexRealmRef indexes the resources of the type of obj by the Realm they reference, as returned by ref,
+// so that they are reconciled when it becomes Ready.
+func indexRealmRef(mgr ctrl.Manager, obj client.Object, ref func(client.Object) *keycloakv1.RealmReference) error {
+	return mgr.GetFieldIndexer().IndexField(context.Background(), obj, realmRefIndex, func(obj client.Object) []string {
+		if ref := ref(obj); ref != nil {
+			return []string{ref.Name}
+		}
+		return nil
+	})
+}
+
+// realmReadinessChanged filters the Realm events that matter to the resources referencing it.
+var realmReadinessChanged = builder.WithPredicates(realmChanged)
+
+// realmChanged keeps the updates of a Realm that make it Ready or no longer Ready, or change the name of its
+// Keycloak realm.
+var realmChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		old, ok := e.ObjectOld.(*keycloakv1.Realm)
+		if !ok {
+			return true
+		}
+		updated, ok := e.ObjectNew.(*keycloakv1.Realm)
+		if !ok {
+			return true
+		}
+		return meta.IsStatusConditionTrue(old.Status.Conditions, "Ready") !=
+			meta.IsStatusConditionTrue(updated.Status.Conditions, "Ready") ||
+			realmName(old) != realmName(updated)
+	},
+}
+
+// requestsForRealm returns a map function listing, with list, the resources referencing a Realm and
+// returning their reconcile requests.
+func requestsForRealm(c client.Client, list client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, realm client.Object) []reconcile.Request {
+		list := list.DeepCopyObject().(client.ObjectList)
+		if err := c.List(ctx, list, client.InNamespace(realm.GetNamespace()),
+			client.MatchingFields{realmRefIndex: realm.GetName()}); err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to list resources referencing Realm", "realm", realm.GetName())
+			return nil
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to list resources referencing Realm", "realm", realm.GetName())
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(items))
+		for _, item := range items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item.(client.Object))})
+		}
+		return requests
+	}
+}
+
+// realmAttributes returns the attributes of a Keycloak realm, or nil when it has none.
+func realmAttributes(realm *gocloak.RealmRepresentation) map[string]string {
+	if realm.Attributes == nil {
+		return nil
+	}
+	return *realm.Attributes
+}
+
+// desiredRealm returns the Keycloak realm described by the spec, marked as managed by realm
+func desiredRealm(realm *keycloakv1.Realm, smtpPassword string) gocloak.RealmRepresentation {
+	spec := &realm.Spec
+	attributes := withOwner(spec.Attributes, realm)
+	desired := gocloak.RealmRepresentation{
+		Realm:                               gocloak.StringP(realmName(realm)),
+		Enabled:                             spec.Enabled,
+		DisplayName:                         spec.DisplayName,
+		DisplayNameHTML:                     spec.DisplayNameHTML,
+		Attributes:                          &attributes,
+		SslRequired:                         spec.SslRequired,
+		RegistrationAllowed:                 spec.RegistrationAllowed,
+		RegistrationEmailAsUsername:         spec.RegistrationEmailAsUsername,
+		EditUsernameAllowed:                 spec.EditUsernameAllowed,
+		ResetPasswordAllowed:                spec.ResetPasswordAllowed,
+		RememberMe:                          spec.RememberMe,
+		VerifyEmail:                         spec.VerifyEmail,
+		LoginWithEmailAllowed:               spec.LoginWithEmailAllowed,
+		DuplicateEmailsAllowed:              spec.DuplicateEmailsAllowed,
+		DefaultSignatureAlgorithm:           spec.DefaultSignatureAlgorithm,
+		RevokeRefreshToken:                  spec.RevokeRefreshToken,
+		RefreshTokenMaxReuse:                intPtr(spec.RefreshTokenMaxReuse),
+		AccessTokenLifespan:                 intPtr(spec.AccessTokenLifespan),
+		AccessTokenLifespanForImplicitFlow:  intPtr(spec.AccessTokenLifespanForImplicitFlow),
+		AccessCodeLifespan:                  intPtr(spec.AccessCodeLifespan),
+		AccessCodeLifespanUserAction:        intPtr(spec.AccessCodeLifespanUserAction),
+		AccessCodeLifespanLogin:             intPtr(spec.AccessCodeLifespanLogin),
+		ActionTokenGeneratedByUserLifespan:  intPtr(spec.ActionTokenGeneratedByUserLifespan),
+		ActionTokenGeneratedByAdminLifespan: intPtr(spec.ActionTokenGeneratedByAdminLifespan),
+		SsoSessionIdleTimeout:               intPtr(spec.SsoSessionIdleTimeout),
+		SsoSessionMaxLifespan:               intPtr(spec.SsoSessionMaxLifespan),
+		SsoSessionIdleTimeoutRememberMe:     intPtr(spec.SsoSessionIdleTimeoutRememberMe),
+		SsoSessionMaxLifespanRememberMe:     intPtr(spec.SsoSessionMaxLifespanRememberMe),
+		OfflineSessionIdleTimeout:           intPtr(spec.OfflineSessionIdleTimeout),
+		OfflineSessionMaxLifespanEnabled:    spec.OfflineSessionMaxLifespanEnabled,
+		OfflineSessionMaxLifespan:           intPtr(spec.OfflineSessionMaxLifespan),
+		BruteForceProtected:                 spec.BruteForceProtected,
+		PermanentLockout:                    spec.PermanentLockout,
+		MaxFailureWaitSeconds:               intPtr(spec.MaxFailureWaitSeconds),
+		MinimumQuickLoginWaitSeconds:        intPtr(spec.MinimumQuickLoginWaitSeconds),
+		WaitIncrementSeconds:                intPtr(spec.WaitIncrementSeconds),
+		QuickLoginCheckMilliSeconds:         spec.QuickLoginCheckMilliSeconds,
+		MaxDeltaTimeSeconds:                 intPtr(spec.MaxDeltaTimeSeconds),
+		FailureFactor:                       intPtr(spec.FailureFactor),
+		LoginTheme:                          spec.LoginTheme,
+		AccountTheme:                        spec.AccountTheme,
+		AdminTheme:                          spec.AdminTheme,
+		EmailTheme:                          spec.EmailTheme,
+		InternationalizationEnabled:         spec.InternationalizationEnabled,
+		DefaultLocale:                       spec.DefaultLocale,
+	}
+	if len(spec.SupportedLocales) > 0 {
+		desired.SupportedLocales = &spec.SupportedLocales
+	}
+	if smtp := spec.SMTPServer; smtp != nil {
+		server := map[string]string{
+			"host":     smtp.Host,
+			"from":     smtp.From,
+			"ssl":      strconv.FormatBool(smtp.SSL),
+			"starttls": strconv.FormatBool(smtp.StartTLS),
+			"auth":     strconv.FormatBool(smtp.Auth),
+		}
+		optional := map[string]string{
+			"port":               smtp.Port,
+			"fromDisplayName":    smtp.FromDisplayName,
+			"replyTo":            smtp.ReplyTo,
+			"replyToDisplayName": smtp.ReplyToDisplayName,
+			"envelopeFrom":       smtp.EnvelopeFrom,
+			"user":               smtp.User,
+			"password":           smtpPassword,
+		}
+		for key, value := range optional {
+			if value != "" {
+				server[key] = value
+			}
+		}
+		desired.SMTPServer = &server
+	}
+	return desired
+}
+
+// diffRealm returns the JSON names of the fields of desired that differ in the live Keycloak realm.
+// The SMTP server is compared as a whole, except for its password.
+func diffRealm(desired, live gocloak.RealmRepresentation) []string {
+	drifted := diffFields(desired, live, realmDiffIgnoredFields...)
+	if desired.SMTPServer != nil {
+		desiredServer := maps.Clone(*desired.SMTPServer)
+		delete(desiredServer, "password")
+		var liveServer map[string]string
+		if live.SMTPServer != nil {
+			liveServer = maps.Clone(*live.SMTPServer)
+			delete(liveServer, "password")
+		}
+		if !maps.Equal(desiredServer, liveServer) {
+			drifted = append(drifted, "smtpServer")
+		}
+	}
+	return drifted
+}
+
+// markRealmSynced records in the status the Keycloak realm the resource is now in sync with.
+func markRealmSynced(realm *keycloakv1.Realm, applied *gocloak.RealmRepresentation, passwordVersion string) {
+	now := metav1.Now()
+	realm.Status.ID = gocloak.PString(applied.ID)
+	realm.Status.Realm = gocloak.PString(applied.Realm)
+	realm.Status.ObservedGeneration = realm.Generation
+	realm.Status.LastSyncedTime = &now
+	realm.Status.SMTPPasswordVersion = passwordVersion
+}
+
+// intPtr converts an optional int32 to the optional int used by GoCloak.
+func intPtr(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	converted := int(*value)
+	return &converted
+}
+
+// realmSMTPSecret returns the name of the Secret holding the SMTP password of a Realm, if any.
+func realmSMTPSecret(obj client.Object) []string {
+	if smtp := obj.(*keycloakv1.Realm).Spec.SMTPServer; smtp != nil && smtp.PasswordSecretRef != nil {
+		return []string{smtp.PasswordSecretRef.Name}
+	}
+	return nil
+}
+
+// realmsForSecret returns the reconcile requests of the realms reading their SMTP password from secret.
+func (r *RealmReconciler) realmsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var realms keycloakv1.RealmList
+	if err := r.List(ctx, &realms, client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{realmSMTPSecretIndex: secret.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list Realms reading Secret", "secret", secret.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(realms.Items))
+	for _, realm := range realms.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&realm)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RealmReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index realms by the Secret of their SMTP password, to send it again when it changes
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &keycloakv1.Realm{}, realmSMTPSecretIndex,
+		realmSMTPSecret); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.Realm{}, ignoreStatusUpdates).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.realmsForSecret)).
+		Named("realm").
+		Complete(r)
+}
@@ -0,0 +1,416 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("Realm Controller", func() {
+	Context("When converting a Realm to a Keycloak realm", func() {
+		newRealm := func() *keycloakv1.Realm {
+			lifespan := int32(300)
+			return &keycloakv1.Realm{
+				ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "default", UID: "realm-uid"},
+				Spec: keycloakv1.RealmSpec{
+					DisplayName:         strPtr("Production"),
+					AccessTokenLifespan: &lifespan,
+					SupportedLocales:    []string{"en", "fr"},
+					Attributes:          map[string]string{"frontendUrl": "https://sso.example.com"},
+					SMTPServer: &keycloakv1.SMTPServer{
+						Host:              "smtp.example.com",
+						From:              "no-reply@example.com",
+						Auth:              true,
+						User:              "no-reply",
+						PasswordSecretRef: &keycloakv1.PasswordSecretReference{Name: "smtp"},
+					},
+				},
+			}
+		}
+
+		It("Should map the spec, mark ownership and default the name", func() {
+			desired := desiredRealm(newRealm(), "secret")
+			Expect(*desired.Realm).To(Equal("production"))
+			Expect(*desired.DisplayName).To(Equal("Production"))
+			Expect(*desired.AccessTokenLifespan).To(Equal(300))
+			Expect(*desired.SupportedLocales).To(ConsistOf("en", "fr"))
+			Expect(desired.Enabled).To(BeNil())
+			Expect(*desired.Attributes).To(HaveKeyWithValue("frontendUrl", "https://sso.example.com"))
+			Expect(*desired.Attributes).To(HaveKeyWithValue(ownerUIDAttribute, "realm-uid"))
+			Expect(*desired.SMTPServer).To(Equal(map[string]string{
+				"host": "smtp.example.com", "from": "no-reply@example.com", "user": "no-reply", "password": "secret",
+				"ssl": "false", "starttls": "false", "auth": "true",
+			}))
+
+			realm := newRealm()
+			realm.Spec.Realm = "prod"
+			Expect(*desiredRealm(realm, "").Realm).To(Equal("prod"))
+		})
+
+		It("Should compare the SMTP server without its password", func() {
+			desired := desiredRealm(newRealm(), "secret")
+			live := desiredRealm(newRealm(), "")
+			live.ID = gocloak.StringP("realm-id")
+			(*live.SMTPServer)["password"] = maskedSecret
+			Expect(diffRealm(desired, live)).To(BeEmpty())
+
+			delete(*live.SMTPServer, "user")
+			live.AccessTokenLifespan = gocloak.IntP(60)
+			Expect(diffRealm(desired, live)).To(Equal([]string{"accessTokenLifespan", "smtpServer"}))
+		})
+	})
+
+	Context("When looking up the Keycloak realm", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/admin/realms/old-name":
+					_, _ = fmt.Fprint(w, `{"id":"old-id","realm":"old-name"}`)
+				case "/admin/realms/production":
+					_, _ = fmt.Fprint(w, `{"id":"production-id","realm":"production"}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Realm not found."}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newRealm := func(name, synced string) *keycloakv1.Realm {
+			return &keycloakv1.Realm{
+				Spec:   keycloakv1.RealmSpec{Realm: name},
+				Status: keycloakv1.RealmStatus{Realm: synced},
+			}
+		}
+
+		It("Should prefer the name recorded in the status, to rename the realm", func() {
+			found, err := (&RealmReconciler{}).findRealm(context.Background(), conn.Client, "token", newRealm("production", "old-name"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*found.ID).To(Equal("old-id"))
+		})
+
+		It("Should fall back to the name of the spec when the recorded realm is gone", func() {
+			found, err := (&RealmReconciler{}).findRealm(context.Background(), conn.Client, "token", newRealm("production", "deleted"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*found.ID).To(Equal("production-id"))
+		})
+
+		It("Should report a missing realm", func() {
+			found, err := (&RealmReconciler{}).findRealm(context.Background(), conn.Client, "token", newRealm("unknown", ""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+	})
+
+	Context("When syncing a Realm with Keycloak", func() {
+		var (
+			fk         *fakeKeycloak
+			c          *fakeCluster
+			reconciler *RealmReconciler
+			req        ctrl.Request
+		)
+
+		// reconcile runs the reconciler the given number of times, and returns the Realm resource
+		reconcile := func(times int) *keycloakv1.Realm {
+			for range times {
+				_, err := reconciler.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			realm := &keycloakv1.Realm{}
+			Expect(c.Get(context.Background(), req.NamespacedName, realm)).To(Succeed())
+			return realm
+		}
+
+		setup := func(realm *keycloakv1.Realm, objs ...client.Object) {
+			fk = newFakeKeycloak()
+			realm.Namespace = "default"
+			realm.UID = "production-uid"
+			realm.Generation = 1
+			c = newFakeCluster(append(objs, realm)...)
+			reconciler = &RealmReconciler{
+				Client:         c,
+				Scheme:         scheme.Scheme,
+				Recorder:       newFakeRecorder(),
+				Connections:    &ConnectionResolver{Client: c, Default: fk.conn},
+				ResyncInterval: 10 * time.Minute,
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: realm.Name, Namespace: "default"}}
+		}
+
+		It("Should create the realm, update it with the spec and delete it with the resource", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Realm{ObjectMeta: metav1.ObjectMeta{Name: "production"}})
+
+			realm := reconcile(2)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(fk.recorded()).To(Equal([]string{"POST /admin/realms"}))
+			Expect(fk.get("/admin/realms/production")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, "production-uid"))
+
+			fk.reset()
+			realm.Spec.DisplayName = strPtr("Production")
+			realm.Generation = 2
+			Expect(c.Update(ctx, realm)).To(Succeed())
+			realm = reconcile(1)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT /admin/realms/production"}))
+			Expect(fk.get("/admin/realms/production")).To(HaveKeyWithValue("displayName", "Production"))
+
+			fk.reset()
+			Expect(c.Delete(ctx, realm)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(Equal([]string{"DELETE /admin/realms/production"}))
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, realm))).To(BeTrue())
+
+			// The resource is gone, there is nothing left to reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should keep the realm in Keycloak when the resource retains it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Realm{
+				ObjectMeta: metav1.ObjectMeta{Name: "production"},
+				Spec:       keycloakv1.RealmSpec{DeletionPolicy: keycloakv1.DeletionPolicyRetain},
+			})
+			realm := reconcile(2)
+
+			fk.reset()
+			Expect(c.Delete(ctx, realm)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(fk.get("/admin/realms/production")).NotTo(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, realm))).To(BeTrue())
+		})
+
+		DescribeTable("Should handle the fields changed in Keycloak according to the drift policy",
+			func(policy keycloakv1.DriftPolicy, reason string, displayName string) {
+				setup(&keycloakv1.Realm{
+					ObjectMeta: metav1.ObjectMeta{Name: "production"},
+					Spec: keycloakv1.RealmSpec{
+						DisplayName: strPtr("Production"),
+						SyncPolicy:  &keycloakv1.SyncPolicy{DriftPolicy: policy},
+					},
+				})
+				reconcile(2)
+
+				fk.get("/admin/realms/production")["displayName"] = "Changed in Keycloak"
+				realm := reconcile(1)
+				drifted := meta.FindStatusCondition(realm.Status.Conditions, "Drifted")
+				Expect(drifted.Reason).To(Equal(reason))
+				Expect(drifted.Message).To(ContainSubstring("displayName"))
+				Expect(fk.get("/admin/realms/production")).To(HaveKeyWithValue("displayName", displayName))
+			},
+			Entry("Correct by default", keycloakv1.DriftPolicy(""), "DriftCorrected", "Production"),
+			Entry("Report", keycloakv1.DriftPolicyReport, "DriftDetected", "Changed in Keycloak"),
+		)
+
+		It("Should refuse a realm managed by another resource until asked to take it over", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.Realm{ObjectMeta: metav1.ObjectMeta{Name: "production"}})
+			fk.put("/admin/realms/production", `{"id":"production-id","realm":"production","attributes":{`+
+				`"keycloak.pewty.fr/owner-uid":"other-uid","keycloak.pewty.fr/owner":"default/other"}}`)
+
+			realm := reconcile(2)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("Conflict"))
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Conflict").Message).To(Equal(
+				"Keycloak realm production is already managed by default/other, set adoptionPolicy to Always to take it over"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			realm.Spec.AdoptionPolicy = keycloakv1.AdoptionPolicyAlways
+			realm.Generation = 2
+			Expect(c.Update(ctx, realm)).To(Succeed())
+			realm = reconcile(1)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(meta.IsStatusConditionFalse(realm.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(fk.get("/admin/realms/production")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, "production-uid"))
+		})
+
+		It("Should not write the status while the realm is up to date", func() {
+			setup(&keycloakv1.Realm{ObjectMeta: metav1.ObjectMeta{Name: "production"}})
+
+			realm := reconcile(2)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			realm = reconcile(1)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("UpToDate"))
+			synced, writes := realm.Status.LastSyncedTime, c.statusWrites
+			fk.reset()
+
+			realm = reconcile(1)
+			Expect(c.statusWrites).To(Equal(writes))
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(realm.Status.LastSyncedTime).To(Equal(synced))
+		})
+
+		It("Should send the SMTP password again when its Secret changes", func() {
+			ctx := context.Background()
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "smtp", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("first")},
+			}
+			setup(&keycloakv1.Realm{
+				ObjectMeta: metav1.ObjectMeta{Name: "production"},
+				Spec: keycloakv1.RealmSpec{SMTPServer: &keycloakv1.SMTPServer{
+					Host:              "smtp.example.com",
+					From:              "no-reply@example.com",
+					Auth:              true,
+					User:              "no-reply",
+					PasswordSecretRef: &keycloakv1.PasswordSecretReference{Name: "smtp"},
+				}},
+			}, secret)
+			Expect(meta.FindStatusCondition(reconcile(2).Status.Conditions, "Ready").Reason).To(Equal("Created"))
+
+			// Only the Secret of the SMTP password enqueues the realm
+			Expect(reconciler.realmsForSecret(ctx, secret)).To(Equal([]ctrl.Request{req}))
+			other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+			Expect(reconciler.realmsForSecret(ctx, other)).To(BeEmpty())
+
+			fk.reset()
+			secret.Data["password"] = []byte("second")
+			Expect(c.Update(ctx, secret)).To(Succeed())
+			realm := reconcile(1)
+			Expect(meta.FindStatusCondition(realm.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT /admin/realms/production"}))
+			Expect(fk.body("PUT /admin/realms/production")).To(ContainSubstring(`"password":"second"`))
+		})
+	})
+
+	Context("When a Realm referenced by other resources changes", func() {
+		update := func(old, updated *keycloakv1.Realm) bool {
+			return realmChanged.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+		}
+
+		It("Should only enqueue them when it becomes Ready or changes realm", func() {
+			old := &keycloakv1.Realm{ObjectMeta: metav1.ObjectMeta{Name: "production", Namespace: "default"}}
+			updated := old.DeepCopy()
+			updated.Status.LastSyncedTime = &metav1.Time{Time: time.Now()}
+			Expect(update(old, updated)).To(BeFalse())
+
+			meta.SetStatusCondition(&updated.Status.Conditions, metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionFalse, Reason: "CreationFailed",
+			})
+			Expect(update(old, updated)).To(BeFalse())
+
+			ready := updated.DeepCopy()
+			meta.SetStatusCondition(&ready.Status.Conditions, metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "Created",
+			})
+			Expect(update(updated, ready)).To(BeTrue())
+			Expect(update(ready, updated)).To(BeTrue())
+
+			renamed := ready.DeepCopy()
+			renamed.Spec.Realm = "prod"
+			Expect(update(ready, renamed)).To(BeTrue())
+		})
+	})
+
+	Context("When a Client references a Realm", func() {
+		ctx := context.Background()
+		const realmResourceName = "test-referenced-realm"
+
+		AfterEach(func() {
+			realm := &keycloakv1.Realm{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: realmResourceName, Namespace: "default"}, realm); err == nil {
+				Expect(k8sClient.Delete(ctx, realm)).To(Succeed())
+			}
+		})
+
+		newClient := func() *keycloakv1.Client {
+			return &keycloakv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-realm-ref-client", Namespace: "default"},
+				Spec: keycloakv1.ClientSpec{
+					RealmRef:  &keycloakv1.RealmReference{Name: realmResourceName},
+					SecretRef: keycloakv1.ClientSecretReference{Name: "realm-ref-secret"},
+				},
+			}
+		}
+
+		It("Should wait for the Realm to be Ready and take its realm", func() {
+			reconciler := &ClientReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			kcClient := newClient()
+			ready, err := reconciler.resolveRealmRef(ctx, kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeFalse())
+			Expect(kcClient.Spec.Realm).To(BeNil())
+
+			realm := &keycloakv1.Realm{
+				ObjectMeta: metav1.ObjectMeta{Name: realmResourceName, Namespace: "default"},
+				Spec:       keycloakv1.RealmSpec{Realm: "production"},
+			}
+			Expect(k8sClient.Create(ctx, realm)).To(Succeed())
+			ready, err = reconciler.resolveRealmRef(ctx, kcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeFalse())
+			Expect(*kcClient.Spec.Realm).To(Equal("production"))
+
+			meta.SetStatusCondition(&realm.Status.Conditions, metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "Created", Message: "Realm created",
+			})
+			Expect(k8sClient.Status().Update(ctx, realm)).To(Succeed())
+			ready, err = reconciler.resolveRealmRef(ctx, newClient())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ready).To(BeTrue())
+		})
+
+		It("Should refuse a realm that does not match the Realm", func() {
+			realm := &keycloakv1.Realm{
+				ObjectMeta: metav1.ObjectMeta{Name: realmResourceName, Namespace: "default"},
+				Spec:       keycloakv1.RealmSpec{Realm: "production"},
+			}
+			Expect(k8sClient.Create(ctx, realm)).To(Succeed())
+
+			kcClient := newClient()
+			kcClient.Spec.Realm = strPtr("staging")
+			_, err := (&ClientReconciler{Client: k8sClient}).resolveRealmRef(ctx, kcClient)
+			Expect(err).To(MatchError(ContainSubstring("does not match realm production")))
+		})
+	})
+})
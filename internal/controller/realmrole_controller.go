@@ -0,0 +1,375 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const realmRoleFinalizer = "keycloak.pewty.fr/finalizer"
+
+// RealmRoleReconciler reconciles a RealmRole object
+type RealmRoleReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to realm roles whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often realm roles are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=realmroles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=realmroles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=realmroles/finalizers,verbs=update
+
+// Reconcile makes the Keycloak realm role match the RealmRole resource: it creates the role, updates its
+// description, attributes and composites, and deletes it with the resource unless it must be retained.
+func (r *RealmRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var role keycloakv1.RealmRole
+	if err := r.Get(ctx, req.NamespacedName, &role); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("RealmRole resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get RealmRole resource")
+		return ctrl.Result{}, err
+	}
+
+	// Take the realm of the referenced Realm resource, only in memory
+	realmReady, err := resolveRealm(ctx, r, &role, role.Spec.RealmRef, &role.Spec.Realm, role.Status.Realm)
+	if err != nil {
+		logger.Error(err, "Failed to resolve realmRef")
+		setReady(ctx, r.Client, &role, metav1.ConditionFalse, "InvalidRealm", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if !role.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &role)
+	}
+
+	if !controllerutil.ContainsFinalizer(&role, realmRoleFinalizer) {
+		// Patch the finalizers only, the realm resolved above must not be written to the spec
+		patch := client.MergeFrom(role.DeepCopy())
+		controllerutil.AddFinalizer(&role, realmRoleFinalizer)
+		if err := r.Patch(ctx, &role, patch); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Wait for the referenced Realm, its changes trigger a new reconciliation
+	if !realmReady {
+		message := fmt.Sprintf("Waiting for Realm %s to be Ready", role.Spec.RealmRef.Name)
+		logger.Info("Realm is not ready, waiting", "realmRef", role.Spec.RealmRef.Name)
+		setReady(ctx, r.Client, &role, metav1.ConditionFalse, "RealmNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, &role, role.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gc := conn.Client
+	realm := *role.Spec.Realm
+	name := realmRoleName(&role)
+
+	existing, err := r.findRealmRole(ctx, gc, token, &role)
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak realm roles")
+		setReady(ctx, r.Client, &role, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query realm role: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	desired := desiredRealmRole(&role)
+	if existing == nil {
+		logger.Info("Creating realm role in Keycloak", "realm", realm, "name", name)
+		if _, err := gc.CreateRealmRole(ctx, token, realm, desired); err != nil {
+			logger.Error(err, "Failed to create realm role in Keycloak")
+			setReady(ctx, r.Client, &role, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			return ctrl.Result{}, err
+		}
+		// Keycloak answers with the name of the role rather than its ID
+		created, err := gc.GetRealmRole(ctx, token, realm, name)
+		if err != nil {
+			logger.Error(err, "Failed to get created realm role details")
+			setReady(ctx, r.Client, &role, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Realm role created but failed to retrieve: %v", err))
+			return ctrl.Result{}, err
+		}
+		id := gocloak.PString(created.ID)
+		// The ID is recorded right away, so that a failure below does not look the role up by name again
+		role.Status.ID = id
+		role.Status.Realm = realm
+
+		plan, clientIDs, err := r.planComposites(ctx, gc, token, &role, "")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncComposites(ctx, gc, token, &role, id, plan, clientIDs); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully created realm role in Keycloak", "name", name, "id", id)
+
+		markRealmRoleSynced(&role, realm, id, name)
+		setReady(ctx, r.Client, &role, metav1.ConditionTrue, "Created", "Realm role successfully created in Keycloak")
+		return ctrl.Result{RequeueAfter: resyncInterval(role.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+	id := gocloak.PString(existing.ID)
+
+	adopting, ok := claimObject(ctx, r.Client, r.Recorder, &role, role.Spec.AdoptionPolicy, firstValues(roleAttributes(existing)),
+		"Keycloak realm role "+name)
+	if !ok {
+		return ctrl.Result{RequeueAfter: resyncInterval(role.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	compositePlan, clientIDs, err := r.planComposites(ctx, gc, token, &role, id)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	fieldsDrifted := diffFields(desired, *existing, "id")
+	drifted := fieldsDrifted
+	if !compositePlan.empty() {
+		drifted = append(drifted, "composites")
+	}
+
+	// Differences on a resource already applied at this generation were made in Keycloak directly.
+	// A new name in the spec renames the role.
+	renaming := gocloak.PString(existing.Name) != name
+	if len(drifted) > 0 && !adopting && !renaming && isSynced(role.Status.Conditions, role.Generation) {
+		if !recordDrift(ctx, r.Recorder, &role, &role.Status.Conditions, syncDriftPolicy(role.Spec.SyncPolicy), drifted) {
+			if err := writeStatus(ctx, r.Client, &role); err != nil {
+				logger.Error(err, "Failed to update RealmRole status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resyncInterval(role.Spec.SyncPolicy, r.ResyncInterval)}, nil
+		}
+	} else {
+		clearDrift(&role.Status.Conditions, role.Generation)
+	}
+
+	if len(drifted) > 0 {
+		logger.Info("Updating realm role in Keycloak", "name", name, "fields", drifted)
+		if len(fieldsDrifted) > 0 {
+			attributes := mergeValues(roleAttributes(existing), *desired.Attributes)
+			desired.Attributes = &attributes
+			if err := gc.UpdateRealmRoleByID(ctx, token, realm, id, desired); err != nil {
+				logger.Error(err, "Failed to update realm role in Keycloak")
+				setReady(ctx, r.Client, &role, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.syncComposites(ctx, gc, token, &role, id, compositePlan, clientIDs); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully updated realm role in Keycloak", "name", name)
+	}
+
+	markRealmRoleSynced(&role, realm, id, name)
+	if len(drifted) > 0 {
+		setReady(ctx, r.Client, &role, metav1.ConditionTrue, "Updated", "Realm role successfully updated in Keycloak")
+	} else {
+		setReady(ctx, r.Client, &role, metav1.ConditionTrue, "UpToDate", "Realm role is up to date in Keycloak")
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval(role.Spec.SyncPolicy, r.ResyncInterval)}, nil
+}
+
+// reconcileDelete removes the realm role from Keycloak and releases the finalizer
+func (r *RealmRoleReconciler) reconcileDelete(ctx context.Context, role *keycloakv1.RealmRole) (ctrl.Result, error) {
+	// Without a realm, the referenced Realm is gone before the realm role was ever synced
+	var cleanup func() error
+	if role.Spec.Realm != nil {
+		cleanup = func() error { return r.cleanupKeycloak(ctx, role) }
+	}
+	return finalize(ctx, r.Client, r.Recorder, role, realmRoleFinalizer, role.Spec.DeletionPolicy,
+		r.DefaultDeletionPolicy, "Keycloak realm role "+realmRoleName(role), cleanup)
+}
+
+// cleanupKeycloak deletes the realm role from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *RealmRoleReconciler) cleanupKeycloak(ctx context.Context, role *keycloakv1.RealmRole) error {
+	conn, token, err := connect(ctx, r.Client, r.Connections, role, role.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	gc := conn.Client
+	return deleteOwned(ctx, r.Client, role, "Keycloak realm role "+realmRoleName(role),
+		func() (*gocloak.Role, error) { return r.findRealmRole(ctx, gc, token, role) },
+		func(existing *gocloak.Role) map[string]string { return firstValues(roleAttributes(existing)) },
+		func(existing *gocloak.Role) error {
+			return gc.DeleteRealmRole(ctx, token, *role.Spec.Realm, gocloak.PString(existing.Name))
+		})
+}
+
+// findRealmRole looks up the Keycloak realm role of the RealmRole resource, by the ID recorded in the status
+// when it was synced to the same realm, else by name. It returns nil when the role does not exist.
+func (r *RealmRoleReconciler) findRealmRole(ctx context.Context, gc *gocloak.GoCloak, token string, role *keycloakv1.RealmRole) (*gocloak.Role, error) {
+	realm := *role.Spec.Realm
+	if role.Status.ID != "" && role.Status.Realm == realm {
+		existing, err := gc.GetRealmRoleByID(ctx, token, realm, role.Status.ID)
+		if err == nil {
+			return existing, nil
+		}
+		if !keycloak.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	existing, err := gc.GetRealmRole(ctx, token, realm, realmRoleName(role))
+	if keycloak.IsNotFound(err) {
+		return nil, nil
+	}
+	return existing, err
+}
+
+// planComposites fetches the composites of the Keycloak role roleID, none when it does not exist yet, and
+// plans their changes. It returns the plan with the internal ID of the clients it references, by clientId,
+// and reports failures in the Ready condition.
+func (r *RealmRoleReconciler) planComposites(ctx context.Context, gc *gocloak.GoCloak, token string, role *keycloakv1.RealmRole, roleID string) (compositePlan, map[string]string, error) {
+	clientIDs := map[string]string{}
+	if role.Spec.Composites == nil {
+		return compositePlan{}, clientIDs, nil
+	}
+
+	realm := *role.Spec.Realm
+	err := resolveCompositeClients(ctx, gc, token, realm, role.Spec.Composites, clientIDs)
+	var live []*gocloak.Role
+	if err == nil && roleID != "" {
+		if live, err = gc.GetCompositeRolesByRoleID(ctx, token, realm, roleID); err != nil {
+			err = fmt.Errorf("failed to get composites of role %s: %w", realmRoleName(role), err)
+		}
+	}
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to get realm role composites from Keycloak")
+		setReady(ctx, r.Client, role, metav1.ConditionFalse, "CompositesFailed", err.Error())
+		return compositePlan{}, nil, err
+	}
+	return planComposites(role.Spec.Composites, live, clientIDs), clientIDs, nil
+}
+
+// syncComposites applies plan to the composites of the Keycloak role roleID and reports failures in the
+// Ready condition, with the CompositeRoleNotFound reason when an included role does not exist. The roles
+// to include are resolved first, and nothing changes when one of them does not exist.
+func (r *RealmRoleReconciler) syncComposites(ctx context.Context, gc *gocloak.GoCloak, token string, role *keycloakv1.RealmRole, roleID string, plan compositePlan, clientIDs map[string]string) error {
+	if plan.empty() {
+		return nil
+	}
+	logger := logf.FromContext(ctx)
+	realm := *role.Spec.Realm
+
+	included, err := resolveCompositeRoles(ctx, gc, token, realm, plan.add, clientIDs)
+	if err == nil && len(plan.remove) > 0 {
+		if err = gc.DeleteClientRoleComposite(ctx, token, realm, roleID, plan.remove); err != nil {
+			err = fmt.Errorf("failed to remove composites of role %s: %w", realmRoleName(role), err)
+		}
+	}
+	if err == nil && len(included) > 0 {
+		if err = gc.AddClientRoleComposite(ctx, token, realm, roleID, included); err != nil {
+			err = fmt.Errorf("failed to add composites of role %s: %w", realmRoleName(role), err)
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to update realm role composites in Keycloak")
+	reason := "CompositesFailed"
+	var notFound *rolesNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "CompositeRoleNotFound"
+		r.Recorder.Eventf(role, nil, corev1.EventTypeWarning, reason, "UpdateComposites", err.Error())
+	}
+	setReady(ctx, r.Client, role, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
+
+// realmRoleName returns the name of the Keycloak role of a RealmRole resource.
+func realmRoleName(role *keycloakv1.RealmRole) string {
+	if role.Spec.Name != "" {
+		return role.Spec.Name
+	}
+	return role.Name
+}
+
+// roleAttributes returns the attributes of a Keycloak role, or nil when it has none.
+func roleAttributes(role *gocloak.Role) map[string][]string {
+	if role.Attributes == nil {
+		return nil
+	}
+	return *role.Attributes
+}
+
+// desiredRealmRole returns the Keycloak role described by the spec, marked as managed by role.
+// Composites are applied separately.
+func desiredRealmRole(role *keycloakv1.RealmRole) gocloak.Role {
+	attributes := withOwnerValues(role.Spec.Attributes, role)
+	desired := gocloak.Role{
+		Name:       gocloak.StringP(realmRoleName(role)),
+		Attributes: &attributes,
+	}
+	if role.Spec.Description != "" {
+		desired.Description = gocloak.StringP(role.Spec.Description)
+	}
+	return desired
+}
+
+// markRealmRoleSynced records in the status the Keycloak role the resource is now in sync with.
+func markRealmRoleSynced(role *keycloakv1.RealmRole, realm, id, name string) {
+	now := metav1.Now()
+	role.Status.ID = id
+	role.Status.Realm = realm
+	role.Status.Name = name
+	role.Status.ObservedGeneration = role.Generation
+	role.Status.LastSyncedTime = &now
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RealmRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexRealmRef(mgr, &keycloakv1.RealmRole{}, func(obj client.Object) *keycloakv1.RealmReference {
+		return obj.(*keycloakv1.RealmRole).Spec.RealmRef
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.RealmRole{}, ignoreStatusUpdates).
+		Watches(&keycloakv1.Realm{}, handler.EnqueueRequestsFromMapFunc(requestsForRealm(r.Client, &keycloakv1.RealmRoleList{})),
+			realmReadinessChanged).
+		Named("realmrole").
+		Complete(r)
+}
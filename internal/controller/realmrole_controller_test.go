@@ -0,0 +1,292 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("RealmRole Controller", func() {
+	Context("When converting a RealmRole to a Keycloak role", func() {
+		It("Should map the spec, mark ownership and default the name", func() {
+			role := &keycloakv1.RealmRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "default", UID: "role-uid"},
+				Spec: keycloakv1.RealmRoleSpec{
+					Attributes: map[string][]string{"department": {"it"}},
+				},
+			}
+			desired := desiredRealmRole(role)
+			Expect(*desired.Name).To(Equal("admin"))
+			Expect(desired.Description).To(BeNil())
+			Expect(*desired.Attributes).To(HaveKeyWithValue("department", []string{"it"}))
+			Expect(firstValues(*desired.Attributes)).To(HaveKeyWithValue(ownerUIDAttribute, "role-uid"))
+			Expect(role.Spec.Attributes).NotTo(HaveKey(ownerUIDAttribute))
+
+			role.Spec.Name = "administrator"
+			role.Spec.Description = "Administrators"
+			desired = desiredRealmRole(role)
+			Expect(*desired.Name).To(Equal("administrator"))
+			Expect(*desired.Description).To(Equal("Administrators"))
+		})
+	})
+
+	Context("When talking to Keycloak", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /admin/realms/demo/roles-by-id/old-id":
+					_, _ = fmt.Fprint(w, `{"id":"old-id","name":"old-name"}`)
+				case "GET /admin/realms/demo/roles/admin":
+					_, _ = fmt.Fprint(w, `{"id":"admin-id","name":"admin"}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Could not find role"}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newRole := func(name, id, realm string) *keycloakv1.RealmRole {
+			return &keycloakv1.RealmRole{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       keycloakv1.RealmRoleSpec{Realm: strPtr("demo")},
+				Status:     keycloakv1.RealmRoleStatus{ID: id, Realm: realm},
+			}
+		}
+
+		It("Should prefer the ID recorded in the status, to rename the role", func() {
+			found, err := (&RealmRoleReconciler{}).findRealmRole(context.Background(), conn.Client, "token", newRole("admin", "old-id", "demo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("old-id"))
+		})
+
+		It("Should look up the role by name in another realm or when it is gone", func() {
+			found, err := (&RealmRoleReconciler{}).findRealmRole(context.Background(), conn.Client, "token", newRole("admin", "old-id", "other"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("admin-id"))
+
+			found, err = (&RealmRoleReconciler{}).findRealmRole(context.Background(), conn.Client, "token", newRole("admin", "deleted-id", "demo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("admin-id"))
+
+			found, err = (&RealmRoleReconciler{}).findRealmRole(context.Background(), conn.Client, "token", newRole("unknown", "", ""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+	})
+
+	Context("When reconciling a RealmRole resource", func() {
+		It("Should reject a RealmRole without realm", func() {
+			resource := &keycloakv1.RealmRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-role-without-realm", Namespace: "default"},
+			}
+			Expect(k8sClient.Create(context.Background(), resource)).To(MatchError(ContainSubstring("realm or realmRef is required")))
+		})
+	})
+
+	Context("When syncing a RealmRole with Keycloak", func() {
+		const rolesPath = "/admin/realms/test-realm/roles"
+		var (
+			fk         *fakeKeycloak
+			c          *fakeCluster
+			reconciler *RealmRoleReconciler
+			req        ctrl.Request
+		)
+
+		// reconcile runs the reconciler the given number of times, and returns the RealmRole resource
+		reconcile := func(times int) *keycloakv1.RealmRole {
+			for range times {
+				_, err := reconciler.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			role := &keycloakv1.RealmRole{}
+			Expect(c.Get(context.Background(), req.NamespacedName, role)).To(Succeed())
+			return role
+		}
+
+		setup := func(role *keycloakv1.RealmRole) {
+			fk = newFakeKeycloak()
+			fk.put(rolesPath+"/viewer", `{"id":"viewer-id","name":"viewer"}`)
+			fk.put(rolesPath+"/editor", `{"id":"editor-id","name":"editor"}`)
+			role.Namespace = "default"
+			role.UID = "admin-uid"
+			role.Generation = 1
+			role.Spec.Realm = strPtr("test-realm")
+			c = newFakeCluster(role)
+			reconciler = &RealmRoleReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: role.Name, Namespace: "default"}}
+		}
+
+		It("Should create the role with its composites, update them with the spec and delete it with the resource", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.RealmRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+				Spec:       keycloakv1.RealmRoleSpec{Composites: &keycloakv1.RoleComposites{Realm: []string{"viewer"}}},
+			})
+
+			role := reconcile(2)
+			Expect(meta.FindStatusCondition(role.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(role.Status.ID).To(Equal("admin-id"))
+			Expect(fk.recorded()).To(Equal([]string{
+				"POST " + rolesPath,
+				"POST /admin/realms/test-realm/roles-by-id/admin-id/composites",
+			}))
+			Expect(fk.get(rolesPath + "/admin")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, ConsistOf("admin-uid")))
+			Expect(fk.get(rolesPath + "/admin/composites/viewer-id")).NotTo(BeNil())
+
+			fk.reset()
+			role.Spec.Description = "Administrators"
+			role.Spec.Composites.Realm = []string{"editor"}
+			role.Generation = 2
+			Expect(c.Update(ctx, role)).To(Succeed())
+			role = reconcile(1)
+			Expect(meta.FindStatusCondition(role.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{
+				"PUT /admin/realms/test-realm/roles-by-id/admin-id",
+				"DELETE /admin/realms/test-realm/roles-by-id/admin-id/composites",
+				"POST /admin/realms/test-realm/roles-by-id/admin-id/composites",
+			}))
+			Expect(fk.get(rolesPath + "/admin")).To(HaveKeyWithValue("description", "Administrators"))
+			Expect(fk.get(rolesPath + "/admin/composites/viewer-id")).To(BeNil())
+			Expect(fk.get(rolesPath + "/admin/composites/editor-id")).NotTo(BeNil())
+
+			fk.reset()
+			Expect(c.Delete(ctx, role)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(Equal([]string{"DELETE " + rolesPath + "/admin"}))
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, role))).To(BeTrue())
+
+			// The resource is gone, there is nothing left to reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should not change the composites while an included role is missing", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.RealmRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+				Spec: keycloakv1.RealmRoleSpec{
+					Composites: &keycloakv1.RoleComposites{Realm: []string{"viewer", "auditor"}},
+				},
+			})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(MatchError(ContainSubstring("auditor")))
+
+			role := &keycloakv1.RealmRole{}
+			Expect(c.Get(ctx, req.NamespacedName, role)).To(Succeed())
+			Expect(meta.FindStatusCondition(role.Status.Conditions, "Ready").Reason).To(Equal("CompositeRoleNotFound"))
+			Expect(fk.recorded()).To(Equal([]string{"POST " + rolesPath}))
+			Expect(fk.get(rolesPath + "/admin/composites/viewer-id")).To(BeNil())
+		})
+
+		It("Should keep the role in Keycloak when the resource retains it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.RealmRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+				Spec:       keycloakv1.RealmRoleSpec{DeletionPolicy: keycloakv1.DeletionPolicyRetain},
+			})
+			role := reconcile(2)
+
+			fk.reset()
+			Expect(c.Delete(ctx, role)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(fk.get(rolesPath + "/admin")).NotTo(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, role))).To(BeTrue())
+		})
+
+		DescribeTable("Should handle the composites changed in Keycloak according to the drift policy",
+			func(policy keycloakv1.DriftPolicy, reason string, editor OmegaMatcher) {
+				setup(&keycloakv1.RealmRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+					Spec: keycloakv1.RealmRoleSpec{
+						Composites: &keycloakv1.RoleComposites{Realm: []string{"viewer"}},
+						SyncPolicy: &keycloakv1.SyncPolicy{DriftPolicy: policy},
+					},
+				})
+				reconcile(2)
+
+				fk.put(rolesPath+"/admin/composites/editor-id", `{"id":"editor-id","name":"editor"}`)
+				role := reconcile(1)
+				drifted := meta.FindStatusCondition(role.Status.Conditions, "Drifted")
+				Expect(drifted.Reason).To(Equal(reason))
+				Expect(drifted.Message).To(ContainSubstring("composites"))
+				Expect(fk.get(rolesPath + "/admin/composites/editor-id")).To(editor)
+			},
+			Entry("Correct by default", keycloakv1.DriftPolicy(""), "DriftCorrected", BeNil()),
+			Entry("Report", keycloakv1.DriftPolicyReport, "DriftDetected", Not(BeNil())),
+		)
+
+		It("Should refuse a role managed by another resource until asked to take it over", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.RealmRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}})
+			fk.put(rolesPath+"/admin", `{"id":"admin-id","name":"admin","attributes":{`+
+				`"keycloak.pewty.fr/owner-uid":["other-uid"],"keycloak.pewty.fr/owner":["default/other"]}}`)
+
+			role := reconcile(2)
+			Expect(meta.FindStatusCondition(role.Status.Conditions, "Ready").Reason).To(Equal("Conflict"))
+			Expect(meta.FindStatusCondition(role.Status.Conditions, "Conflict").Message).To(Equal(
+				"Keycloak realm role admin is already managed by default/other, set adoptionPolicy to Always to take it over"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			role.Spec.AdoptionPolicy = keycloakv1.AdoptionPolicyAlways
+			role.Generation = 2
+			Expect(c.Update(ctx, role)).To(Succeed())
+			role = reconcile(1)
+			Expect(meta.FindStatusCondition(role.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(meta.IsStatusConditionFalse(role.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(fk.get(rolesPath + "/admin")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, ConsistOf("admin-uid")))
+		})
+	})
+})
@@ -0,0 +1,269 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// roleGrants lists the roles, by name, to grant and the role mappings to revoke.
+type roleGrants struct {
+	grant  []string
+	revoke []gocloak.Role
+}
+
+// empty reports whether the role mappings already match.
+func (g roleGrants) empty() bool {
+	return len(g.grant) == 0 && len(g.revoke) == 0
+}
+
+// roleMappingPlan lists the role mappings to change on a user or a group.
+type roleMappingPlan struct {
+	realm roleGrants
+	// clients holds the client role changes by clientId of the client defining the roles
+	clients map[string]roleGrants
+	// clientIDs holds the internal ID of the clients the user or group already has roles of
+	clientIDs map[string]string
+}
+
+// empty reports whether the role mappings already match.
+func (p roleMappingPlan) empty() bool {
+	return p.realm.empty() && len(p.clients) == 0
+}
+
+// drifted returns the JSON names of the realmRoles and clientRoles spec fields the plan changes.
+func (p roleMappingPlan) drifted() []string {
+	var drifted []string
+	if !p.realm.empty() {
+		drifted = append(drifted, "realmRoles")
+	}
+	if len(p.clients) > 0 {
+		drifted = append(drifted, "clientRoles")
+	}
+	return drifted
+}
+
+// planRoleMappings compares the declared realm roles and client roles, by clientId, with live role mappings.
+// Only the roles granted directly are considered, not those inherited through composite roles or groups.
+// When exact, the live roles not declared are revoked, except the realm role keep.
+func planRoleMappings(realmRoles []string, clientRoles map[string][]string, exact bool, live *gocloak.MappingsRepresentation, keep string) roleMappingPlan {
+	plan := roleMappingPlan{clients: map[string]roleGrants{}, clientIDs: map[string]string{}}
+	if live == nil {
+		live = &gocloak.MappingsRepresentation{}
+	}
+
+	var liveRealm []gocloak.Role
+	if live.RealmMappings != nil {
+		liveRealm = *live.RealmMappings
+	}
+	plan.realm = planRoleGrants(realmRoles, liveRealm, exact, keep)
+
+	clientIDs := make(map[string]bool, len(clientRoles)+len(live.ClientMappings))
+	for clientID := range clientRoles {
+		clientIDs[clientID] = true
+	}
+	for clientID, mappings := range live.ClientMappings {
+		if mappings == nil {
+			continue
+		}
+		clientIDs[clientID] = true
+		plan.clientIDs[clientID] = gocloak.PString(mappings.ID)
+	}
+	for clientID := range clientIDs {
+		var liveRoles []gocloak.Role
+		if mappings := live.ClientMappings[clientID]; mappings != nil && mappings.Mappings != nil {
+			liveRoles = *mappings.Mappings
+		}
+		if grants := planRoleGrants(clientRoles[clientID], liveRoles, exact, ""); !grants.empty() {
+			plan.clients[clientID] = grants
+		}
+	}
+	return plan
+}
+
+// planRoleGrants returns the desired roles missing in live and, when exact, the live roles not desired
+// except keep.
+func planRoleGrants(desired []string, live []gocloak.Role, exact bool, keep string) roleGrants {
+	var grants roleGrants
+	liveNames := make([]string, 0, len(live))
+	for _, role := range live {
+		liveNames = append(liveNames, gocloak.PString(role.Name))
+	}
+	for _, name := range desired {
+		if !slices.Contains(liveNames, name) && !slices.Contains(grants.grant, name) {
+			grants.grant = append(grants.grant, name)
+		}
+	}
+	if exact {
+		for _, role := range live {
+			name := gocloak.PString(role.Name)
+			if name != keep && !slices.Contains(desired, name) {
+				grants.revoke = append(grants.revoke, role)
+			}
+		}
+	}
+	return grants
+}
+
+// rolesNotFoundError reports roles, or clients defining them, that do not exist in the realm.
+type rolesNotFoundError struct {
+	names []string
+}
+
+func (e *rolesNotFoundError) Error() string {
+	return fmt.Sprintf("roles not found in realm: %s", strings.Join(e.names, ", "))
+}
+
+// resolvedClientRoles holds the roles to grant of a client, with the internal ID of the client.
+type resolvedClientRoles struct {
+	idOfClient string
+	roles      []gocloak.Role
+}
+
+// roleMapper grants and revokes the role mappings of a user or a group.
+type roleMapper struct {
+	addRealmRoles     func(ctx context.Context, token, realm string, roles []gocloak.Role) error
+	deleteRealmRoles  func(ctx context.Context, token, realm string, roles []gocloak.Role) error
+	addClientRoles    func(ctx context.Context, token, realm, idOfClient string, roles []gocloak.Role) error
+	deleteClientRoles func(ctx context.Context, token, realm, idOfClient string, roles []gocloak.Role) error
+}
+
+// userRoleMapper returns the roleMapper of the user userID.
+func userRoleMapper(gc *gocloak.GoCloak, userID string) roleMapper {
+	return roleMapper{
+		addRealmRoles: func(ctx context.Context, token, realm string, roles []gocloak.Role) error {
+			return gc.AddRealmRoleToUser(ctx, token, realm, userID, roles)
+		},
+		deleteRealmRoles: func(ctx context.Context, token, realm string, roles []gocloak.Role) error {
+			return gc.DeleteRealmRoleFromUser(ctx, token, realm, userID, roles)
+		},
+		addClientRoles: func(ctx context.Context, token, realm, idOfClient string, roles []gocloak.Role) error {
+			return gc.AddClientRolesToUser(ctx, token, realm, idOfClient, userID, roles)
+		},
+		deleteClientRoles: func(ctx context.Context, token, realm, idOfClient string, roles []gocloak.Role) error {
+			return gc.DeleteClientRolesFromUser(ctx, token, realm, idOfClient, userID, roles)
+		},
+	}
+}
+
+// groupRoleMapper returns the roleMapper of the group groupID.
+func groupRoleMapper(gc *gocloak.GoCloak, groupID string) roleMapper {
+	return roleMapper{
+		addRealmRoles: func(ctx context.Context, token, realm string, roles []gocloak.Role) error {
+			return gc.AddRealmRoleToGroup(ctx, token, realm, groupID, roles)
+		},
+		deleteRealmRoles: func(ctx context.Context, token, realm string, roles []gocloak.Role) error {
+			return gc.DeleteRealmRoleFromGroup(ctx, token, realm, groupID, roles)
+		},
+		addClientRoles: func(ctx context.Context, token, realm, idOfClient string, roles []gocloak.Role) error {
+			return gc.AddClientRolesToGroup(ctx, token, realm, idOfClient, groupID, roles)
+		},
+		deleteClientRoles: func(ctx context.Context, token, realm, idOfClient string, roles []gocloak.Role) error {
+			return gc.DeleteClientRoleFromGroup(ctx, token, realm, idOfClient, groupID, roles)
+		},
+	}
+}
+
+// applyRoleMappings applies plan through mapper. Roles to grant are resolved in the realm first, and
+// nothing changes when one of them does not exist.
+func applyRoleMappings(ctx context.Context, gc *gocloak.GoCloak, token, realm string, plan roleMappingPlan, mapper roleMapper) error {
+	if plan.empty() {
+		return nil
+	}
+
+	var missing []string
+	var realmRoles []gocloak.Role
+	for _, name := range plan.realm.grant {
+		role, err := gc.GetRealmRole(ctx, token, realm, name)
+		if keycloak.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get realm role %s: %w", name, err)
+		}
+		realmRoles = append(realmRoles, *role)
+	}
+
+	clientIDs := slices.Sorted(maps.Keys(plan.clients))
+	clientRoles := make(map[string]resolvedClientRoles, len(plan.clients))
+	for _, clientID := range clientIDs {
+		grants := plan.clients[clientID]
+		idOfClient := plan.clientIDs[clientID]
+		if idOfClient == "" {
+			clients, err := gc.GetClients(ctx, token, realm, gocloak.GetClientsParams{ClientID: &clientID})
+			if err != nil {
+				return fmt.Errorf("failed to get client %s: %w", clientID, err)
+			}
+			if len(clients) == 0 {
+				missing = append(missing, clientID)
+				continue
+			}
+			idOfClient = gocloak.PString(clients[0].ID)
+		}
+		resolved := resolvedClientRoles{idOfClient: idOfClient}
+		for _, name := range grants.grant {
+			role, err := gc.GetClientRole(ctx, token, realm, idOfClient, name)
+			if keycloak.IsNotFound(err) {
+				missing = append(missing, clientID+"/"+name)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get client role %s/%s: %w", clientID, name, err)
+			}
+			resolved.roles = append(resolved.roles, *role)
+		}
+		clientRoles[clientID] = resolved
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return &rolesNotFoundError{names: missing}
+	}
+
+	if len(plan.realm.revoke) > 0 {
+		if err := mapper.deleteRealmRoles(ctx, token, realm, plan.realm.revoke); err != nil {
+			return fmt.Errorf("failed to revoke realm roles: %w", err)
+		}
+	}
+	if len(realmRoles) > 0 {
+		if err := mapper.addRealmRoles(ctx, token, realm, realmRoles); err != nil {
+			return fmt.Errorf("failed to grant realm roles: %w", err)
+		}
+	}
+	for _, clientID := range clientIDs {
+		resolved := clientRoles[clientID]
+		if revoke := plan.clients[clientID].revoke; len(revoke) > 0 {
+			if err := mapper.deleteClientRoles(ctx, token, realm, resolved.idOfClient, revoke); err != nil {
+				return fmt.Errorf("failed to revoke client roles of %s: %w", clientID, err)
+			}
+		}
+		if len(resolved.roles) > 0 {
+			if err := mapper.addClientRoles(ctx, token, realm, resolved.idOfClient, resolved.roles); err != nil {
+				return fmt.Errorf("failed to grant client roles of %s: %w", clientID, err)
+			}
+		}
+	}
+	return nil
+}
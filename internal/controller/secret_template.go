@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+// templateKeysAnnotation records on the credentials Secret the keys, labels and annotations rendered from the
+// secret template, to remove those the template no longer renders.
+const templateKeysAnnotation = "keycloak.pewty.fr/secret-template-keys"
+
+// secretTemplateData is the context secret templates are rendered with.
+type secretTemplateData struct {
+	ClientID     string
+	ClientSecret string
+	// URL is the base URL of the Keycloak server
+	URL   string
+	Realm string
+	keycloak.Endpoints
+}
+
+// secretTemplateFuncs are the functions available to secret templates.
+var secretTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"quote": strconv.Quote,
+	"b64enc": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+}
+
+// secretContent is what the operator writes to the credentials Secret.
+// Keys of Data with a nil value are removed from the secret.
+type secretContent struct {
+	Data        map[string][]byte
+	Labels      map[string]string
+	Annotations map[string]string
+	// RemovedLabels and RemovedAnnotations are removed from the secret
+	RemovedLabels      []string
+	RemovedAnnotations []string
+}
+
+// templateKeys lists the keys, labels and annotations rendered from a secret template.
+type templateKeys struct {
+	Data        []string `json:"data,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// credentialsContent returns the content of the credentials Secret: the client ID and secret, and the
+// keys, labels and annotations rendered from the secret template. conn may be nil to validate templates.
+func credentialsContent(kcClient *keycloakv1.Client, conn *keycloak.Connection, clientID, clientSecret string) (secretContent, error) {
+	clientIDKey, clientSecretKey := credentialKeys(kcClient)
+	content := secretContent{Data: map[string][]byte{
+		clientIDKey:     []byte(clientID),
+		clientSecretKey: []byte(clientSecret),
+	}}
+
+	tmpl := kcClient.Spec.SecretTemplate
+	if tmpl == nil {
+		return content, nil
+	}
+
+	data := secretTemplateData{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Realm:        gocloak.PString(kcClient.Spec.Realm),
+	}
+	if conn != nil {
+		data.URL = conn.URL()
+		data.Endpoints = conn.Endpoints(data.Realm)
+	}
+
+	values, err := renderTemplates("data", tmpl.Data, data)
+	if err != nil {
+		return secretContent{}, err
+	}
+	for key, value := range values {
+		if key == clientIDKey || key == clientSecretKey || key == previousSecretKey(kcClient) {
+			return secretContent{}, fmt.Errorf("secretTemplate.data.%s is reserved for the client credentials", key)
+		}
+		content.Data[key] = []byte(value)
+	}
+	if content.Labels, err = renderTemplates("labels", tmpl.Labels, data); err != nil {
+		return secretContent{}, err
+	}
+	if content.Annotations, err = renderTemplates("annotations", tmpl.Annotations, data); err != nil {
+		return secretContent{}, err
+	}
+
+	rendered := templateKeys{
+		Data:        slices.Sorted(maps.Keys(values)),
+		Labels:      slices.Sorted(maps.Keys(content.Labels)),
+		Annotations: slices.Sorted(maps.Keys(content.Annotations)),
+	}
+	if len(rendered.Data)+len(rendered.Labels)+len(rendered.Annotations) > 0 {
+		recorded, err := json.Marshal(rendered)
+		if err != nil {
+			return secretContent{}, err
+		}
+		if content.Annotations == nil {
+			content.Annotations = map[string]string{}
+		}
+		content.Annotations[templateKeysAnnotation] = string(recorded)
+	}
+	return content, nil
+}
+
+// pruneTemplateKeys marks for removal what the secret template rendered into secret before, according to its
+// templateKeysAnnotation, and no longer renders.
+func pruneTemplateKeys(content *secretContent, secret *corev1.Secret) {
+	recorded, ok := secret.Annotations[templateKeysAnnotation]
+	if !ok {
+		return
+	}
+	var previous templateKeys
+	// A damaged record cannot tell what to remove, it is replaced below
+	_ = json.Unmarshal([]byte(recorded), &previous)
+	previous.Annotations = append(previous.Annotations, templateKeysAnnotation)
+
+	for _, key := range previous.Data {
+		if _, ok := content.Data[key]; !ok {
+			content.Data[key] = nil
+		}
+	}
+	for _, key := range previous.Labels {
+		if _, ok := content.Labels[key]; !ok {
+			content.RemovedLabels = append(content.RemovedLabels, key)
+		}
+	}
+	for _, key := range previous.Annotations {
+		if _, ok := content.Annotations[key]; !ok {
+			content.RemovedAnnotations = append(content.RemovedAnnotations, key)
+		}
+	}
+}
+
+// validateSecretTemplate checks that the secret template renders, before anything changes in Keycloak.
+func validateSecretTemplate(kcClient *keycloakv1.Client) error {
+	_, err := credentialsContent(kcClient, nil, "", "")
+	return err
+}
+
+// renderTemplates renders each template of templates, field names them in errors.
+func renderTemplates(field string, templates map[string]string, data secretTemplateData) (map[string]string, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	rendered := make(map[string]string, len(templates))
+	for key, text := range templates {
+		name := fmt.Sprintf("secretTemplate.%s.%s", field, key)
+		t, err := template.New(name).Funcs(secretTemplateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		var out strings.Builder
+		if err := t.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		rendered[key] = out.String()
+	}
+	return rendered, nil
+}
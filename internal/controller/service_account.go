@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+)
+
+// serviceAccountRolePlan lists the role mappings to change on the service account user of a client.
+type serviceAccountRolePlan struct {
+	userID string
+	roleMappingPlan
+}
+
+// drifted returns the JSON names of the spec fields the plan changes.
+func (p serviceAccountRolePlan) drifted() []string {
+	if !p.roleMappingPlan.empty() {
+		return []string{"serviceAccount"}
+	}
+	return nil
+}
+
+// validateServiceAccount refuses service account roles on a client without a service account.
+func validateServiceAccount(kcClient *keycloakv1.Client) error {
+	if kcClient.Spec.ServiceAccount != nil && !gocloak.PBool(kcClient.Spec.Client.ServiceAccountsEnabled) {
+		return fmt.Errorf("serviceAccount requires client.serviceAccountsEnabled")
+	}
+	return nil
+}
+
+// defaultRolesName returns the name of the composite role Keycloak grants to every user of realm.
+func defaultRolesName(realm string) string {
+	return "default-roles-" + strings.ToLower(realm)
+}
+
+// planServiceAccountRoles compares the declared roles of a service account with its live role mappings.
+// Only the roles granted directly are considered, not those inherited through composite roles or groups.
+func planServiceAccountRoles(spec *keycloakv1.ServiceAccount, live *gocloak.MappingsRepresentation, realm string) serviceAccountRolePlan {
+	if spec == nil {
+		return serviceAccountRolePlan{}
+	}
+	exact := spec.RoleMappingPolicy == keycloakv1.RoleMappingPolicyExact
+	return serviceAccountRolePlan{
+		roleMappingPlan: planRoleMappings(spec.RealmRoles, spec.ClientRoles, exact, live, defaultRolesName(realm)),
+	}
+}
+
+// fetchServiceAccountRoles returns the plan making the role mappings of the service account of the
+// Keycloak client idOfClient match the spec. The plan is empty when the spec declares no roles.
+func fetchServiceAccountRoles(ctx context.Context, gc *gocloak.GoCloak, token, realm, idOfClient string, spec *keycloakv1.ServiceAccount) (serviceAccountRolePlan, error) {
+	if spec == nil {
+		return planServiceAccountRoles(nil, nil, realm), nil
+	}
+	user, err := gc.GetClientServiceAccount(ctx, token, realm, idOfClient)
+	if err != nil {
+		return serviceAccountRolePlan{}, fmt.Errorf("failed to get service account: %w", err)
+	}
+	userID := gocloak.PString(user.ID)
+	mappings, err := gc.GetRoleMappingByUserID(ctx, token, realm, userID)
+	if err != nil {
+		return serviceAccountRolePlan{}, fmt.Errorf("failed to get service account role mappings: %w", err)
+	}
+	plan := planServiceAccountRoles(spec, mappings, realm)
+	plan.userID = userID
+	return plan, nil
+}
+
+// applyServiceAccountRoles applies plan to the service account user. Roles to grant are resolved in the
+// realm first, and nothing changes when one of them does not exist.
+func applyServiceAccountRoles(ctx context.Context, gc *gocloak.GoCloak, token, realm string, plan serviceAccountRolePlan) error {
+	return applyRoleMappings(ctx, gc, token, realm, plan.roleMappingPlan, userRoleMapper(gc, plan.userID))
+}
+
+// planServiceAccount fetches the role mappings of the service account of the Keycloak client idOfClient
+// and plans their changes, reporting failures in the Ready condition.
+func (r *ClientReconciler) planServiceAccount(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, idOfClient string) (serviceAccountRolePlan, error) {
+	plan, err := fetchServiceAccountRoles(ctx, gc, token, *kcClient.Spec.Realm, idOfClient, kcClient.Spec.ServiceAccount)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to get service account roles from Keycloak")
+		setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, "ServiceAccountRolesFailed", err.Error())
+	}
+	return plan, err
+}
+
+// syncServiceAccountRoles applies plan to the service account of kcClient and reports failures in the Ready
+// condition, with the ServiceAccountRoleNotFound reason when a role does not exist in the realm.
+func (r *ClientReconciler) syncServiceAccountRoles(ctx context.Context, gc *gocloak.GoCloak, token string, kcClient *keycloakv1.Client, plan serviceAccountRolePlan) error {
+	logger := logf.FromContext(ctx)
+
+	err := applyServiceAccountRoles(ctx, gc, token, *kcClient.Spec.Realm, plan)
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to update service account roles in Keycloak")
+	reason := "ServiceAccountRolesFailed"
+	var notFound *rolesNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "ServiceAccountRoleNotFound"
+		r.Recorder.Eventf(kcClient, nil, corev1.EventTypeWarning, reason, "GrantRoles", err.Error())
+	}
+	setReady(ctx, r.Client, kcClient, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
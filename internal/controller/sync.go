@@ -0,0 +1,159 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+)
+
+// keycloakResource is a resource synced to a Keycloak object, reporting its progress in status conditions.
+type keycloakResource interface {
+	client.Object
+	GetConditions() []metav1.Condition
+	SetConditions(conditions []metav1.Condition)
+}
+
+// ignoreStatusUpdates filters out the updates of a resource that change neither its spec nor its annotations,
+// such as the status writes of its own reconciler.
+var ignoreStatusUpdates = builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))
+
+// resyncInterval returns how long to wait before comparing a resource with Keycloak again: the interval
+// of its sync policy, else the operator-wide interval. Zero disables periodic resync.
+func resyncInterval(policy *keycloakv1.SyncPolicy, operatorInterval time.Duration) time.Duration {
+	if policy != nil && policy.ResyncInterval != nil {
+		return policy.ResyncInterval.Duration
+	}
+	return operatorInterval
+}
+
+// syncDriftPolicy returns how drift detected on a resource with the given sync policy is handled.
+func syncDriftPolicy(policy *keycloakv1.SyncPolicy) keycloakv1.DriftPolicy {
+	if policy != nil && policy.DriftPolicy != "" {
+		return policy.DriftPolicy
+	}
+	return keycloakv1.DriftPolicyCorrect
+}
+
+// isSynced reports whether the current generation of the resource was successfully applied to Keycloak.
+// Differences found afterwards were made in Keycloak directly.
+func isSynced(conditions []metav1.Condition, generation int64) bool {
+	ready := meta.FindStatusCondition(conditions, "Ready")
+	return ready != nil && ready.Status == metav1.ConditionTrue && ready.ObservedGeneration == generation
+}
+
+// recordDrift sets the Drifted condition of obj and emits an Event listing the drifted fields.
+// It reports whether the drift must be corrected.
+func recordDrift(ctx context.Context, recorder events.EventRecorder, obj client.Object, conditions *[]metav1.Condition,
+	policy keycloakv1.DriftPolicy, drifted []string) bool {
+	logger := logf.FromContext(ctx)
+
+	fields := strings.Join(drifted, ", ")
+	logger.Info("Resource drifted in Keycloak", "fields", fields, "driftPolicy", policy)
+
+	if policy == keycloakv1.DriftPolicyReport {
+		recorder.Eventf(obj, nil, corev1.EventTypeWarning, "DriftDetected", "Report",
+			"Fields changed in Keycloak: %s", fields)
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               "Drifted",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "DriftDetected",
+			Message:            fmt.Sprintf("Fields changed in Keycloak: %s", fields),
+		})
+		return false
+	}
+
+	recorder.Eventf(obj, nil, corev1.EventTypeWarning, "DriftCorrected", "Correct",
+		"Fields changed in Keycloak were restored: %s", fields)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               "Drifted",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             "DriftCorrected",
+		Message:            fmt.Sprintf("Fields changed in Keycloak were restored: %s", fields),
+	})
+	return true
+}
+
+// clearDrift records in the Drifted condition that Keycloak matches the desired state.
+func clearDrift(conditions *[]metav1.Condition, generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               "Drifted",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "InSync",
+		Message:            "Keycloak matches the desired state",
+	})
+}
+
+// setReady sets the Ready condition of obj and writes its status. A failed write is only logged, the
+// reconciliation reports its own outcome.
+func setReady(ctx context.Context, c client.Client, obj keycloakResource, status metav1.ConditionStatus, reason, message string) {
+	conditions := obj.GetConditions()
+	// The transition time is only bumped when the status changes, so that periodic resyncs do not hide it
+	meta.SetStatusCondition(&conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             status,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+	obj.SetConditions(conditions)
+
+	if err := writeStatus(ctx, c, obj); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update status")
+	}
+}
+
+// writeStatus writes the status of obj unless it matches the status last written, as found in the cache.
+// The last synced time alone is not a change, so that resyncs finding Keycloak up to date leave the
+// resource alone.
+func writeStatus(ctx context.Context, c client.Client, obj client.Object) error {
+	written := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), written); err == nil &&
+		equality.Semantic.DeepEqual(comparableStatus(written), comparableStatus(obj)) {
+		return nil
+	}
+	return c.Status().Update(ctx, obj)
+}
+
+// comparableStatus returns the status of obj without its last synced time.
+func comparableStatus(obj client.Object) map[string]any {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	status, _ := content["status"].(map[string]any)
+	delete(status, "lastSyncedTime")
+	return status
+}
@@ -0,0 +1,570 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+const userFinalizer = "keycloak.pewty.fr/finalizer"
+
+// UserReconciler reconciles a User object
+type UserReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Recorder    events.EventRecorder
+	Connections *ConnectionResolver
+	// DefaultDeletionPolicy applies to users whose spec and annotations do not set a deletion policy.
+	DefaultDeletionPolicy keycloakv1.DeletionPolicy
+	// ResyncInterval is how often users are compared with Keycloak when their syncPolicy does not say.
+	// Zero disables periodic resync.
+	ResyncInterval time.Duration
+}
+
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=users,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=users/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=keycloak.pewty.fr,resources=users/finalizers,verbs=update
+
+// Reconcile makes the Keycloak user match the User resource: it creates the user with its initial password,
+// updates its profile, group memberships and role mappings, and deletes it with the resource unless it
+// must be retained.
+func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var user keycloakv1.User
+	if err := r.Get(ctx, req.NamespacedName, &user); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("User resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get User resource")
+		return ctrl.Result{}, err
+	}
+
+	// Take the realm of the referenced Realm resource, only in memory
+	realmReady, err := resolveRealm(ctx, r, &user, user.Spec.RealmRef, &user.Spec.Realm, user.Status.Realm)
+	if err != nil {
+		logger.Error(err, "Failed to resolve realmRef")
+		setReady(ctx, r.Client, &user, metav1.ConditionFalse, "InvalidRealm", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if !user.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &user)
+	}
+
+	if !controllerutil.ContainsFinalizer(&user, userFinalizer) {
+		// Patch the finalizers only, the realm resolved above must not be written to the spec
+		patch := client.MergeFrom(user.DeepCopy())
+		controllerutil.AddFinalizer(&user, userFinalizer)
+		if err := r.Patch(ctx, &user, patch); err != nil {
+			logger.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Wait for the referenced Realm, its changes trigger a new reconciliation
+	if !realmReady {
+		message := fmt.Sprintf("Waiting for Realm %s to be Ready", user.Spec.RealmRef.Name)
+		logger.Info("Realm is not ready, waiting", "realmRef", user.Spec.RealmRef.Name)
+		setReady(ctx, r.Client, &user, metav1.ConditionFalse, "RealmNotReady", message)
+		return ctrl.Result{}, nil
+	}
+
+	conn, token, err := connect(ctx, r.Client, r.Connections, &user, user.Spec.ConnectionRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gc := conn.Client
+	realm := *user.Spec.Realm
+	username := userName(&user)
+
+	existing, err := r.findUser(ctx, gc, token, &user)
+	if err != nil {
+		logger.Error(err, "Failed to query Keycloak users")
+		setReady(ctx, r.Client, &user, metav1.ConditionFalse, "QueryFailed", fmt.Sprintf("Failed to query user: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	if existing == nil {
+		// Read the password first, a user created without it could not be given one later
+		password, err := r.getInitialPassword(ctx, &user)
+		if err != nil {
+			logger.Error(err, "Failed to get initial password from secret")
+			setReady(ctx, r.Client, &user, metav1.ConditionFalse, "SecretReadFailed", fmt.Sprintf("Failed to read secret: %v", err))
+			return ctrl.Result{}, err
+		}
+
+		logger.Info("Creating user in Keycloak", "realm", realm, "username", username)
+		desired := desiredUser(&user, true)
+		// Keycloak creates users disabled unless told otherwise
+		if desired.Enabled == nil {
+			desired.Enabled = gocloak.BoolP(true)
+		}
+		if len(user.Spec.RequiredActions) > 0 {
+			desired.RequiredActions = &user.Spec.RequiredActions
+		}
+		// The password is part of the creation, so that no user is ever left without it
+		if password != "" {
+			desired.Credentials = &[]gocloak.CredentialRepresentation{{
+				Type:      gocloak.StringP("password"),
+				Value:     gocloak.StringP(password),
+				Temporary: gocloak.BoolP(user.Spec.InitialPassword.Temporary),
+			}}
+		}
+		id, err := gc.CreateUser(ctx, token, realm, desired)
+		if err != nil {
+			logger.Error(err, "Failed to create user in Keycloak")
+			setReady(ctx, r.Client, &user, metav1.ConditionFalse, "CreationFailed", fmt.Sprintf("Failed to create: %v", err))
+			return ctrl.Result{}, err
+		}
+		// The ID is recorded right away, so that a failure below does not look the user up by username again
+		user.Status.ID = id
+		user.Status.Realm = realm
+
+		groupPlan := planGroupMemberships(user.Spec.Groups, nil, false)
+		if err := r.syncGroups(ctx, gc, token, &user, id, groupPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		rolePlan := planUserRoleMappings(&user, nil, realm)
+		if err := r.syncRoleMappings(ctx, gc, token, &user, id, rolePlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully created user in Keycloak", "username", username, "id", id)
+
+		markUserSynced(&user, realm, id, username)
+		setReady(ctx, r.Client, &user, metav1.ConditionTrue, "Created", "User successfully created in Keycloak")
+		return ctrl.Result{RequeueAfter: resyncInterval(user.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+	id := gocloak.PString(existing.ID)
+
+	adopting, ok := claimObject(ctx, r.Client, r.Recorder, &user, user.Spec.AdoptionPolicy, firstValues(userAttributes(existing)),
+		"Keycloak user "+username)
+	if !ok {
+		return ctrl.Result{RequeueAfter: resyncInterval(user.Spec.SyncPolicy, r.ResyncInterval)}, nil
+	}
+
+	groupPlan, rolePlan, err := r.planMemberships(ctx, gc, token, &user, id)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	desired := desiredUser(&user, user.Spec.ProfilePolicy != keycloakv1.ProfilePolicyCreateOnly)
+	fieldsDrifted := diffFields(desired, *existing, "id")
+	drifted := fieldsDrifted
+	if !groupPlan.empty() {
+		drifted = append(drifted, "groups")
+	}
+	drifted = append(drifted, rolePlan.drifted()...)
+
+	// Differences on a resource already applied at this generation were made in Keycloak directly.
+	// A new username in the spec renames the user.
+	renaming := gocloak.PString(existing.Username) != username
+	if len(drifted) > 0 && !adopting && !renaming && isSynced(user.Status.Conditions, user.Generation) {
+		if !recordDrift(ctx, r.Recorder, &user, &user.Status.Conditions, syncDriftPolicy(user.Spec.SyncPolicy), drifted) {
+			if err := writeStatus(ctx, r.Client, &user); err != nil {
+				logger.Error(err, "Failed to update User status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resyncInterval(user.Spec.SyncPolicy, r.ResyncInterval)}, nil
+		}
+	} else {
+		clearDrift(&user.Status.Conditions, user.Generation)
+	}
+
+	if len(drifted) > 0 {
+		logger.Info("Updating user in Keycloak", "username", username, "fields", drifted)
+		if len(fieldsDrifted) > 0 {
+			if err := gc.UpdateUser(ctx, token, realm, updatedUser(existing, desired)); err != nil {
+				logger.Error(err, "Failed to update user in Keycloak")
+				setReady(ctx, r.Client, &user, metav1.ConditionFalse, "UpdateFailed", fmt.Sprintf("Failed to update: %v", err))
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.syncGroups(ctx, gc, token, &user, id, groupPlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.syncRoleMappings(ctx, gc, token, &user, id, rolePlan); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Successfully updated user in Keycloak", "username", username)
+	}
+
+	markUserSynced(&user, realm, id, username)
+	if len(drifted) > 0 {
+		setReady(ctx, r.Client, &user, metav1.ConditionTrue, "Updated", "User successfully updated in Keycloak")
+	} else {
+		setReady(ctx, r.Client, &user, metav1.ConditionTrue, "UpToDate", "User is up to date in Keycloak")
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval(user.Spec.SyncPolicy, r.ResyncInterval)}, nil
+}
+
+// reconcileDelete removes the user from Keycloak and releases the finalizer
+func (r *UserReconciler) reconcileDelete(ctx context.Context, user *keycloakv1.User) (ctrl.Result, error) {
+	// Without a realm, the referenced Realm is gone before the user was ever synced
+	var cleanup func() error
+	if user.Spec.Realm != nil {
+		cleanup = func() error { return r.cleanupKeycloak(ctx, user) }
+	}
+	return finalize(ctx, r.Client, r.Recorder, user, userFinalizer, user.Spec.DeletionPolicy,
+		r.DefaultDeletionPolicy, "Keycloak user "+userName(user), cleanup)
+}
+
+// cleanupKeycloak deletes the user from Keycloak before the resource goes away, unless another
+// resource or someone else manages it
+func (r *UserReconciler) cleanupKeycloak(ctx context.Context, user *keycloakv1.User) error {
+	conn, token, err := connect(ctx, r.Client, r.Connections, user, user.Spec.ConnectionRef)
+	if err != nil {
+		return err
+	}
+	gc := conn.Client
+	return deleteOwned(ctx, r.Client, user, "Keycloak user "+userName(user),
+		func() (*gocloak.User, error) { return r.findUser(ctx, gc, token, user) },
+		func(existing *gocloak.User) map[string]string { return firstValues(userAttributes(existing)) },
+		func(existing *gocloak.User) error {
+			return gc.DeleteUser(ctx, token, *user.Spec.Realm, gocloak.PString(existing.ID))
+		})
+}
+
+// findUser looks up the Keycloak user of the User resource, by the ID recorded in the status when it was
+// synced to the same realm, else by username. It returns nil when the user or its realm does not exist.
+func (r *UserReconciler) findUser(ctx context.Context, gc *gocloak.GoCloak, token string, user *keycloakv1.User) (*gocloak.User, error) {
+	realm := *user.Spec.Realm
+	if user.Status.ID != "" && user.Status.Realm == realm {
+		existing, err := gc.GetUserByID(ctx, token, realm, user.Status.ID)
+		if err == nil {
+			return existing, nil
+		}
+		if !keycloak.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	username := userName(user)
+	users, err := gc.GetUsers(ctx, token, realm, gocloak.GetUsersParams{
+		Username: &username,
+		Exact:    gocloak.BoolP(true),
+	})
+	if keycloak.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range users {
+		if gocloak.PString(existing.Username) == username {
+			return existing, nil
+		}
+	}
+	return nil, nil
+}
+
+// getInitialPassword reads the initial password of the user from the referenced Secret, empty when the
+// spec references none.
+func (r *UserReconciler) getInitialPassword(ctx context.Context, user *keycloakv1.User) (string, error) {
+	if user.Spec.InitialPassword == nil {
+		return "", nil
+	}
+	ref := user.Spec.InitialPassword.SecretRef
+	key := ref.Key
+	if key == "" {
+		key = "password"
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: user.Namespace}, secret); err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", ref.Name, err)
+	}
+	password, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", key, ref.Name)
+	}
+	return string(password), nil
+}
+
+// planMemberships fetches the groups and role mappings of the Keycloak user userID and plans their changes,
+// reporting failures in the Ready condition.
+func (r *UserReconciler) planMemberships(ctx context.Context, gc *gocloak.GoCloak, token string, user *keycloakv1.User, userID string) (groupMembershipPlan, roleMappingPlan, error) {
+	logger := logf.FromContext(ctx)
+	realm := *user.Spec.Realm
+
+	groups, err := gc.GetUserGroups(ctx, token, realm, userID, gocloak.GetGroupsParams{})
+	if err != nil {
+		logger.Error(err, "Failed to get user groups from Keycloak")
+		setReady(ctx, r.Client, user, metav1.ConditionFalse, "GroupsFailed", fmt.Sprintf("Failed to get user groups: %v", err))
+		return groupMembershipPlan{}, roleMappingPlan{}, err
+	}
+	mappings, err := gc.GetRoleMappingByUserID(ctx, token, realm, userID)
+	if err != nil {
+		logger.Error(err, "Failed to get user role mappings from Keycloak")
+		setReady(ctx, r.Client, user, metav1.ConditionFalse, "RoleMappingsFailed",
+			fmt.Sprintf("Failed to get user role mappings: %v", err))
+		return groupMembershipPlan{}, roleMappingPlan{}, err
+	}
+
+	exact := user.Spec.GroupMembershipPolicy == keycloakv1.MembershipPolicyExact
+	return planGroupMemberships(user.Spec.Groups, groups, exact), planUserRoleMappings(user, mappings, realm), nil
+}
+
+// syncGroups applies plan to the group memberships of the Keycloak user userID and reports failures in the
+// Ready condition, with the GroupNotFound reason when a group does not exist in the realm.
+func (r *UserReconciler) syncGroups(ctx context.Context, gc *gocloak.GoCloak, token string, user *keycloakv1.User, userID string, plan groupMembershipPlan) error {
+	logger := logf.FromContext(ctx)
+
+	err := applyGroupMemberships(ctx, gc, token, *user.Spec.Realm, userID, plan)
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to update user groups in Keycloak")
+	reason := "GroupsFailed"
+	var notFound *groupsNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "GroupNotFound"
+		r.Recorder.Eventf(user, nil, corev1.EventTypeWarning, reason, "JoinGroups", err.Error())
+	}
+	setReady(ctx, r.Client, user, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
+
+// syncRoleMappings applies plan to the role mappings of the Keycloak user userID and reports failures in
+// the Ready condition, with the RoleNotFound reason when a role does not exist in the realm.
+func (r *UserReconciler) syncRoleMappings(ctx context.Context, gc *gocloak.GoCloak, token string, user *keycloakv1.User, userID string, plan roleMappingPlan) error {
+	logger := logf.FromContext(ctx)
+
+	err := applyRoleMappings(ctx, gc, token, *user.Spec.Realm, plan, userRoleMapper(gc, userID))
+	if err == nil {
+		return nil
+	}
+
+	logger.Error(err, "Failed to update user role mappings in Keycloak")
+	reason := "RoleMappingsFailed"
+	var notFound *rolesNotFoundError
+	if errors.As(err, &notFound) {
+		reason = "RoleNotFound"
+		r.Recorder.Eventf(user, nil, corev1.EventTypeWarning, reason, "GrantRoles", err.Error())
+	}
+	setReady(ctx, r.Client, user, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
+
+// groupMembershipPlan lists the groups, by path, a user joins and the groups it leaves.
+type groupMembershipPlan struct {
+	join  []string
+	leave []*gocloak.Group
+}
+
+// empty reports whether the group memberships already match.
+func (p groupMembershipPlan) empty() bool {
+	return len(p.join) == 0 && len(p.leave) == 0
+}
+
+// groupsNotFoundError reports groups a user should be a member of that do not exist in the realm.
+type groupsNotFoundError struct {
+	paths []string
+}
+
+func (e *groupsNotFoundError) Error() string {
+	return fmt.Sprintf("groups not found in realm: %s", strings.Join(e.paths, ", "))
+}
+
+// groupPath returns path with a leading slash, as Keycloak reports group paths.
+func groupPath(path string) string {
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+// planGroupMemberships compares the declared groups of a user, by path, with the groups it is a member of.
+// When exact, the groups not declared are left.
+func planGroupMemberships(desired []string, live []*gocloak.Group, exact bool) groupMembershipPlan {
+	var plan groupMembershipPlan
+	livePaths := make([]string, 0, len(live))
+	for _, group := range live {
+		livePaths = append(livePaths, gocloak.PString(group.Path))
+	}
+	desiredPaths := make([]string, 0, len(desired))
+	for _, path := range desired {
+		desiredPaths = append(desiredPaths, groupPath(path))
+	}
+	for _, path := range desiredPaths {
+		if !slices.Contains(livePaths, path) && !slices.Contains(plan.join, path) {
+			plan.join = append(plan.join, path)
+		}
+	}
+	if exact {
+		for _, group := range live {
+			if !slices.Contains(desiredPaths, gocloak.PString(group.Path)) {
+				plan.leave = append(plan.leave, group)
+			}
+		}
+	}
+	return plan
+}
+
+// applyGroupMemberships applies plan to the Keycloak user userID. Groups to join are resolved in the realm
+// first, and nothing changes when one of them does not exist.
+func applyGroupMemberships(ctx context.Context, gc *gocloak.GoCloak, token, realm, userID string, plan groupMembershipPlan) error {
+	var missing []string
+	joined := make([]string, 0, len(plan.join))
+	for _, path := range plan.join {
+		group, err := gc.GetGroupByPath(ctx, token, realm, strings.TrimPrefix(path, "/"))
+		if keycloak.IsNotFound(err) {
+			missing = append(missing, path)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get group %s: %w", path, err)
+		}
+		joined = append(joined, gocloak.PString(group.ID))
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return &groupsNotFoundError{paths: missing}
+	}
+
+	for _, group := range plan.leave {
+		if err := gc.DeleteUserFromGroup(ctx, token, realm, userID, gocloak.PString(group.ID)); err != nil {
+			return fmt.Errorf("failed to leave group %s: %w", gocloak.PString(group.Path), err)
+		}
+	}
+	for i, groupID := range joined {
+		if err := gc.AddUserToGroup(ctx, token, realm, userID, groupID); err != nil {
+			return fmt.Errorf("failed to join group %s: %w", plan.join[i], err)
+		}
+	}
+	return nil
+}
+
+// planUserRoleMappings compares the roles declared on a user with its live role mappings. The default roles
+// of the realm are never revoked.
+func planUserRoleMappings(user *keycloakv1.User, live *gocloak.MappingsRepresentation, realm string) roleMappingPlan {
+	exact := user.Spec.RoleMappingPolicy == keycloakv1.RoleMappingPolicyExact
+	return planRoleMappings(user.Spec.RealmRoles, user.Spec.ClientRoles, exact, live, defaultRolesName(realm))
+}
+
+// userName returns the username of the Keycloak user of a User resource, in lowercase as Keycloak stores it.
+func userName(user *keycloakv1.User) string {
+	if user.Spec.Username != "" {
+		return strings.ToLower(user.Spec.Username)
+	}
+	return user.Name
+}
+
+// userAttributes returns the attributes of a Keycloak user, or nil when it has none.
+func userAttributes(user *gocloak.User) map[string][]string {
+	if user.Attributes == nil {
+		return nil
+	}
+	return *user.Attributes
+}
+
+// desiredUser returns the Keycloak user described by the spec, marked as managed by user. The profile fields
+// are only included when enforceProfile is set, enabled and emailVerified whenever the spec sets them.
+// Required actions, the initial password, groups and roles are applied separately.
+func desiredUser(user *keycloakv1.User, enforceProfile bool) gocloak.User {
+	spec := &user.Spec
+	desired := gocloak.User{
+		Username:      gocloak.StringP(userName(user)),
+		Enabled:       spec.Enabled,
+		EmailVerified: spec.EmailVerified,
+	}
+
+	var attributes map[string][]string
+	if enforceProfile {
+		if spec.Email != nil {
+			desired.Email = gocloak.StringP(strings.ToLower(*spec.Email))
+		}
+		desired.FirstName = spec.FirstName
+		desired.LastName = spec.LastName
+		attributes = spec.Attributes
+	}
+	owned := withOwnerValues(attributes, user)
+	desired.Attributes = &owned
+	return desired
+}
+
+// updatedUser returns the live user with the fields set in desired. Keycloak replaces the whole user on
+// update, so the fields and attributes the spec does not manage are sent back as they are.
+func updatedUser(live *gocloak.User, desired gocloak.User) gocloak.User {
+	updated := *live
+	updated.Username = desired.Username
+	if desired.Enabled != nil {
+		updated.Enabled = desired.Enabled
+	}
+	if desired.EmailVerified != nil {
+		updated.EmailVerified = desired.EmailVerified
+	}
+	if desired.Email != nil {
+		updated.Email = desired.Email
+	}
+	if desired.FirstName != nil {
+		updated.FirstName = desired.FirstName
+	}
+	if desired.LastName != nil {
+		updated.LastName = desired.LastName
+	}
+	attributes := mergeValues(userAttributes(live), *desired.Attributes)
+	updated.Attributes = &attributes
+	return updated
+}
+
+// markUserSynced records in the status the Keycloak user the resource is now in sync with.
+func markUserSynced(user *keycloakv1.User, realm, id, username string) {
+	now := metav1.Now()
+	user.Status.ID = id
+	user.Status.Realm = realm
+	user.Status.Username = username
+	user.Status.ObservedGeneration = user.Generation
+	user.Status.LastSyncedTime = &now
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := indexRealmRef(mgr, &keycloakv1.User{}, func(obj client.Object) *keycloakv1.RealmReference {
+		return obj.(*keycloakv1.User).Spec.RealmRef
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&keycloakv1.User{}, ignoreStatusUpdates).
+		Watches(&keycloakv1.Realm{}, handler.EnqueueRequestsFromMapFunc(requestsForRealm(r.Client, &keycloakv1.UserList{})),
+			realmReadinessChanged).
+		Named("user").
+		Complete(r)
+}
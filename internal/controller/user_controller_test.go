@@ -0,0 +1,431 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	keycloakv1 "github.com/pewty-fr/keycloak-client-operator/api/v1"
+	"github.com/pewty-fr/keycloak-client-operator/internal/keycloak"
+)
+
+var _ = Describe("User Controller", func() {
+	Context("When converting a User to a Keycloak user", func() {
+		newUser := func() *keycloakv1.User {
+			return &keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default", UID: "user-uid"},
+				Spec: keycloakv1.UserSpec{
+					Username:   "Alice",
+					Email:      strPtr("Alice@Example.com"),
+					FirstName:  strPtr("Alice"),
+					Attributes: map[string][]string{"team": {"platform"}},
+				},
+			}
+		}
+
+		It("Should map the spec in lowercase and mark ownership", func() {
+			desired := desiredUser(newUser(), true)
+			Expect(*desired.Username).To(Equal("alice"))
+			Expect(*desired.Email).To(Equal("alice@example.com"))
+			Expect(*desired.FirstName).To(Equal("Alice"))
+			Expect(desired.Enabled).To(BeNil())
+			Expect(*desired.Attributes).To(HaveKeyWithValue("team", []string{"platform"}))
+			Expect(firstValues(*desired.Attributes)).To(HaveKeyWithValue(ownerUIDAttribute, "user-uid"))
+		})
+
+		It("Should leave the profile fields out unless enforced", func() {
+			desired := desiredUser(newUser(), false)
+			Expect(*desired.Username).To(Equal("alice"))
+			Expect(desired.Email).To(BeNil())
+			Expect(desired.FirstName).To(BeNil())
+			Expect(*desired.Attributes).NotTo(HaveKey("team"))
+			Expect(*desired.Attributes).To(HaveKey(ownerUIDAttribute))
+		})
+
+		It("Should send back the fields and attributes the spec does not manage", func() {
+			live := &gocloak.User{
+				ID:              strPtr("alice-id"),
+				Username:        strPtr("alice"),
+				LastName:        strPtr("Liddell"),
+				Attributes:      &map[string][]string{"locale": {"fr"}, "team": {"legacy"}},
+				RequiredActions: &[]string{"UPDATE_PASSWORD"},
+			}
+			updated := updatedUser(live, desiredUser(newUser(), true))
+			Expect(*updated.ID).To(Equal("alice-id"))
+			Expect(*updated.FirstName).To(Equal("Alice"))
+			Expect(*updated.LastName).To(Equal("Liddell"))
+			Expect(*updated.RequiredActions).To(Equal([]string{"UPDATE_PASSWORD"}))
+			Expect(*updated.Attributes).To(HaveKeyWithValue("locale", []string{"fr"}))
+			Expect(*updated.Attributes).To(HaveKeyWithValue("team", []string{"platform"}))
+			Expect(*live.Attributes).To(HaveKeyWithValue("team", []string{"legacy"}))
+		})
+
+		It("Should plan the group memberships by path", func() {
+			live := []*gocloak.Group{
+				{ID: strPtr("eng-id"), Path: strPtr("/engineering")},
+				{ID: strPtr("ops-id"), Path: strPtr("/ops")},
+			}
+			plan := planGroupMemberships([]string{"engineering", "/engineering/backend"}, live, false)
+			Expect(plan.join).To(Equal([]string{"/engineering/backend"}))
+			Expect(plan.leave).To(BeEmpty())
+
+			plan = planGroupMemberships([]string{"/engineering"}, live, true)
+			Expect(plan.join).To(BeEmpty())
+			Expect(plan.leave).To(Equal([]*gocloak.Group{live[1]}))
+			Expect(planGroupMemberships([]string{"/engineering", "/ops"}, live, true).empty()).To(BeTrue())
+		})
+	})
+
+	Context("When talking to Keycloak", func() {
+		var (
+			server *httptest.Server
+			conn   *keycloak.Connection
+			calls  []string
+		)
+
+		BeforeEach(func() {
+			calls = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /admin/realms/demo/users/old-id":
+					_, _ = fmt.Fprint(w, `{"id":"old-id","username":"old-name"}`)
+				case "GET /admin/realms/demo/users":
+					if r.URL.Query().Get("username") == "alice" && r.URL.Query().Get("exact") == "true" {
+						_, _ = fmt.Fprint(w, `[{"id":"alice-id","username":"alice"}]`)
+					} else {
+						_, _ = fmt.Fprint(w, `[]`)
+					}
+				case "GET /admin/realms/demo/group-by-path/engineering/backend":
+					_, _ = fmt.Fprint(w, `{"id":"backend-id","path":"/engineering/backend"}`)
+				default:
+					if r.Method != http.MethodGet {
+						calls = append(calls, r.Method+" "+r.URL.Path)
+						w.WriteHeader(http.StatusNoContent)
+						return
+					}
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = fmt.Fprint(w, `{"error":"Not found"}`)
+				}
+			}))
+			var err error
+			conn, err = keycloak.NewConnection(keycloak.Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newUser := func(name, id, realm string) *keycloakv1.User {
+			return &keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       keycloakv1.UserSpec{Realm: strPtr("demo")},
+				Status:     keycloakv1.UserStatus{ID: id, Realm: realm},
+			}
+		}
+
+		It("Should prefer the ID recorded in the status, to rename the user", func() {
+			found, err := (&UserReconciler{}).findUser(context.Background(), conn.Client, "token", newUser("alice", "old-id", "demo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("old-id"))
+		})
+
+		It("Should look up the user by username in another realm or when it is gone", func() {
+			found, err := (&UserReconciler{}).findUser(context.Background(), conn.Client, "token", newUser("alice", "old-id", "other"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gocloak.PString(found.ID)).To(Equal("alice-id"))
+
+			found, err = (&UserReconciler{}).findUser(context.Background(), conn.Client, "token", newUser("bob", "deleted-id", "demo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+
+		It("Should join and leave groups, and refuse groups missing in the realm", func() {
+			plan := groupMembershipPlan{
+				join:  []string{"/engineering/backend"},
+				leave: []*gocloak.Group{{ID: strPtr("ops-id"), Path: strPtr("/ops")}},
+			}
+			Expect(applyGroupMemberships(context.Background(), conn.Client, "token", "demo", "alice-id", plan)).To(Succeed())
+			Expect(calls).To(Equal([]string{
+				"DELETE /admin/realms/demo/users/alice-id/groups/ops-id",
+				"PUT /admin/realms/demo/users/alice-id/groups/backend-id",
+			}))
+
+			calls = nil
+			plan.join = append(plan.join, "/sales")
+			err := applyGroupMemberships(context.Background(), conn.Client, "token", "demo", "alice-id", plan)
+			Expect(err).To(MatchError("groups not found in realm: /sales"))
+			Expect(calls).To(BeEmpty())
+		})
+	})
+
+	Context("When reconciling a User resource", func() {
+		ctx := context.Background()
+
+		It("Should reject a User without realm", func() {
+			resource := &keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-user-without-realm", Namespace: "default"},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("realm or realmRef is required")))
+		})
+
+		It("Should read the initial password from the referenced Secret", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-user-password", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("s3cret"), "other": []byte("0ther")},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) })
+
+			reconciler := &UserReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			user := &keycloakv1.User{ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"}}
+			password, err := reconciler.getInitialPassword(ctx, user)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(password).To(BeEmpty())
+
+			user.Spec.InitialPassword = &keycloakv1.InitialPassword{
+				SecretRef: keycloakv1.PasswordSecretReference{Name: secret.Name},
+			}
+			password, err = reconciler.getInitialPassword(ctx, user)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(password).To(Equal("s3cret"))
+
+			user.Spec.InitialPassword.SecretRef.Key = "missing"
+			_, err = reconciler.getInitialPassword(ctx, user)
+			Expect(err).To(MatchError("key missing not found in secret test-user-password"))
+		})
+	})
+
+	Context("When syncing a User with Keycloak", func() {
+		const usersPath = "/admin/realms/test-realm/users"
+		var (
+			fk         *fakeKeycloak
+			c          *fakeCluster
+			reconciler *UserReconciler
+			req        ctrl.Request
+		)
+
+		// reconcile runs the reconciler until the user is synced, and returns the User resource
+		reconcile := func(times int) *keycloakv1.User {
+			for range times {
+				_, err := reconciler.Reconcile(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			user := &keycloakv1.User{}
+			Expect(c.Get(context.Background(), req.NamespacedName, user)).To(Succeed())
+			return user
+		}
+
+		setup := func(user *keycloakv1.User, objs ...client.Object) {
+			fk = newFakeKeycloak()
+			// Keycloak lists the role mappings of every user
+			fk.onCreate = func(path string, obj map[string]any) {
+				if path == usersPath {
+					fk.store(path+"/"+obj["id"].(string)+"/role-mappings", map[string]any{})
+				}
+			}
+			user.Namespace = "default"
+			user.UID = "alice-uid"
+			user.Generation = 1
+			user.Spec.Realm = strPtr("test-realm")
+			c = newFakeCluster(append(objs, user)...)
+			reconciler = &UserReconciler{
+				Client:      c,
+				Scheme:      scheme.Scheme,
+				Recorder:    newFakeRecorder(),
+				Connections: &ConnectionResolver{Client: c, Default: fk.conn},
+			}
+			req = ctrl.Request{NamespacedName: types.NamespacedName{Name: user.Name, Namespace: "default"}}
+		}
+
+		It("Should create the user enabled with its initial password in a single request", func() {
+			setup(&keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+				Spec: keycloakv1.UserSpec{InitialPassword: &keycloakv1.InitialPassword{
+					SecretRef: keycloakv1.PasswordSecretReference{Name: "alice-password"},
+					Temporary: true,
+				}},
+			}, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice-password", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("s3cret")},
+			})
+
+			user := reconcile(2)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(fk.recorded()).To(Equal([]string{"POST " + usersPath}))
+			var created gocloak.User
+			Expect(json.Unmarshal([]byte(fk.body("POST "+usersPath)), &created)).To(Succeed())
+			Expect(*created.Enabled).To(BeTrue())
+			Expect(*created.Credentials).To(Equal([]gocloak.CredentialRepresentation{{
+				Type: strPtr("password"), Value: strPtr("s3cret"), Temporary: gocloak.BoolP(true),
+			}}))
+		})
+
+		It("Should only keep enabled as declared when the spec sets it", func() {
+			setup(&keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+				Spec:       keycloakv1.UserSpec{ProfilePolicy: keycloakv1.ProfilePolicyCreateOnly},
+			})
+			reconcile(2)
+
+			// An administrator disabling the user is left alone
+			fk.get(usersPath + "/alice-id")["enabled"] = false
+			fk.reset()
+			user := reconcile(1)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("UpToDate"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			user.Spec.Enabled = gocloak.BoolP(true)
+			user.Generation = 2
+			Expect(c.Update(context.Background(), user)).To(Succeed())
+			user = reconcile(1)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT " + usersPath + "/alice-id"}))
+			Expect(fk.get(usersPath + "/alice-id")).To(HaveKeyWithValue("enabled", true))
+		})
+
+		It("Should update the user with the spec and delete it with the resource", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+				Spec:       keycloakv1.UserSpec{FirstName: strPtr("Alice")},
+			})
+
+			user := reconcile(2)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("Created"))
+			Expect(user.Status.ID).To(Equal("alice-id"))
+			Expect(fk.get(usersPath + "/alice-id")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, ConsistOf("alice-uid")))
+
+			fk.reset()
+			user.Spec.LastName = strPtr("Liddell")
+			user.Generation = 2
+			Expect(c.Update(ctx, user)).To(Succeed())
+			user = reconcile(1)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(fk.recorded()).To(Equal([]string{"PUT " + usersPath + "/alice-id"}))
+			Expect(fk.get(usersPath + "/alice-id")).To(And(
+				HaveKeyWithValue("firstName", "Alice"), HaveKeyWithValue("lastName", "Liddell")))
+
+			fk.reset()
+			Expect(c.Delete(ctx, user)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(Equal([]string{"DELETE " + usersPath + "/alice-id"}))
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, user))).To(BeTrue())
+
+			// The resource is gone, there is nothing left to reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should keep the user in Keycloak when the resource retains it", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+				Spec:       keycloakv1.UserSpec{DeletionPolicy: keycloakv1.DeletionPolicyRetain},
+			})
+			user := reconcile(2)
+
+			fk.reset()
+			Expect(c.Delete(ctx, user)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(fk.get(usersPath + "/alice-id")).NotTo(BeNil())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, user))).To(BeTrue())
+		})
+
+		It("Should release a user whose realm was deleted from Keycloak first", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.User{ObjectMeta: metav1.ObjectMeta{Name: "alice"}})
+			fk.put("/admin/realms/test-realm", `{"id":"test-realm-id","realm":"test-realm"}`)
+			user := reconcile(2)
+
+			// The Realm resource deleted with the namespace takes its users with it
+			Expect(fk.conn.Client.DeleteRealm(ctx, "token", "test-realm")).To(Succeed())
+			fk.reset()
+			Expect(c.Delete(ctx, user)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fk.recorded()).To(BeEmpty())
+			Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, user))).To(BeTrue())
+		})
+
+		DescribeTable("Should handle the profile changed in Keycloak according to the profile and drift policies",
+			func(profile keycloakv1.ProfilePolicy, drift keycloakv1.DriftPolicy, reason string, firstName string) {
+				setup(&keycloakv1.User{
+					ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+					Spec: keycloakv1.UserSpec{
+						FirstName:     strPtr("Alice"),
+						ProfilePolicy: profile,
+						SyncPolicy:    &keycloakv1.SyncPolicy{DriftPolicy: drift},
+					},
+				})
+				reconcile(2)
+
+				// The user changed their first name
+				fk.get(usersPath + "/alice-id")["firstName"] = "Ally"
+				user := reconcile(1)
+				Expect(meta.FindStatusCondition(user.Status.Conditions, "Drifted").Reason).To(Equal(reason))
+				Expect(fk.get(usersPath + "/alice-id")).To(HaveKeyWithValue("firstName", firstName))
+			},
+			Entry("Enforce by default", keycloakv1.ProfilePolicy(""), keycloakv1.DriftPolicy(""), "DriftCorrected", "Alice"),
+			Entry("Enforce with the Report drift policy", keycloakv1.ProfilePolicyEnforce, keycloakv1.DriftPolicyReport,
+				"DriftDetected", "Ally"),
+			Entry("CreateOnly", keycloakv1.ProfilePolicyCreateOnly, keycloakv1.DriftPolicy(""), "InSync", "Ally"),
+		)
+
+		It("Should refuse a user managed by another resource until asked to take it over", func() {
+			ctx := context.Background()
+			setup(&keycloakv1.User{ObjectMeta: metav1.ObjectMeta{Name: "alice"}})
+			fk.put(usersPath+"/alice-id", `{"id":"alice-id","username":"alice","enabled":true,"attributes":{`+
+				`"keycloak.pewty.fr/owner-uid":["other-uid"],"keycloak.pewty.fr/owner":["default/other"]}}`)
+			fk.put(usersPath+"/alice-id/role-mappings", `{}`)
+
+			user := reconcile(2)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("Conflict"))
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Conflict").Message).To(Equal(
+				"Keycloak user alice is already managed by default/other, set adoptionPolicy to Always to take it over"))
+			Expect(fk.recorded()).To(BeEmpty())
+
+			user.Spec.AdoptionPolicy = keycloakv1.AdoptionPolicyAlways
+			user.Generation = 2
+			Expect(c.Update(ctx, user)).To(Succeed())
+			user = reconcile(1)
+			Expect(meta.FindStatusCondition(user.Status.Conditions, "Ready").Reason).To(Equal("Updated"))
+			Expect(meta.IsStatusConditionFalse(user.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(fk.get(usersPath + "/alice-id")["attributes"]).To(HaveKeyWithValue(ownerUIDAttribute, ConsistOf("alice-uid")))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	"github.com/go-resty/resty/v2"
+)
+
+// adminURL returns the URL of the admin API endpoint of realm made of segments, each path escaped.
+func (c *Connection) adminURL(realm string, segments ...string) string {
+	endpoint := strings.TrimSuffix(c.config.URL, "/") + "/admin/realms/" + url.PathEscape(realm)
+	for _, segment := range segments {
+		endpoint += "/" + url.PathEscape(segment)
+	}
+	return endpoint
+}
+
+// adminRequest sends a request with body, when not nil, to the admin API endpoint. Error statuses are
+// returned as GoCloak API errors prefixed with errMessage, so that IsNotFound and IsConflict apply.
+func (c *Connection) adminRequest(ctx context.Context, token, method, endpoint string, body any, errMessage string) (*resty.Response, error) {
+	request := c.Client.GetRequestWithBearerAuth(ctx, token)
+	if body != nil {
+		request = request.SetBody(body)
+	}
+	resp, err := request.Execute(method, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errMessage, err)
+	}
+	if resp.IsError() {
+		return nil, &gocloak.APIError{
+			Code:    resp.StatusCode(),
+			Message: fmt.Sprintf("%s: %s", errMessage, resp.Status()),
+			Type:    gocloak.APIErrTypeUnknown,
+		}
+	}
+	return resp, nil
+}
+
+// createdID returns the ID of the object created by a request, the last segment of its Location header.
+func createdID(resp *resty.Response) string {
+	location := resp.Header().Get("Location")
+	return location[strings.LastIndex(location, "/")+1:]
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthMethod selects how the operator authenticates against the Keycloak admin API.
+type AuthMethod string
+
+const (
+	// AuthMethodPassword logs in as an admin user through the admin-cli client.
+	AuthMethodPassword AuthMethod = "password"
+	// AuthMethodClientCredentials logs in as a confidential client using its client secret.
+	AuthMethodClientCredentials AuthMethod = "client_credentials"
+	// AuthMethodPrivateKeyJWT logs in as a confidential client using a client assertion
+	// signed with its private key.
+	AuthMethodPrivateKeyJWT AuthMethod = "private_key_jwt"
+)
+
+// clientAssertionLifetime is how long a signed client assertion stays valid.
+const clientAssertionLifetime = time.Minute
+
+// validateAuth checks that cfg carries the credentials required by its authentication method.
+func validateAuth(cfg *Config) error {
+	switch cfg.AuthMethod {
+	case AuthMethodPassword:
+		if cfg.Username == "" || cfg.Password == "" {
+			return fmt.Errorf("keycloak username and password are required for %s authentication", cfg.AuthMethod)
+		}
+	case AuthMethodClientCredentials:
+		if cfg.ClientID == "" || cfg.ClientSecret == "" {
+			return fmt.Errorf("keycloak client ID and client secret are required for %s authentication", cfg.AuthMethod)
+		}
+	case AuthMethodPrivateKeyJWT:
+		if cfg.ClientID == "" || len(cfg.PrivateKey) == 0 {
+			return fmt.Errorf("keycloak client ID and private key are required for %s authentication", cfg.AuthMethod)
+		}
+	default:
+		return fmt.Errorf("unsupported keycloak authentication method %q", cfg.AuthMethod)
+	}
+	return nil
+}
+
+// parseSigningKey parses a PEM encoded RSA or EC private key and returns it with the
+// signing method used for client assertions.
+func parseSigningKey(pemKey []byte) (crypto.Signer, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemKey); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+	if key, err := jwt.ParseECPrivateKeyFromPEM(pemKey); err == nil {
+		switch key.Curve.Params().BitSize {
+		case 384:
+			return key, jwt.SigningMethodES384, nil
+		case 521:
+			return key, jwt.SigningMethodES512, nil
+		default:
+			return key, jwt.SigningMethodES256, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("private key must be a PEM encoded RSA or EC private key")
+}
+
+// adminClientID is the public client used by password authentication.
+const adminClientID = "admin-cli"
+
+// Login authenticates against Keycloak with the configured method and returns a fresh token.
+// Most callers should use Token, which caches the result.
+func (c *Connection) Login(ctx context.Context) (*gocloak.JWT, error) {
+	switch c.config.AuthMethod {
+	case AuthMethodClientCredentials:
+		return c.Client.LoginClient(ctx, c.config.ClientID, c.config.ClientSecret, c.config.Realm)
+	case AuthMethodPrivateKeyJWT:
+		expiresAt := jwt.NewNumericDate(time.Now().Add(clientAssertionLifetime))
+		return c.Client.LoginClientSignedJWT(ctx, c.config.ClientID, c.config.Realm, c.signingKey, c.signingMethod, expiresAt)
+	default:
+		return c.Client.LoginAdmin(ctx, c.config.Username, c.config.Password, c.config.Realm)
+	}
+}
+
+// refreshFunc returns how to refresh tokens for the configured method, or nil when tokens
+// cannot be refreshed and a new login is required instead.
+func (c *Connection) refreshFunc() RefreshFunc {
+	switch c.config.AuthMethod {
+	case AuthMethodPassword:
+		return func(ctx context.Context, refreshToken string) (*gocloak.JWT, error) {
+			return c.Client.RefreshToken(ctx, refreshToken, adminClientID, "", c.config.Realm)
+		}
+	case AuthMethodClientCredentials:
+		return func(ctx context.Context, refreshToken string) (*gocloak.JWT, error) {
+			return c.Client.RefreshToken(ctx, refreshToken, c.config.ClientID, c.config.ClientSecret, c.config.Realm)
+		}
+	default:
+		// Refreshing requires a client assertion, which is no cheaper than logging in again
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newFormCapturingServer starts a server answering token requests and recording the last submitted form.
+// Client credentials sent with basic authentication are recorded as client_id and client_secret.
+func newFormCapturingServer(form *url.Values) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.ParseForm()).To(Succeed())
+		*form = r.PostForm
+		if clientID, clientSecret, ok := r.BasicAuth(); ok {
+			form.Set("client_id", clientID)
+			form.Set("client_secret", clientSecret)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"access_token":"test-token","token_type":"Bearer","expires_in":60}`)
+	}))
+}
+
+func rsaKeyPEM() []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func ecKeyPEM() []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// clearKeycloakEnv unsets the KEYCLOAK_* variables for the duration of the current spec.
+func clearKeycloakEnv() {
+	for _, name := range []string{
+		"KEYCLOAK_URL", "KEYCLOAK_REALM", "KEYCLOAK_AUTH_METHOD", "KEYCLOAK_USER", "KEYCLOAK_PASSWORD",
+		"KEYCLOAK_CLIENT_ID", "KEYCLOAK_CLIENT_SECRET", "KEYCLOAK_PRIVATE_KEY_FILE",
+		"KEYCLOAK_CA_FILE", "KEYCLOAK_TLS_CERT_FILE", "KEYCLOAK_TLS_KEY_FILE", "KEYCLOAK_PROXY_URL", "KEYCLOAK_TIMEOUT",
+	} {
+		GinkgoT().Setenv(name, "")
+	}
+}
+
+var _ = Describe("Authentication", func() {
+	Context("When validating the configuration", func() {
+		It("Should reject an unknown authentication method", func() {
+			_, err := NewConnection(Config{URL: "http://localhost:8080", AuthMethod: "kerberos"})
+			Expect(err).To(MatchError(ContainSubstring("unsupported keycloak authentication method")))
+		})
+
+		It("Should require a client secret for client_credentials", func() {
+			_, err := NewConnection(Config{
+				URL:        "http://localhost:8080",
+				AuthMethod: AuthMethodClientCredentials,
+				ClientID:   "operator",
+			})
+			Expect(err).To(MatchError(ContainSubstring("client ID and client secret are required")))
+		})
+
+		It("Should require a private key for private_key_jwt", func() {
+			_, err := NewConnection(Config{
+				URL:        "http://localhost:8080",
+				AuthMethod: AuthMethodPrivateKeyJWT,
+				ClientID:   "operator",
+			})
+			Expect(err).To(MatchError(ContainSubstring("client ID and private key are required")))
+		})
+
+		It("Should reject a malformed private key", func() {
+			_, err := NewConnection(Config{
+				URL:        "http://localhost:8080",
+				AuthMethod: AuthMethodPrivateKeyJWT,
+				ClientID:   "operator",
+				PrivateKey: []byte("not a key"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("PEM encoded RSA or EC private key")))
+		})
+
+		It("Should pick the signing method matching the key", func() {
+			_, method, err := parseSigningKey(rsaKeyPEM())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(method).To(Equal(jwt.SigningMethodRS256))
+
+			_, method, err = parseSigningKey(ecKeyPEM())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(method).To(Equal(jwt.SigningMethodES384))
+		})
+	})
+
+	Context("When logging in", func() {
+		var (
+			form   url.Values
+			server *httptest.Server
+		)
+
+		BeforeEach(func() {
+			server = newFormCapturingServer(&form)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should log in as an admin user with the password method", func() {
+			conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "secret"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("password"))
+			Expect(form.Get("client_id")).To(Equal("admin-cli"))
+			Expect(form.Get("username")).To(Equal("admin"))
+		})
+
+		It("Should log in as a confidential client with the client_credentials method", func() {
+			conn, err := NewConnection(Config{
+				URL:          server.URL,
+				AuthMethod:   AuthMethodClientCredentials,
+				ClientID:     "operator",
+				ClientSecret: "secret",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("client_credentials"))
+			Expect(form.Get("client_id")).To(Equal("operator"))
+			Expect(form.Get("client_secret")).To(Equal("secret"))
+		})
+
+		It("Should send a signed client assertion with the private_key_jwt method", func() {
+			conn, err := NewConnection(Config{
+				URL:        server.URL,
+				AuthMethod: AuthMethodPrivateKeyJWT,
+				ClientID:   "operator",
+				PrivateKey: rsaKeyPEM(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.Get("grant_type")).To(Equal("client_credentials"))
+			Expect(form.Get("client_assertion_type")).To(Equal("urn:ietf:params:oauth:client-assertion-type:jwt-bearer"))
+			Expect(form.Get("client_assertion")).NotTo(BeEmpty())
+			Expect(form.Get("client_secret")).To(BeEmpty())
+		})
+	})
+
+	Context("When reading the configuration from the environment", func() {
+		BeforeEach(func() {
+			clearKeycloakEnv()
+		})
+
+		It("Should report an unconfigured default connection", func() {
+			_, configured, err := ConfigFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configured).To(BeFalse())
+		})
+
+		It("Should list the variables missing for the selected method", func() {
+			GinkgoT().Setenv("KEYCLOAK_URL", "http://localhost:8080")
+			GinkgoT().Setenv("KEYCLOAK_AUTH_METHOD", "client_credentials")
+			GinkgoT().Setenv("KEYCLOAK_CLIENT_ID", "operator")
+
+			_, configured, err := ConfigFromEnv()
+			Expect(configured).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("KEYCLOAK_CLIENT_SECRET required")))
+		})
+
+		It("Should load the private key from a file", func() {
+			keyFile := filepath.Join(GinkgoT().TempDir(), "tls.key")
+			Expect(os.WriteFile(keyFile, rsaKeyPEM(), 0o600)).To(Succeed())
+			GinkgoT().Setenv("KEYCLOAK_URL", "http://localhost:8080")
+			GinkgoT().Setenv("KEYCLOAK_AUTH_METHOD", "private_key_jwt")
+			GinkgoT().Setenv("KEYCLOAK_CLIENT_ID", "operator")
+			GinkgoT().Setenv("KEYCLOAK_PRIVATE_KEY_FILE", keyFile)
+
+			cfg, configured, err := ConfigFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configured).To(BeTrue())
+			Expect(cfg.PrivateKey).NotTo(BeEmpty())
+
+			_, err = NewConnection(cfg)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
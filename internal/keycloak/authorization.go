@@ -0,0 +1,32 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"net/http"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+)
+
+// UpdateResourceServer updates the settings of the resource server of the client idOfClient, such as
+// its policy enforcement mode and decision strategy. GoCloak does not cover this endpoint.
+func (c *Connection) UpdateResourceServer(ctx context.Context, token, realm, idOfClient string, server gocloak.ResourceServerRepresentation) error {
+	endpoint := c.adminURL(realm, "clients", idOfClient, "authz", "resource-server")
+	_, err := c.adminRequest(ctx, token, http.MethodPut, endpoint, server, "could not update resource server")
+	return err
+}
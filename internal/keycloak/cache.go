@@ -0,0 +1,64 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"sync"
+)
+
+// ConnectionCache keeps one Connection per connection resource so that GoCloak clients
+// are reused across reconciles instead of being rebuilt every time.
+type ConnectionCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	version string
+	conn    *Connection
+}
+
+// NewConnectionCache returns an empty ConnectionCache.
+func NewConnectionCache() *ConnectionCache {
+	return &ConnectionCache{entries: make(map[string]cacheEntry)}
+}
+
+// Get returns the connection cached under key when it was built for the same version.
+// Otherwise build is called and its result replaces the cached entry.
+func (c *ConnectionCache) Get(key, version string, build func() (*Connection, error)) (*Connection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok && entry.version == version {
+		return entry.conn, nil
+	}
+
+	conn, err := build()
+	if err != nil {
+		return nil, err
+	}
+	c.entries[key] = cacheEntry{version: version, conn: conn}
+	return conn, nil
+}
+
+// Delete drops the connection cached under key, if any.
+func (c *ConnectionCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+)
+
+// The kinds of client scopes a realm assigns to the clients created in it.
+const (
+	RealmDefaultClientScopes  = "default-default-client-scopes"
+	RealmOptionalClientScopes = "default-optional-client-scopes"
+)
+
+// ClientScope is a Keycloak client scope. GoCloak only models a fixed set of client scope attributes
+// and protocol mapper settings, while any attribute and mapper configuration can be set here.
+type ClientScope struct {
+	ID              *string                                 `json:"id,omitempty"`
+	Name            *string                                 `json:"name,omitempty"`
+	Description     *string                                 `json:"description,omitempty"`
+	Protocol        *string                                 `json:"protocol,omitempty"`
+	Attributes      *map[string]string                      `json:"attributes,omitempty"`
+	ProtocolMappers *[]gocloak.ProtocolMapperRepresentation `json:"protocolMappers,omitempty"`
+}
+
+// GetClientScopes returns the client scopes of realm with their protocol mappers.
+func (c *Connection) GetClientScopes(ctx context.Context, token, realm string) ([]ClientScope, error) {
+	resp, err := c.adminRequest(ctx, token, http.MethodGet, c.adminURL(realm, "client-scopes"), nil, "could not get client scopes")
+	if err != nil {
+		return nil, err
+	}
+	var scopes []ClientScope
+	if err := json.Unmarshal(resp.Body(), &scopes); err != nil {
+		return nil, fmt.Errorf("could not decode client scopes: %w", err)
+	}
+	return scopes, nil
+}
+
+// CreateClientScope creates a client scope in realm, without its protocol mappers, and returns its ID.
+func (c *Connection) CreateClientScope(ctx context.Context, token, realm string, scope ClientScope) (string, error) {
+	scope.ProtocolMappers = nil
+	resp, err := c.adminRequest(ctx, token, http.MethodPost, c.adminURL(realm, "client-scopes"), scope, "could not create client scope")
+	if err != nil {
+		return "", err
+	}
+	return createdID(resp), nil
+}
+
+// UpdateClientScope updates the client scope with the ID of scope. Keycloak leaves its protocol mappers alone.
+func (c *Connection) UpdateClientScope(ctx context.Context, token, realm string, scope ClientScope) error {
+	scope.ProtocolMappers = nil
+	endpoint := c.adminURL(realm, "client-scopes", gocloak.PString(scope.ID))
+	_, err := c.adminRequest(ctx, token, http.MethodPut, endpoint, scope, "could not update client scope")
+	return err
+}
+
+// CreateClientScopeProtocolMapper creates a protocol mapper in the client scope scopeID.
+func (c *Connection) CreateClientScopeProtocolMapper(ctx context.Context, token, realm, scopeID string, mapper gocloak.ProtocolMapperRepresentation) error {
+	endpoint := c.adminURL(realm, "client-scopes", scopeID, "protocol-mappers", "models")
+	_, err := c.adminRequest(ctx, token, http.MethodPost, endpoint, mapper, "could not create client scope protocol mapper")
+	return err
+}
+
+// UpdateClientScopeProtocolMapper updates the protocol mapper with the ID of mapper in the client scope scopeID.
+func (c *Connection) UpdateClientScopeProtocolMapper(ctx context.Context, token, realm, scopeID string, mapper gocloak.ProtocolMapperRepresentation) error {
+	endpoint := c.adminURL(realm, "client-scopes", scopeID, "protocol-mappers", "models", gocloak.PString(mapper.ID))
+	_, err := c.adminRequest(ctx, token, http.MethodPut, endpoint, mapper, "could not update client scope protocol mapper")
+	return err
+}
+
+// AddRealmClientScope makes the client scope scopeID one of the scopes of kind, RealmDefaultClientScopes or
+// RealmOptionalClientScopes, that realm assigns to new clients. GoCloak does not cover this endpoint.
+func (c *Connection) AddRealmClientScope(ctx context.Context, token, realm, kind, scopeID string) error {
+	_, err := c.adminRequest(ctx, token, http.MethodPut, c.adminURL(realm, kind, scopeID), nil, "could not add realm client scope")
+	return err
+}
+
+// RemoveRealmClientScope removes the client scope scopeID from the scopes of kind that realm assigns to new clients.
+func (c *Connection) RemoveRealmClientScope(ctx context.Context, token, realm, kind, scopeID string) error {
+	_, err := c.adminRequest(ctx, token, http.MethodDelete, c.adminURL(realm, kind, scopeID), nil, "could not remove realm client scope")
+	return err
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package keycloak wraps the GoCloak admin client with the connection handling shared by the controllers.
+package keycloak
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultRealm is the realm the operator authenticates against when none is configured.
+const DefaultRealm = "master"
+
+// Config holds everything needed to reach and authenticate against a Keycloak server.
+type Config struct {
+	// Name identifies the connection in logs and metrics
+	Name string
+	// URL of the Keycloak server
+	URL string
+	// Realm used for authentication (default: "master")
+	Realm string
+	// AuthMethod selects how to authenticate (default: AuthMethodPassword)
+	AuthMethod AuthMethod
+	// Username used by password authentication
+	Username string
+	// Password used by password authentication
+	Password string
+	// ClientID used by client_credentials and private_key_jwt authentication
+	ClientID string
+	// ClientSecret used by client_credentials authentication
+	ClientSecret string
+	// PrivateKey is the PEM encoded key signing client assertions for private_key_jwt authentication
+	PrivateKey []byte
+	// CABundle is an optional PEM encoded CA bundle trusted in addition to the system roots
+	CABundle []byte
+	// ClientCertificate is an optional PEM encoded certificate presented to Keycloak for mutual TLS
+	ClientCertificate []byte
+	// ClientKey is the PEM encoded private key of ClientCertificate
+	ClientKey []byte
+	// ProxyURL is an optional HTTP(S) proxy used to reach Keycloak instead of the environment proxy settings
+	ProxyURL string
+	// Timeout bounds each request sent to Keycloak (default: no timeout)
+	Timeout time.Duration
+}
+
+// Connection is a GoCloak client bound to the credentials used to obtain admin tokens.
+type Connection struct {
+	// Client is the underlying GoCloak admin client
+	Client        *gocloak.GoCloak
+	config        Config
+	tokens        *TokenManager
+	signingKey    crypto.Signer
+	signingMethod jwt.SigningMethod
+}
+
+// NewConnection validates the configuration and builds a Connection from it.
+func NewConnection(cfg Config) (*Connection, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("keycloak URL is required")
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.URL
+	}
+	if cfg.Realm == "" {
+		cfg.Realm = DefaultRealm
+	}
+	if cfg.AuthMethod == "" {
+		cfg.AuthMethod = AuthMethodPassword
+	}
+	if err := validateAuth(&cfg); err != nil {
+		return nil, err
+	}
+
+	conn := &Connection{config: cfg}
+	if cfg.AuthMethod == AuthMethodPrivateKeyJWT {
+		key, method, err := parseSigningKey(cfg.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		conn.signingKey = key
+		conn.signingMethod = method
+	}
+
+	gc := gocloak.NewClient(cfg.URL)
+	if err := configureTransport(gc.RestyClient(), &cfg); err != nil {
+		return nil, err
+	}
+
+	conn.Client = gc
+	conn.tokens = NewTokenManager(cfg.Name, conn.Login, conn.refreshFunc())
+
+	// Drop the cached token as soon as Keycloak rejects it, e.g. because the session was
+	// revoked, so that the next reconcile logs in again instead of retrying with it.
+	gc.RestyClient().OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		if resp.StatusCode() != http.StatusUnauthorized {
+			return nil
+		}
+		// Token requests authenticate without a bearer token and are left alone
+		if resp.Request.Token != "" {
+			conn.tokens.Invalidate(resp.Request.Token)
+		}
+		return nil
+	})
+
+	return conn, nil
+}
+
+// URL returns the base URL of the Keycloak server.
+func (c *Connection) URL() string {
+	return c.config.URL
+}
+
+// Realm returns the realm the connection authenticates against.
+func (c *Connection) Realm() string {
+	return c.config.Realm
+}
+
+// Name returns the name identifying the connection.
+func (c *Connection) Name() string {
+	return c.config.Name
+}
+
+// Token returns a cached admin access token, logging in or refreshing it when needed.
+func (c *Connection) Token(ctx context.Context) (string, error) {
+	return c.tokens.Token(ctx)
+}
+
+// IssuerURL returns the issuer of the tokens of a realm, as derived from the connection URL.
+func (c *Connection) IssuerURL(realm string) string {
+	return strings.TrimSuffix(c.config.URL, "/") + "/realms/" + url.PathEscape(realm)
+}
+
+// WellKnownURL returns the OpenID Connect discovery endpoint of a realm.
+func (c *Connection) WellKnownURL(realm string) string {
+	return c.IssuerURL(realm) + "/.well-known/openid-configuration"
+}
+
+// Endpoints are the OpenID Connect endpoints of a realm, as listed by its discovery document.
+type Endpoints struct {
+	Issuer                string
+	WellKnownURL          string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	IntrospectionEndpoint string
+	UserinfoEndpoint      string
+	EndSessionEndpoint    string
+	JWKSURI               string
+}
+
+// Endpoints returns the OpenID Connect endpoints of a realm, derived from the connection URL
+// the same way Keycloak builds its discovery document.
+func (c *Connection) Endpoints(realm string) Endpoints {
+	issuer := c.IssuerURL(realm)
+	protocol := issuer + "/protocol/openid-connect"
+	return Endpoints{
+		Issuer:                issuer,
+		WellKnownURL:          c.WellKnownURL(realm),
+		AuthorizationEndpoint: protocol + "/auth",
+		TokenEndpoint:         protocol + "/token",
+		IntrospectionEndpoint: protocol + "/token/introspect",
+		UserinfoEndpoint:      protocol + "/userinfo",
+		EndSessionEndpoint:    protocol + "/logout",
+		JWKSURI:               protocol + "/certs",
+	}
+}
+
+// AuthMethod returns the method used to authenticate.
+func (c *Connection) AuthMethod() AuthMethod {
+	return c.config.AuthMethod
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// tokenHandler answers every request with a static token.
+var tokenHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprint(w, `{"access_token":"test-token","token_type":"Bearer","expires_in":60}`)
+})
+
+// newTokenServer starts a TLS server answering every token request with a static token.
+func newTokenServer() *httptest.Server {
+	return httptest.NewTLSServer(tokenHandler)
+}
+
+var _ = Describe("Connection", func() {
+	Context("When building a connection", func() {
+		It("Should require a URL", func() {
+			_, err := NewConnection(Config{Username: "admin", Password: "admin"})
+			Expect(err).To(MatchError(ContainSubstring("URL is required")))
+		})
+
+		It("Should require credentials", func() {
+			_, err := NewConnection(Config{URL: "http://localhost:8080"})
+			Expect(err).To(MatchError(ContainSubstring("username and password are required")))
+		})
+
+		It("Should default the realm to master", func() {
+			conn, err := NewConnection(Config{URL: "http://localhost:8080", Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.Realm()).To(Equal(DefaultRealm))
+			Expect(conn.URL()).To(Equal("http://localhost:8080"))
+		})
+
+		It("Should derive the issuer and discovery endpoint of a realm", func() {
+			conn, err := NewConnection(Config{URL: "https://sso.example.com/", Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.IssuerURL("my realm")).To(Equal("https://sso.example.com/realms/my%20realm"))
+			Expect(conn.WellKnownURL("demo")).To(Equal("https://sso.example.com/realms/demo/.well-known/openid-configuration"))
+			Expect(conn.Endpoints("demo").TokenEndpoint).To(Equal("https://sso.example.com/realms/demo/protocol/openid-connect/token"))
+			Expect(conn.Endpoints("demo").JWKSURI).To(Equal("https://sso.example.com/realms/demo/protocol/openid-connect/certs"))
+		})
+
+		It("Should reject an invalid CA bundle", func() {
+			_, err := NewConnection(Config{
+				URL:      "https://localhost:8443",
+				Username: "admin",
+				Password: "admin",
+				CABundle: []byte("not a certificate"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("no valid PEM certificate")))
+		})
+
+		It("Should trust the configured CA bundle", func() {
+			server := newTokenServer()
+			defer server.Close()
+
+			caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			conn, err := NewConnection(Config{
+				URL:      server.URL,
+				Username: "admin",
+				Password: "admin",
+				CABundle: caBundle,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := conn.Login(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.AccessToken).To(Equal("test-token"))
+		})
+
+		It("Should fail the TLS handshake without the CA bundle", func() {
+			server := newTokenServer()
+			defer server.Close()
+
+			conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When caching connections", func() {
+		var (
+			cache  *ConnectionCache
+			builds int
+			build  func() (*Connection, error)
+		)
+
+		BeforeEach(func() {
+			cache = NewConnectionCache()
+			builds = 0
+			build = func() (*Connection, error) {
+				builds++
+				return NewConnection(Config{URL: "http://localhost:8080", Username: "admin", Password: "admin"})
+			}
+		})
+
+		It("Should reuse the connection for the same version", func() {
+			first, err := cache.Get("default/staging", "1", build)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := cache.Get("default/staging", "1", build)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(builds).To(Equal(1))
+		})
+
+		It("Should rebuild the connection when the version changes", func() {
+			first, err := cache.Get("default/staging", "1", build)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := cache.Get("default/staging", "2", build)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second).NotTo(BeIdenticalTo(first))
+			Expect(builds).To(Equal(2))
+		})
+
+		It("Should not cache build failures", func() {
+			_, err := cache.Get("default/broken", "1", func() (*Connection, error) {
+				return nil, fmt.Errorf("boom")
+			})
+			Expect(err).To(HaveOccurred())
+
+			_, err = cache.Get("default/broken", "1", build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(builds).To(Equal(1))
+		})
+
+		It("Should rebuild the connection after it was deleted", func() {
+			_, err := cache.Get("default/staging", "1", build)
+			Expect(err).NotTo(HaveOccurred())
+			cache.Delete("default/staging")
+			_, err = cache.Get("default/staging", "1", build)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(builds).To(Equal(2))
+		})
+	})
+})
+
+var _ = Describe("Errors", func() {
+	It("Should recognize Keycloak admin API status codes", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/admin/realms/master/clients/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusConflict)
+		}))
+		defer server.Close()
+
+		conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = conn.Client.GetClient(context.Background(), "token", "master", "missing")
+		Expect(IsNotFound(err)).To(BeTrue())
+		Expect(IsConflict(err)).To(BeFalse())
+
+		_, err = conn.Client.CreateClient(context.Background(), "token", "master", gocloak.Client{})
+		Expect(IsConflict(err)).To(BeTrue())
+		Expect(IsNotFound(fmt.Errorf("connection refused"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("Installation", func() {
+	It("Should return the document of the installation provider", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/admin/realms/demo/clients/app-id/installation/providers/keycloak-oidc-keycloak-json":
+				Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+				_, _ = fmt.Fprint(w, `{"realm":"demo","resource":"app"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		document, err := conn.GetClientInstallation(context.Background(), "token", "demo", "app-id", "keycloak-oidc-keycloak-json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(document)).To(Equal(`{"realm":"demo","resource":"app"}`))
+
+		_, err = conn.GetClientInstallation(context.Background(), "token", "demo", "app-id", "unknown")
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Authorization", func() {
+	It("Should update the resource server settings", func() {
+		var body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut || r.URL.Path != "/admin/realms/demo/clients/app-id/authz/resource-server" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+			data, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			body = string(data)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		settings := gocloak.ResourceServerRepresentation{PolicyEnforcementMode: gocloak.PERMISSIVE}
+		Expect(conn.UpdateResourceServer(context.Background(), "token", "demo", "app-id", settings)).To(Succeed())
+		Expect(body).To(MatchJSON(`{"policyEnforcementMode":"PERMISSIVE"}`))
+
+		err = conn.UpdateResourceServer(context.Background(), "token", "demo", "other-id", settings)
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Realm", func() {
+	It("Should rename the realm it updates", func() {
+		var body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut || r.URL.Path != "/admin/realms/demo" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+			data, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			body = string(data)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		realm := gocloak.RealmRepresentation{Realm: gocloak.StringP("production")}
+		Expect(conn.UpdateRealm(context.Background(), "token", "demo", realm)).To(Succeed())
+		Expect(body).To(MatchJSON(`{"realm":"production"}`))
+
+		err = conn.UpdateRealm(context.Background(), "token", "other", realm)
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ClientScope", func() {
+	var (
+		server   *httptest.Server
+		conn     *Connection
+		requests []string
+		bodies   []string
+	)
+
+	BeforeEach(func() {
+		requests, bodies = nil, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+			data, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			bodies = append(bodies, string(data))
+			switch r.Method + " " + r.URL.Path {
+			case "GET /admin/realms/demo/client-scopes":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(w, `[{"id":"tenant-id","name":"tenant","attributes":{"custom":"value"},
+					"protocolMappers":[{"id":"mapper-id","name":"tenant","config":{"claim.name":"tenant"}}]}]`)
+			case "POST /admin/realms/demo/client-scopes":
+				w.Header().Set("Location", server.URL+"/admin/realms/demo/client-scopes/new-id")
+				w.WriteHeader(http.StatusCreated)
+			case "PUT /admin/realms/demo/default-optional-client-scopes/tenant-id":
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		var err error
+		conn, err = NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should list client scopes with any attribute and mapper configuration", func() {
+		scopes, err := conn.GetClientScopes(context.Background(), "token", "demo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scopes).To(HaveLen(1))
+		Expect(*scopes[0].Attributes).To(HaveKeyWithValue("custom", "value"))
+		Expect(*(*scopes[0].ProtocolMappers)[0].Config).To(HaveKeyWithValue("claim.name", "tenant"))
+	})
+
+	It("Should create client scopes without their protocol mappers", func() {
+		mappers := []gocloak.ProtocolMapperRepresentation{{Name: gocloak.StringP("tenant")}}
+		id, err := conn.CreateClientScope(context.Background(), "token", "demo",
+			ClientScope{Name: gocloak.StringP("audience"), ProtocolMappers: &mappers})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal("new-id"))
+		Expect(bodies[0]).To(MatchJSON(`{"name":"audience"}`))
+	})
+
+	It("Should assign client scopes to the realm", func() {
+		Expect(conn.AddRealmClientScope(context.Background(), "token", "demo", RealmOptionalClientScopes, "tenant-id")).To(Succeed())
+		Expect(requests).To(Equal([]string{"PUT /admin/realms/demo/default-optional-client-scopes/tenant-id"}))
+
+		err := conn.RemoveRealmClientScope(context.Background(), "token", "demo", RealmDefaultClientScopes, "tenant-id")
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConfigFromEnv reads the operator-wide connection from the KEYCLOAK_* environment variables.
+// It returns false when KEYCLOAK_URL is not set, meaning no default connection is configured.
+func ConfigFromEnv() (Config, bool, error) {
+	cfg := Config{
+		URL:          os.Getenv("KEYCLOAK_URL"),
+		Realm:        os.Getenv("KEYCLOAK_REALM"),
+		AuthMethod:   AuthMethod(os.Getenv("KEYCLOAK_AUTH_METHOD")),
+		Username:     os.Getenv("KEYCLOAK_USER"),
+		Password:     os.Getenv("KEYCLOAK_PASSWORD"),
+		ClientID:     os.Getenv("KEYCLOAK_CLIENT_ID"),
+		ClientSecret: os.Getenv("KEYCLOAK_CLIENT_SECRET"),
+		ProxyURL:     os.Getenv("KEYCLOAK_PROXY_URL"),
+	}
+	if cfg.URL == "" {
+		if cfg.Username != "" || cfg.Password != "" || cfg.ClientID != "" || cfg.ClientSecret != "" {
+			return cfg, false, fmt.Errorf("KEYCLOAK_URL is required when Keycloak credentials are set")
+		}
+		return cfg, false, nil
+	}
+	if cfg.AuthMethod == "" {
+		cfg.AuthMethod = AuthMethodPassword
+	}
+
+	var missing []string
+	switch cfg.AuthMethod {
+	case AuthMethodPassword:
+		missing = missingEnv("KEYCLOAK_USER", "KEYCLOAK_PASSWORD")
+	case AuthMethodClientCredentials:
+		missing = missingEnv("KEYCLOAK_CLIENT_ID", "KEYCLOAK_CLIENT_SECRET")
+	case AuthMethodPrivateKeyJWT:
+		missing = missingEnv("KEYCLOAK_CLIENT_ID", "KEYCLOAK_PRIVATE_KEY_FILE")
+	default:
+		return cfg, true, fmt.Errorf("unsupported KEYCLOAK_AUTH_METHOD %q", cfg.AuthMethod)
+	}
+	if len(missing) > 0 {
+		return cfg, true, fmt.Errorf("%s required for %s authentication", strings.Join(missing, ", "), cfg.AuthMethod)
+	}
+
+	if keyFile := os.Getenv("KEYCLOAK_PRIVATE_KEY_FILE"); keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			return cfg, true, fmt.Errorf("failed to read KEYCLOAK_PRIVATE_KEY_FILE: %w", err)
+		}
+		cfg.PrivateKey = key
+	}
+
+	if err := transportFromEnv(&cfg); err != nil {
+		return cfg, true, err
+	}
+
+	return cfg, true, nil
+}
+
+// transportFromEnv reads the optional CA bundle, client certificate and timeout settings.
+func transportFromEnv(cfg *Config) error {
+	files := []struct {
+		env    string
+		target *[]byte
+	}{
+		{"KEYCLOAK_CA_FILE", &cfg.CABundle},
+		{"KEYCLOAK_TLS_CERT_FILE", &cfg.ClientCertificate},
+		{"KEYCLOAK_TLS_KEY_FILE", &cfg.ClientKey},
+	}
+	for _, file := range files {
+		path := os.Getenv(file.env)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.env, err)
+		}
+		*file.target = data
+	}
+
+	if timeout := os.Getenv("KEYCLOAK_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid KEYCLOAK_TIMEOUT %q: %w", timeout, err)
+		}
+		cfg.Timeout = d
+	}
+	return nil
+}
+
+// missingEnv returns the names of the given environment variables that are empty.
+func missingEnv(names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"errors"
+	"net/http"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+)
+
+// IsNotFound reports whether err is a Keycloak admin API 404 Not Found error.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a Keycloak admin API 409 Conflict error,
+// returned when an object with the same unique name already exists.
+func IsConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict
+}
+
+// statusCode returns the HTTP status code of a GoCloak API error, or 0 for other errors.
+func statusCode(err error) int {
+	var apiErr *gocloak.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetClientInstallation returns the document generated for the client idOfClient by the installation
+// provider providerID, e.g. the keycloak.json adapter configuration or a SAML descriptor.
+// GoCloak does not cover this endpoint.
+func (c *Connection) GetClientInstallation(ctx context.Context, token, realm, idOfClient, providerID string) ([]byte, error) {
+	endpoint := c.adminURL(realm, "clients", idOfClient, "installation", "providers", providerID)
+	resp, err := c.adminRequest(ctx, token, http.MethodGet, endpoint, nil, "could not get client installation")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body(), nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// tokenRequestsTotal counts the admin token requests sent to Keycloak.
+	tokenRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "keycloak_operator_token_requests_total",
+			Help: "Number of admin token requests sent to Keycloak, by connection, grant (login or refresh) and result.",
+		},
+		[]string{"connection", "grant", "result"},
+	)
+
+	// tokenInvalidationsTotal counts the cached tokens dropped after Keycloak rejected them.
+	tokenInvalidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "keycloak_operator_token_invalidations_total",
+			Help: "Number of cached admin tokens dropped after Keycloak answered 401 Unauthorized, by connection.",
+		},
+		[]string{"connection"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(tokenRequestsTotal, tokenInvalidationsTotal)
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"net/http"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+)
+
+// UpdateRealm updates the realm named realm, renaming it when the representation names another realm.
+// GoCloak addresses the realm by the name in the representation, which cannot rename it.
+func (c *Connection) UpdateRealm(ctx context.Context, token, realm string, representation gocloak.RealmRepresentation) error {
+	_, err := c.adminRequest(ctx, token, http.MethodPut, c.adminURL(realm), representation, "could not update realm")
+	return err
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+)
+
+// tokenRefreshMargin is how long before expiry a cached token is renewed.
+const tokenRefreshMargin = 30 * time.Second
+
+// LoginFunc obtains a new token with the connection credentials.
+type LoginFunc func(ctx context.Context) (*gocloak.JWT, error)
+
+// RefreshFunc exchanges a refresh token for a new token.
+type RefreshFunc func(ctx context.Context, refreshToken string) (*gocloak.JWT, error)
+
+// TokenManager caches an admin access token and renews it ahead of expiry, using the refresh
+// token when possible and logging in again otherwise. It is safe for concurrent use.
+type TokenManager struct {
+	name    string
+	login   LoginFunc
+	refresh RefreshFunc
+	now     func() time.Time
+
+	mu               sync.Mutex
+	token            *gocloak.JWT
+	expiresAt        time.Time
+	refreshExpiresAt time.Time
+}
+
+// NewTokenManager returns a TokenManager for the connection called name.
+// refresh may be nil when the authentication method does not support refresh tokens.
+func NewTokenManager(name string, login LoginFunc, refresh RefreshFunc) *TokenManager {
+	return &TokenManager{
+		name:    name,
+		login:   login,
+		refresh: refresh,
+		now:     time.Now,
+	}
+}
+
+// Token returns a valid access token, refreshing or logging in again when the cached one is about to expire.
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if m.token != nil && now.Before(m.expiresAt.Add(-tokenRefreshMargin)) {
+		return m.token.AccessToken, nil
+	}
+
+	if m.token != nil && m.refresh != nil && m.token.RefreshToken != "" &&
+		now.Before(m.refreshExpiresAt.Add(-tokenRefreshMargin)) {
+		token, err := m.refresh(ctx, m.token.RefreshToken)
+		tokenRequestsTotal.WithLabelValues(m.name, "refresh", resultLabel(err)).Inc()
+		if err == nil {
+			m.store(token, now)
+			return token.AccessToken, nil
+		}
+		// The refresh token may have been revoked, fall back to a full login
+	}
+
+	token, err := m.login(ctx)
+	tokenRequestsTotal.WithLabelValues(m.name, "login", resultLabel(err)).Inc()
+	if err != nil {
+		m.token = nil
+		return "", err
+	}
+	m.store(token, now)
+	return token.AccessToken, nil
+}
+
+// Invalidate drops the cached token if it is still accessToken, so that the next call to Token
+// logs in again. Tokens renewed in the meantime are kept.
+func (m *TokenManager) Invalidate(accessToken string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != nil && m.token.AccessToken == accessToken {
+		m.token = nil
+		tokenInvalidationsTotal.WithLabelValues(m.name).Inc()
+	}
+}
+
+// store caches token, computing its expiry times relative to now.
+func (m *TokenManager) store(token *gocloak.JWT, now time.Time) {
+	m.token = token
+	m.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	m.refreshExpiresAt = now.Add(time.Duration(token.RefreshExpiresIn) * time.Second)
+}
+
+// resultLabel maps an error to the result label of the token metrics.
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocloak "github.com/Nerzal/gocloak/v13"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenManager", func() {
+	var (
+		logins    int
+		refreshes int
+		now       time.Time
+		manager   *TokenManager
+	)
+
+	newToken := func(access string) *gocloak.JWT {
+		return &gocloak.JWT{AccessToken: access, RefreshToken: "refresh-" + access, ExpiresIn: 60, RefreshExpiresIn: 1800}
+	}
+
+	BeforeEach(func() {
+		logins, refreshes = 0, 0
+		now = time.Now()
+		manager = NewTokenManager("test",
+			func(context.Context) (*gocloak.JWT, error) {
+				logins++
+				return newToken(fmt.Sprintf("login-%d", logins)), nil
+			},
+			func(_ context.Context, refreshToken string) (*gocloak.JWT, error) {
+				refreshes++
+				return newToken(fmt.Sprintf("refreshed-%d", refreshes)), nil
+			})
+		manager.now = func() time.Time { return now }
+	})
+
+	It("Should reuse the cached token until it is about to expire", func() {
+		for range 3 {
+			token, err := manager.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("login-1"))
+		}
+		Expect(logins).To(Equal(1))
+		Expect(refreshes).To(BeZero())
+	})
+
+	It("Should refresh the token ahead of expiry", func() {
+		_, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(45 * time.Second)
+		token, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("refreshed-1"))
+		Expect(logins).To(Equal(1))
+	})
+
+	It("Should log in again when the refresh fails", func() {
+		manager.refresh = func(context.Context, string) (*gocloak.JWT, error) {
+			refreshes++
+			return nil, errors.New("invalid_grant")
+		}
+		_, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(45 * time.Second)
+		token, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("login-2"))
+		Expect(refreshes).To(Equal(1))
+	})
+
+	It("Should log in again once the refresh token expired", func() {
+		_, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(time.Hour)
+		token, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("login-2"))
+		Expect(refreshes).To(BeZero())
+	})
+
+	It("Should only drop the token it was asked to invalidate", func() {
+		_, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		manager.Invalidate("stale-token")
+		token, err := manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("login-1"))
+
+		manager.Invalidate("login-1")
+		token, err = manager.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("login-2"))
+	})
+
+	It("Should log in once for concurrent callers", func() {
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				_, err := manager.Token(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+		Expect(logins).To(Equal(1))
+	})
+
+	It("Should drop the cached token when Keycloak answers 401 Unauthorized", func() {
+		var tokenRequests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/realms/master/protocol/openid-connect/token" {
+				n := tokenRequests.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":60}`, n)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := conn.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("token-1"))
+
+		_, err = conn.Client.GetRealm(context.Background(), token, "master")
+		Expect(err).To(HaveOccurred())
+
+		token, err = conn.Token(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("token-2"))
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// configureTransport applies the TLS, proxy and timeout settings of cfg to the resty client
+// underneath GoCloak.
+func configureTransport(rc *resty.Client, cfg *Config) error {
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		rc.SetTLSClientConfig(tlsConfig)
+	}
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return fmt.Errorf("invalid keycloak proxy URL %q", cfg.ProxyURL)
+		}
+		rc.SetProxy(proxyURL.String())
+	}
+
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("keycloak request timeout must not be negative")
+	}
+	if cfg.Timeout > 0 {
+		rc.SetTimeout(cfg.Timeout)
+	}
+	return nil
+}
+
+// buildTLSConfig returns the TLS configuration trusting the CA bundle and presenting the client
+// certificate of cfg, or nil when the defaults apply.
+func buildTLSConfig(cfg *Config) (*tls.Config, error) {
+	if len(cfg.CABundle) == 0 && len(cfg.ClientCertificate) == 0 && len(cfg.ClientKey) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(cfg.CABundle) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(cfg.CABundle) {
+			return nil, fmt.Errorf("no valid PEM certificate found in CA bundle")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if len(cfg.ClientCertificate) > 0 || len(cfg.ClientKey) > 0 {
+		if len(cfg.ClientCertificate) == 0 || len(cfg.ClientKey) == 0 {
+			return nil, fmt.Errorf("both a client certificate and a client key are required for mutual TLS")
+		}
+		cert, err := tls.X509KeyPair(cfg.ClientCertificate, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keycloak
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newClientCertificate returns a self-signed client certificate and its private key, PEM encoded.
+func newClientCertificate() (*x509.Certificate, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "keycloak-operator"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return cert,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("Transport", func() {
+	Context("When using mutual TLS", func() {
+		var (
+			server   *httptest.Server
+			caBundle []byte
+			certPEM  []byte
+			keyPEM   []byte
+		)
+
+		BeforeEach(func() {
+			var cert *x509.Certificate
+			cert, certPEM, keyPEM = newClientCertificate()
+			clientCAs := x509.NewCertPool()
+			clientCAs.AddCert(cert)
+
+			server = httptest.NewUnstartedServer(tokenHandler)
+			server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+			server.StartTLS()
+			caBundle = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should present the client certificate", func() {
+			conn, err := NewConnection(Config{
+				URL:               server.URL,
+				Username:          "admin",
+				Password:          "admin",
+				CABundle:          caBundle,
+				ClientCertificate: certPEM,
+				ClientKey:         keyPEM,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should be rejected without a client certificate", func() {
+			conn, err := NewConnection(Config{URL: server.URL, Username: "admin", Password: "admin", CABundle: caBundle})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should require the client key with the certificate", func() {
+			_, err := NewConnection(Config{
+				URL:               server.URL,
+				Username:          "admin",
+				Password:          "admin",
+				ClientCertificate: certPEM,
+			})
+			Expect(err).To(MatchError(ContainSubstring("both a client certificate and a client key are required")))
+		})
+	})
+
+	Context("When using a proxy", func() {
+		It("Should send requests through the proxy", func() {
+			var proxiedHost string
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				proxiedHost = r.URL.Host
+				tokenHandler(w, r)
+			}))
+			defer proxy.Close()
+
+			conn, err := NewConnection(Config{
+				URL:      "http://keycloak.internal:8080",
+				Username: "admin",
+				Password: "admin",
+				ProxyURL: proxy.URL,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proxiedHost).To(Equal("keycloak.internal:8080"))
+		})
+
+		It("Should reject an invalid proxy URL", func() {
+			_, err := NewConnection(Config{
+				URL:      "http://localhost:8080",
+				Username: "admin",
+				Password: "admin",
+				ProxyURL: "proxy:3128",
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid keycloak proxy URL")))
+		})
+	})
+
+	Context("When configuring a timeout", func() {
+		It("Should abort slow requests", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			}))
+			defer server.Close()
+
+			conn, err := NewConnection(Config{
+				URL:      server.URL,
+				Username: "admin",
+				Password: "admin",
+				Timeout:  50 * time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Login(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("Client.Timeout")))
+		})
+	})
+
+	Context("When reading the transport settings from the environment", func() {
+		BeforeEach(func() {
+			clearKeycloakEnv()
+			GinkgoT().Setenv("KEYCLOAK_URL", "https://keycloak.example.com")
+			GinkgoT().Setenv("KEYCLOAK_USER", "admin")
+			GinkgoT().Setenv("KEYCLOAK_PASSWORD", "admin")
+		})
+
+		It("Should load the CA bundle, client certificate, proxy and timeout", func() {
+			_, certPEM, keyPEM := newClientCertificate()
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "ca.crt"), certPEM, 0o600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "tls.crt"), certPEM, 0o600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0o600)).To(Succeed())
+			GinkgoT().Setenv("KEYCLOAK_CA_FILE", filepath.Join(dir, "ca.crt"))
+			GinkgoT().Setenv("KEYCLOAK_TLS_CERT_FILE", filepath.Join(dir, "tls.crt"))
+			GinkgoT().Setenv("KEYCLOAK_TLS_KEY_FILE", filepath.Join(dir, "tls.key"))
+			GinkgoT().Setenv("KEYCLOAK_PROXY_URL", "http://proxy.example.com:3128")
+			GinkgoT().Setenv("KEYCLOAK_TIMEOUT", "15s")
+
+			cfg, _, err := ConfigFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.CABundle).To(Equal(certPEM))
+			Expect(cfg.ClientCertificate).To(Equal(certPEM))
+			Expect(cfg.ClientKey).To(Equal(keyPEM))
+			Expect(cfg.ProxyURL).To(Equal("http://proxy.example.com:3128"))
+			Expect(cfg.Timeout).To(Equal(15 * time.Second))
+
+			_, err = NewConnection(cfg)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject an invalid timeout", func() {
+			GinkgoT().Setenv("KEYCLOAK_TIMEOUT", "soon")
+
+			_, _, err := ConfigFromEnv()
+			Expect(err).To(MatchError(ContainSubstring("invalid KEYCLOAK_TIMEOUT")))
+		})
+
+		It("Should report an unreadable CA file", func() {
+			GinkgoT().Setenv("KEYCLOAK_CA_FILE", filepath.Join(GinkgoT().TempDir(), "missing.crt"))
+
+			_, _, err := ConfigFromEnv()
+			Expect(err).To(MatchError(ContainSubstring("failed to read KEYCLOAK_CA_FILE")))
+		})
+	})
+})